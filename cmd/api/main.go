@@ -1,13 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/mercadolibre/fury_go-platform/pkg/fury"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
 	"github.com/osalomon89/test-crud-api/internal/core/services"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/blob"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/imaging"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/jobs"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/metrics"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/photocheck"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/memory"
 	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/mysql"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/search"
 	server "github.com/osalomon89/test-crud-api/internal/infrastructure/server"
 	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/middleware"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/tracing"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	applog "github.com/osalomon89/test-crud-api/pkg/log"
 )
 
 func main() {
@@ -22,32 +39,389 @@ func run() error {
 		return err
 	}
 
-	furyHandler := server.NewHTTPServer(app, newHandlers())
-	furyHandler.SetupRouter()
+	conn, err := mysql.GetConnectionDB()
+	if err != nil {
+		panic("error connecting to DB: " + applog.Redact(err.Error()))
+	}
 
-	return furyHandler.Run()
-}
+	// The configs are read once the connection has loaded the .env file into the environment.
+	if err := applog.Configure(applog.LoadConfig()); err != nil {
+		return err
+	}
 
-func newHandlers() handler.ItemHandler {
-	conn, err := mysql.GetConnectionDB()
+	if err := marketcontext.SetTrustedProxies(middleware.LoadTrustedProxies()); err != nil {
+		return err
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.LoadConfig())
 	if err != nil {
-		panic("error connecting to DB: " + err.Error())
+		return err
 	}
 
+	registry := metrics.NewRegistry()
+	metrics.RegisterDBStats(registry, conn.DB)
+
+	jobRepository, err := mysql.NewJobRepository(conn)
+	if err != nil {
+		panic("error creating job repository: " + err.Error())
+	}
+
+	runnerConfig := jobs.LoadRunnerConfig()
+	jobRunner, err := jobs.NewRunner(jobRepository, runnerConfig)
+	if err != nil {
+		panic("error creating job runner: " + err.Error())
+	}
+
+	idempotencyStore := newIdempotencyStore(conn, registry)
+	registerIdempotencyPurge(idempotencyStore, jobRepository, jobRunner)
+
+	furyHandler := server.NewHTTPServer(app, newHandlers(conn, jobRepository, jobRunner, registry),
+		newMiddlewares(idempotencyStore, registry))
+	furyHandler.SetupRouter()
+
+	metricsServer := newMetricsServer(registry)
+
+	go func() {
+		if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Println("error serving metrics: " + err.Error())
+		}
+	}()
+
+	jobRunner.Start()
+	err = furyHandler.Run()
+
+	// The app returns once it stops serving, so the running jobs are drained before exiting.
+	ctx, cancel := context.WithTimeout(context.Background(), runnerConfig.DrainTimeout)
+	defer cancel()
+
+	if shutdownErr := metricsServer.Shutdown(ctx); shutdownErr != nil {
+		log.Println("error stopping the metrics server: " + shutdownErr.Error())
+	}
+
+	if stopErr := jobRunner.Stop(ctx); stopErr != nil {
+		log.Println("error draining jobs: " + stopErr.Error())
+	}
+
+	// The spans of the drained jobs are flushed as well.
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Println("error flushing spans: " + shutdownErr.Error())
+	}
+
+	return err
+}
+
+// newHandlers also registers on jobRunner the handlers of the job types, and on registry the
+// metrics of the repositories and the items.
+func newHandlers(conn *sqlx.DB, jobRepository ports.JobRepository, jobRunner ports.JobRunner,
+	registry *metrics.Registry) server.Handlers {
 	itemRepository, err := mysql.NewItemRepository(conn)
 	if err != nil {
 		panic("error creating item repository: " + err.Error())
 	}
 
-	itemService, err := services.NewItemService(itemRepository)
+	itemRepository, err = metrics.NewItemRepository(itemRepository, metrics.NewRepositoryMetrics(registry),
+		"mysql")
+	if err != nil {
+		panic("error creating measured item repository: " + err.Error())
+	}
+
+	metrics.RegisterItemStatus(registry, itemRepository, metrics.LoadConfig().ItemsRefreshInterval)
+
+	itemRepository, err = tracing.NewItemRepository(itemRepository, "mysql")
+	if err != nil {
+		panic("error creating traced item repository: " + err.Error())
+	}
+
+	revisionRepository, err := mysql.NewItemRevisionRepository(conn)
+	if err != nil {
+		panic("error creating item revision repository: " + err.Error())
+	}
+
+	itemService, err := services.NewItemService(itemRepository, revisionRepository)
 	if err != nil {
 		panic("error creating item service: " + err.Error())
 	}
 
-	itemHandler, err := handler.NewItemHandler(itemService)
+	itemService, err = services.NewAuditedItemService(itemService)
+	if err != nil {
+		panic("error creating audited item service: " + err.Error())
+	}
+
+	searchIndex := newSearchIndex(conn)
+	suggester := memory.NewItemSuggester()
+
+	itemService, err = services.NewIndexedItemService(itemService, searchIndex, suggester)
+	if err != nil {
+		panic("error creating indexed item service: " + err.Error())
+	}
+
+	itemService, err = tracing.NewItemService(itemService)
+	if err != nil {
+		panic("error creating traced item service: " + err.Error())
+	}
+
+	searchService, err := services.NewSearchService(itemRepository, searchIndex)
+	if err != nil {
+		panic("error creating search service: " + err.Error())
+	}
+
+	// The in-process index starts empty, so it is filled before serving.
+	if search.LoadConfig().Index == search.IndexMemory {
+		if err := searchService.ReindexItems(context.Background()); err != nil {
+			panic("error filling search index: " + err.Error())
+		}
+	}
+
+	importRepository, err := mysql.NewImportRepository(conn)
+	if err != nil {
+		panic("error creating import repository: " + err.Error())
+	}
+
+	jobService, err := services.NewJobService(jobRepository)
+	if err != nil {
+		panic("error creating job service: " + err.Error())
+	}
+
+	suggestService, err := services.NewSuggestService(itemRepository, suggester)
+	if err != nil {
+		panic("error creating suggest service: " + err.Error())
+	}
+
+	// The suggester lives in the process, so it is filled before serving and then refreshed for
+	// as long as the process runs, to pick up the writes served by the other instances.
+	if err := suggestService.RebuildSuggestions(context.Background()); err != nil {
+		panic("error filling suggestions: " + err.Error())
+	}
+
+	go suggestService.RefreshSuggestions(context.Background(), search.LoadConfig().SuggestionRefreshInterval)
+
+	importService, err := services.NewImportService(itemService, itemRepository, importRepository, jobService)
+	if err != nil {
+		panic("error creating import service: " + err.Error())
+	}
+
+	if err := jobRunner.Register(importService); err != nil {
+		panic("error registering import job handler: " + err.Error())
+	}
+
+	photoRepository, err := mysql.NewPhotoRepository(conn)
+	if err != nil {
+		panic("error creating photo repository: " + err.Error())
+	}
+
+	blobStore := newBlobStore()
+
+	photoService, err := services.NewPhotoService(itemService, photoRepository, blobStore,
+		imaging.NewPhotoProcessor(imaging.LoadConfig()), jobService)
+	if err != nil {
+		panic("error creating photo service: " + err.Error())
+	}
+
+	if err := jobRunner.Register(photoService); err != nil {
+		panic("error registering photo variants job handler: " + err.Error())
+	}
+
+	photoCheckHandler := newPhotoCheckHandler(itemService, photoRepository, jobService, jobRunner)
+
+	auditRepository, err := mysql.NewAuditRepository(conn)
+	if err != nil {
+		panic("error creating audit repository: " + err.Error())
+	}
+
+	auditService, err := services.NewAuditService(auditRepository)
+	if err != nil {
+		panic("error creating audit service: " + err.Error())
+	}
+
+	itemHandler, err := handler.NewItemHandler(itemService, handler.LoadItemHandlerConfig())
 	if err != nil {
 		panic("error creating item handler: " + err.Error())
 	}
 
-	return itemHandler
+	itemHandler, err = tracing.NewItemHandler(itemHandler)
+	if err != nil {
+		panic("error creating traced item handler: " + err.Error())
+	}
+
+	photoHandler, err := handler.NewPhotoHandler(photoService, handler.LoadPhotoHandlerConfig())
+	if err != nil {
+		panic("error creating photo handler: " + err.Error())
+	}
+
+	blobHandler, err := handler.NewBlobHandler(blobStore)
+	if err != nil {
+		panic("error creating blob handler: " + err.Error())
+	}
+
+	auditHandler, err := handler.NewAuditHandler(auditService)
+	if err != nil {
+		panic("error creating audit handler: " + err.Error())
+	}
+
+	importHandler, err := handler.NewImportHandler(importService, handler.LoadImportHandlerConfig())
+	if err != nil {
+		panic("error creating import handler: " + err.Error())
+	}
+
+	jobHandler, err := handler.NewJobHandler(jobService)
+	if err != nil {
+		panic("error creating job handler: " + err.Error())
+	}
+
+	searchHandler, err := handler.NewSearchHandler(searchService, handler.LoadSearchHandlerConfig())
+	if err != nil {
+		panic("error creating search handler: " + err.Error())
+	}
+
+	suggestHandler, err := handler.NewSuggestHandler(suggestService)
+	if err != nil {
+		panic("error creating suggest handler: " + err.Error())
+	}
+
+	return server.Handlers{
+		ItemHandler:       itemHandler,
+		PhotoHandler:      photoHandler,
+		PhotoCheckHandler: photoCheckHandler,
+		AuditHandler:      auditHandler,
+		ImportHandler:     importHandler,
+		JobHandler:        jobHandler,
+		SearchHandler:     searchHandler,
+		SuggestHandler:    suggestHandler,
+		BlobHandler:       blobHandler,
+		LogHandler:        handler.NewLogHandler(),
+	}
+}
+
+// newMetricsServer serves the metrics of registry on the internal address of METRICS_ADDR.
+func newMetricsServer(registry *metrics.Registry) *http.Server {
+	metricsHandler, err := handler.NewMetricsHandler(registry)
+	if err != nil {
+		panic("error creating metrics handler: " + err.Error())
+	}
+
+	return server.NewMetricsServer(metrics.LoadConfig().Addr, metricsHandler)
+}
+
+// newPhotoCheckHandler also registers the check job on jobRunner and makes sure a check is
+// scheduled, as every check schedules the next one. Without PHOTO_URL_HOSTS there is no host the
+// checker may request, so the photos are not checked and there is no handler.
+func newPhotoCheckHandler(itemService ports.ItemService, photoRepository ports.PhotoRepository,
+	jobService ports.JobService, jobRunner ports.JobRunner) handler.PhotoCheckHandler {
+	config := photocheck.LoadConfig()
+	if len(config.Hosts) == 0 {
+		log.Println("warning: PHOTO_URL_HOSTS is empty, the photo URLs will not be checked")
+		return nil
+	}
+
+	checker, err := photocheck.NewURLChecker(photocheck.NewHTTPClient(config), config.Hosts, config.Concurrency)
+	if err != nil {
+		panic("error creating photo url checker: " + err.Error())
+	}
+
+	photoCheckService, err := services.NewPhotoCheckService(itemService, photoRepository, checker, jobService,
+		config.Interval, config.Deactivate, config.DeactivateAfter)
+	if err != nil {
+		panic("error creating photo check service: " + err.Error())
+	}
+
+	if err := jobRunner.Register(photoCheckService); err != nil {
+		panic("error registering photo check job handler: " + err.Error())
+	}
+
+	if err := photoCheckService.ScheduleChecks(context.Background()); err != nil {
+		panic("error scheduling photo checks: " + err.Error())
+	}
+
+	photoCheckHandler, err := handler.NewPhotoCheckHandler(photoCheckService)
+	if err != nil {
+		panic("error creating photo check handler: " + err.Error())
+	}
+
+	return photoCheckHandler
+}
+
+// s3Timeout bounds every request to an S3 blob store, uploads included.
+const s3Timeout = time.Minute
+
+func newBlobStore() ports.BlobStore {
+	config := blob.LoadConfig()
+
+	var (
+		blobStore ports.BlobStore
+		err       error
+	)
+
+	if config.Store == blob.StoreS3 {
+		blobStore, err = blob.NewS3Store(config.S3, config.BaseURL, &http.Client{Timeout: s3Timeout})
+	} else {
+		blobStore, err = blob.NewLocalStore(config.LocalDir, config.BaseURL)
+	}
+
+	if err != nil {
+		panic("error creating blob store: " + err.Error())
+	}
+
+	return blobStore
+}
+
+func newSearchIndex(conn *sqlx.DB) ports.ItemSearchIndex {
+	if search.LoadConfig().Index == search.IndexMemory {
+		return memory.NewItemSearchIndex()
+	}
+
+	searchIndex, err := mysql.NewItemSearchIndex(conn)
+	if err != nil {
+		panic("error creating search index: " + err.Error())
+	}
+
+	return searchIndex
+}
+
+func newIdempotencyStore(conn *sqlx.DB, registry *metrics.Registry) ports.IdempotencyStore {
+	idempotencyStore, err := mysql.NewIdempotencyStore(conn)
+	if err != nil {
+		panic("error creating idempotency store: " + err.Error())
+	}
+
+	idempotencyStore, err = metrics.NewIdempotencyStore(idempotencyStore, metrics.NewCacheMetrics(registry))
+	if err != nil {
+		panic("error creating measured idempotency store: " + err.Error())
+	}
+
+	return idempotencyStore
+}
+
+// registerIdempotencyPurge also makes sure a purge is scheduled, as every purge schedules the next one.
+func registerIdempotencyPurge(idempotencyStore ports.IdempotencyStore, jobRepository ports.JobRepository,
+	jobRunner ports.JobRunner) {
+	jobService, err := services.NewJobService(jobRepository)
+	if err != nil {
+		panic("error creating job service: " + err.Error())
+	}
+
+	purgeService, err := services.NewIdempotencyPurgeService(idempotencyStore, jobService,
+		middleware.LoadIdempotencyConfig().PurgeInterval)
+	if err != nil {
+		panic("error creating idempotency purge service: " + err.Error())
+	}
+
+	if err := jobRunner.Register(purgeService); err != nil {
+		panic("error registering idempotency purge job handler: " + err.Error())
+	}
+
+	if err := purgeService.SchedulePurges(context.Background()); err != nil {
+		panic("error scheduling idempotency purges: " + err.Error())
+	}
+}
+
+func newMiddlewares(idempotencyStore ports.IdempotencyStore, registry *metrics.Registry) server.Middlewares {
+	return server.Middlewares{
+		RateLimitStore:   memory.NewRateLimitStore(),
+		RateLimits:       middleware.LoadRateLimitConfig(),
+		IdempotencyStore: idempotencyStore,
+		Idempotency:      middleware.LoadIdempotencyConfig(),
+		RouteMetrics:     middleware.NewRouteMetrics(registry),
+		AccessLog:        middleware.NewAccessLog(middleware.LoadAccessLogConfig()),
+		Auth:             middleware.LoadAuthConfig(),
+	}
 }
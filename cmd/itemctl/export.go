@@ -0,0 +1,147 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/core/services"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/exporter"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/importer"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/mysql"
+)
+
+// runExport writes the items to a local file with the same formats and filters as GET /v1/items/export.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("o", "", "file to write, ending in .gz to compress it (required)")
+	format := flags.String("format", "", "file format, csv or jsonl (default: from the file extension)")
+	itemType := flags.String("item-type", "", "export only the items of this type")
+	status := flags.String("status", "", "export only the items with this status")
+	leader := flags.String("leader", "", "export only leader (true) or non leader (false) items")
+	updatedFrom := flags.String("updated-from", "", "export only the items updated at or after this RFC3339 time")
+	updatedTo := flags.String("updated-to", "", "export only the items updated at or before this RFC3339 time")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: itemctl export [flags] -o <file>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		flags.Usage()
+		return fmt.Errorf("export expects an output file")
+	}
+
+	compress := strings.HasSuffix(*output, ".gz")
+	if *format == "" {
+		*format = importer.FormatOf(strings.TrimSuffix(*output, ".gz"))
+	}
+
+	filter := domain.ItemFilter{
+		ItemType: *itemType,
+		Status:   *status,
+	}
+
+	if *leader != "" {
+		value, err := strconv.ParseBool(*leader)
+		if err != nil {
+			return fmt.Errorf("leader must be true or false")
+		}
+
+		filter.Leader = &value
+	}
+
+	for target, value := range map[*time.Time]string{&filter.UpdatedFrom: *updatedFrom, &filter.UpdatedTo: *updatedTo} {
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid time %q: %w", value, err)
+		}
+
+		*target = parsed
+	}
+
+	itemService, err := newExportService()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var w io.Writer = file
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(file)
+		w = gzipWriter
+	}
+
+	writer, err := exporter.NewItemWriter(*format, w)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = itemService.ExportItems(context.Background(), filter, func(item *domain.Item) error {
+		count++
+		return writer.Write(item)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	if gzipWriter != nil {
+		if err := gzipWriter.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("exported %d items to %s\n", count, *output)
+
+	return nil
+}
+
+func newExportService() (ports.ItemService, error) {
+	conn, err := mysql.GetConnectionDB()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to DB: %w", err)
+	}
+
+	itemRepository, err := mysql.NewItemRepository(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	revisionRepository, err := mysql.NewItemRevisionRepository(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return services.NewItemService(itemRepository, revisionRepository)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/core/services"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/importer"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/mysql"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/i18n"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+var errFailedRows = errors.New("some rows could not be imported")
+
+// runImport imports a file synchronously with the same rules as POST /v1/imports.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "file format, csv or jsonl (default: from the file extension)")
+	dryRun := flags.Bool("dry-run", false, "validate the rows without writing any item")
+	report := flags.String("errors", "", "write the per-row error report as CSV to this file (default: stderr)")
+	language := flags.String("lang", i18n.DefaultLanguage, "language of the error messages")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: itemctl import [flags] <file>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("import expects exactly one file")
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = importer.FormatOf(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	validator, err := validation.New(validation.LoadConfig())
+	if err != nil {
+		return err
+	}
+
+	rows, err := importer.ReadRows(*format, file, validator, i18n.Negotiate(*language))
+	if err != nil {
+		return err
+	}
+
+	importService, err := newImportService()
+	if err != nil {
+		return err
+	}
+
+	ctx := marketcontext.WithCaller(context.Background(), marketcontext.Caller{ID: actor()})
+
+	itemImport, err := importService.RunImport(ctx, domain.Import{Format: *format, DryRun: *dryRun}, rows)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("import %d %s: %d rows, %d created, %d updated, %d failed\n", itemImport.ID, itemImport.Status,
+		itemImport.TotalRows, itemImport.CreatedRows, itemImport.UpdatedRows, itemImport.FailedRows)
+
+	if itemImport.Status == domain.ImportStatusFailed {
+		return fmt.Errorf("import %d failed: %s", itemImport.ID, itemImport.Error)
+	}
+
+	if itemImport.FailedRows == 0 {
+		return nil
+	}
+
+	importErrors, err := importService.GetImportErrors(ctx, itemImport.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := writeReport(*report, importErrors, i18n.Negotiate(*language)); err != nil {
+		return err
+	}
+
+	return errFailedRows
+}
+
+// newImportService wires the import service the same way the API does, so imported items
+// are audited and get revisions.
+func newImportService() (ports.ImportService, error) {
+	conn, err := mysql.GetConnectionDB()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to DB: %w", err)
+	}
+
+	itemRepository, err := mysql.NewItemRepository(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	revisionRepository, err := mysql.NewItemRevisionRepository(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	importRepository, err := mysql.NewImportRepository(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	jobRepository, err := mysql.NewJobRepository(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	itemService, err := services.NewItemService(itemRepository, revisionRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	itemService, err = services.NewAuditedItemService(itemService)
+	if err != nil {
+		return nil, err
+	}
+
+	jobService, err := services.NewJobService(jobRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	return services.NewImportService(itemService, itemRepository, importRepository, jobService)
+}
+
+func writeReport(path string, importErrors []domain.ImportError, language string) error {
+	var w io.Writer = os.Stderr
+
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	return importer.WriteErrorReport(w, importErrors, language)
+}
+
+// actor is the audit actor of the items written by the command.
+func actor() string {
+	current, err := user.Current()
+	if err != nil {
+		return "itemctl"
+	}
+
+	return "itemctl:" + current.Username
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+const usage = `usage: itemctl <command> [flags]
+
+commands:
+  import    create or update items from a CSV or JSON Lines file
+  export    write the items to a CSV or JSON Lines file
+  migrate   apply the database migrations not applied yet
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch os.Args[1] {
+	case "import":
+		err = runImport(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	case "migrate":
+		err = runMigrate(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "itemctl: "+log.Redact(err.Error()))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/mysql"
+)
+
+// runMigrate applies the migrations to the database of GO_ENVIRONMENT. The API applies them at
+// startup except in production, where they are applied with this command before deploying.
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: itemctl migrate")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	migrated, err := mysql.MigrateDB()
+	for _, m := range migrated {
+		fmt.Printf("applied migration %d %s\n", m.Version, m.Name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(migrated) == 0 {
+		fmt.Println("the database is up to date")
+	}
+
+	return nil
+}
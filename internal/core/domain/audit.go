@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditEntityItem = "item"
+
+	AuditOperationCreate = "CREATE"
+	AuditOperationUpdate = "UPDATE"
+	AuditOperationPatch  = "PATCH"
+	AuditOperationDelete = "DELETE"
+	AuditOperationRevert = "REVERT"
+)
+
+// AuditEntry records a write. Entries form a hash chain: Hash covers every field of the entry
+// plus the Hash of the previous one, so editing or removing an entry breaks the chain.
+type AuditEntry struct {
+	ID         uint
+	Actor      string
+	RequestID  string
+	ClientIP   string
+	Operation  string
+	EntityType string
+	EntityID   uint
+	Before     json.RawMessage
+	After      json.RawMessage
+	CreatedAt  time.Time
+	PrevHash   string
+	Hash       string
+}
+
+type AuditFilter struct {
+	EntityType string
+	EntityID   uint
+	Actor      string
+	From       time.Time
+	To         time.Time
+	AfterID    uint
+	Limit      int
+}
+
+// ComputeHash returns the chained hash of the entry. CreatedAt is hashed with millisecond
+// precision in UTC, which is what the storage keeps.
+func (e AuditEntry) ComputeHash() string {
+	content, _ := json.Marshal(struct {
+		Actor      string          `json:"actor"`
+		RequestID  string          `json:"requestId"`
+		ClientIP   string          `json:"clientIp"`
+		Operation  string          `json:"operation"`
+		EntityType string          `json:"entityType"`
+		EntityID   uint            `json:"entityId"`
+		Before     json.RawMessage `json:"before"`
+		After      json.RawMessage `json:"after"`
+		CreatedAt  string          `json:"createdAt"`
+		PrevHash   string          `json:"prevHash"`
+	}{
+		Actor:      e.Actor,
+		RequestID:  e.RequestID,
+		ClientIP:   e.ClientIP,
+		Operation:  e.Operation,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     nullIfEmpty(e.Before),
+		After:      nullIfEmpty(e.After),
+		CreatedAt:  e.CreatedAt.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano),
+		PrevHash:   e.PrevHash,
+	})
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func nullIfEmpty(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+
+	return raw
+}
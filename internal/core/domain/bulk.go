@@ -0,0 +1,34 @@
+package domain
+
+const (
+	// BulkModeAtomic creates every item in a single transaction or none of them.
+	BulkModeAtomic = "atomic"
+	// BulkModePartial creates the valid items and reports the failing ones.
+	BulkModePartial = "partial"
+)
+
+const (
+	BulkStatusCreated = "created"
+	BulkStatusFailed  = "failed"
+	// BulkStatusSkipped marks a valid item that was not created because its atomic batch was rejected.
+	BulkStatusSkipped = "skipped"
+)
+
+// BulkItemResult is the outcome of one item of a bulk creation, Index being its position in the batch.
+type BulkItemResult struct {
+	Index  int
+	Status string
+	Item   *Item
+	Err    error
+}
+
+// BulkFailed reports whether any item of a bulk creation failed.
+func BulkFailed(results []BulkItemResult) bool {
+	for _, result := range results {
+		if result.Status == BulkStatusFailed {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error codes are part of the API contract: clients match on them, so they never change
+// once published, while titles and details are free to be reworded.
+const (
+	ErrCodeItemValidationFailed    = "ITEM_VALIDATION_FAILED"
+	ErrCodeItemCodeDuplicated      = "ITEM_CODE_DUPLICATED"
+	ErrCodeItemLeaderLevelInvalid  = "ITEM_LEADER_LEVEL_INVALID"
+	ErrCodeItemPhotosRequired      = "ITEM_PHOTOS_REQUIRED"
+	ErrCodeItemPhotosLimit         = "ITEM_PHOTOS_LIMIT_EXCEEDED"
+	ErrCodeItemPhotoOrderInvalid   = "ITEM_PHOTO_ORDER_INVALID"
+	ErrCodePhotoTypeUnsupported    = "PHOTO_TYPE_UNSUPPORTED"
+	ErrCodePhotoTooLarge           = "PHOTO_TOO_LARGE"
+	ErrCodePhotoTooManyPixels      = "PHOTO_TOO_MANY_PIXELS"
+	ErrCodeRevisionNotRestorable   = "ITEM_REVISION_NOT_RESTORABLE"
+	ErrCodeBulkRejected            = "ITEM_BULK_REJECTED"
+	ErrCodeBulkTooLarge            = "ITEM_BULK_TOO_LARGE"
+	ErrCodeJobFinished             = "JOB_ALREADY_FINISHED"
+	ErrCodeRequestValidationFailed = "REQUEST_VALIDATION_FAILED"
+	ErrCodeMalformedRequest        = "MALFORMED_REQUEST"
+	ErrCodeResourceNotFound        = "RESOURCE_NOT_FOUND"
+	ErrCodeVersionConflict         = "VERSION_CONFLICT"
+	ErrCodePreconditionFailed      = "PRECONDITION_FAILED"
+	ErrCodePreconditionRequired    = "PRECONDITION_REQUIRED"
+	ErrCodeIdempotencyKeyReused    = "IDEMPOTENCY_KEY_REUSED"
+	ErrCodeIdempotencyInProgress   = "IDEMPOTENCY_REQUEST_IN_PROGRESS"
+	ErrCodeRateLimitExceeded       = "RATE_LIMIT_EXCEEDED"
+	ErrCodeUnauthorized            = "UNAUTHORIZED"
+	ErrCodeForbidden               = "FORBIDDEN"
+	ErrCodeInternal                = "INTERNAL_ERROR"
+)
+
+type ErrorDefinition struct {
+	Code   string
+	Status int
+	Title  string
+}
+
+var errorCatalog = map[string]ErrorDefinition{
+	ErrCodeItemValidationFailed:    {ErrCodeItemValidationFailed, http.StatusBadRequest, "Item validation failed"},
+	ErrCodeItemCodeDuplicated:      {ErrCodeItemCodeDuplicated, http.StatusBadRequest, "Item code already exists"},
+	ErrCodeItemLeaderLevelInvalid:  {ErrCodeItemLeaderLevelInvalid, http.StatusBadRequest, "Invalid leader level"},
+	ErrCodeItemPhotosRequired:      {ErrCodeItemPhotosRequired, http.StatusBadRequest, "Photos required"},
+	ErrCodeItemPhotosLimit:         {ErrCodeItemPhotosLimit, http.StatusBadRequest, "Too many photos"},
+	ErrCodeItemPhotoOrderInvalid:   {ErrCodeItemPhotoOrderInvalid, http.StatusBadRequest, "Invalid photo order"},
+	ErrCodePhotoTypeUnsupported:    {ErrCodePhotoTypeUnsupported, http.StatusUnsupportedMediaType, "Unsupported photo type"},
+	ErrCodePhotoTooLarge:           {ErrCodePhotoTooLarge, http.StatusRequestEntityTooLarge, "Photo too large"},
+	ErrCodePhotoTooManyPixels:      {ErrCodePhotoTooManyPixels, http.StatusRequestEntityTooLarge, "Photo too large"},
+	ErrCodeRevisionNotRestorable:   {ErrCodeRevisionNotRestorable, http.StatusBadRequest, "Revision not restorable"},
+	ErrCodeBulkRejected:            {ErrCodeBulkRejected, http.StatusUnprocessableEntity, "Bulk creation rejected"},
+	ErrCodeBulkTooLarge:            {ErrCodeBulkTooLarge, http.StatusRequestEntityTooLarge, "Too many items"},
+	ErrCodeJobFinished:             {ErrCodeJobFinished, http.StatusConflict, "Job already finished"},
+	ErrCodeRequestValidationFailed: {ErrCodeRequestValidationFailed, http.StatusBadRequest, "Request validation failed"},
+	ErrCodeMalformedRequest:        {ErrCodeMalformedRequest, http.StatusBadRequest, "Malformed request"},
+	ErrCodeResourceNotFound:        {ErrCodeResourceNotFound, http.StatusNotFound, "Resource not found"},
+	ErrCodeVersionConflict:         {ErrCodeVersionConflict, http.StatusPreconditionFailed, "Version conflict"},
+	ErrCodePreconditionFailed:      {ErrCodePreconditionFailed, http.StatusPreconditionFailed, "Precondition failed"},
+	ErrCodePreconditionRequired:    {ErrCodePreconditionRequired, http.StatusPreconditionRequired, "Precondition required"},
+	ErrCodeIdempotencyKeyReused:    {ErrCodeIdempotencyKeyReused, http.StatusConflict, "Idempotency key reused"},
+	ErrCodeIdempotencyInProgress:   {ErrCodeIdempotencyInProgress, http.StatusConflict, "Request in progress"},
+	ErrCodeRateLimitExceeded:       {ErrCodeRateLimitExceeded, http.StatusTooManyRequests, "Rate limit exceeded"},
+	ErrCodeUnauthorized:            {ErrCodeUnauthorized, http.StatusUnauthorized, "Unauthorized"},
+	ErrCodeForbidden:               {ErrCodeForbidden, http.StatusForbidden, "Forbidden"},
+	ErrCodeInternal:                {ErrCodeInternal, http.StatusInternalServerError, "Internal error"},
+}
+
+// LookupError returns the definition of a code, falling back to the internal error one.
+func LookupError(code string) ErrorDefinition {
+	if definition, ok := errorCatalog[code]; ok {
+		return definition
+	}
+
+	return errorCatalog[ErrCodeInternal]
+}
+
+// CodedError is implemented by the errors that belong to the catalogue.
+type CodedError interface {
+	error
+	ErrorCode() string
+}
+
+// Error is a catalogue error raised outside of the item model, e.g. by the transport layer.
+// Args are the values interpolated in Message, as in ItemError.
+type Error struct {
+	Code    string
+	Message string
+	Args    []interface{}
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+func (e Error) ErrorCode() string {
+	return e.Code
+}
+
+func (e Error) ErrorArgs() []interface{} {
+	return e.Args
+}
+
+// ErrorCodeOf returns the catalogue code of err, or ErrCodeInternal when err is not a CodedError.
+func ErrorCodeOf(err error) string {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+
+	return ErrCodeInternal
+}
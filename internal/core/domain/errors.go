@@ -2,14 +2,30 @@ package domain
 
 import "fmt"
 
+// ItemError is a validation error of the item model. Code defaults to ErrCodeItemValidationFailed.
+// Args are the values interpolated in Message, kept apart so the message can be translated.
 type ItemError struct {
+	Code    string
 	Message string
+	Args    []interface{}
 }
 
 func (e ItemError) Error() string {
 	return fmt.Sprintf("item error: '%s'", e.Message)
 }
 
+func (e ItemError) ErrorCode() string {
+	if e.Code == "" {
+		return ErrCodeItemValidationFailed
+	}
+
+	return e.Code
+}
+
+func (e ItemError) ErrorArgs() []interface{} {
+	return e.Args
+}
+
 type ResourceNotFoundError struct {
 	Message string
 }
@@ -17,3 +33,19 @@ type ResourceNotFoundError struct {
 func (e ResourceNotFoundError) Error() string {
 	return e.Message
 }
+
+func (e ResourceNotFoundError) ErrorCode() string {
+	return ErrCodeResourceNotFound
+}
+
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
+func (e ConflictError) ErrorCode() string {
+	return ErrCodeVersionConflict
+}
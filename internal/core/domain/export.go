@@ -0,0 +1,7 @@
+package domain
+
+// Exports are written in the import formats, so an export can be imported back.
+const (
+	ExportFormatCSV   = ImportFormatCSV
+	ExportFormatJSONL = ImportFormatJSONL
+)
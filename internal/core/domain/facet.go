@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	FacetStatus      = "status"
+	FacetItemType    = "itemType"
+	FacetLeader      = "leader"
+	FacetLeaderLevel = "leaderLevel"
+	FacetPrice       = "price"
+)
+
+// facetValues are the values counted for each facet but the price, whose values are the price ranges of the search.
+var facetValues = map[string][]string{ //nolint:gochecknoglobals
+	FacetStatus:      {StatusActive, StatusInactive},
+	FacetItemType:    {ItemTypeOwn, ItemTypeSeller},
+	FacetLeader:      {"true", "false"},
+	FacetLeaderLevel: {LeaderLevelBasic, LeaderLevelGold, LeaderLevelPlatinum},
+}
+
+// Facets lists the facets in the order they are returned.
+var Facets = []string{FacetStatus, FacetItemType, FacetLeader, FacetLeaderLevel, FacetPrice} //nolint:gochecknoglobals
+
+// PriceRange is a price bucket including Min and excluding Max. A nil bound is open.
+type PriceRange struct {
+	Min *int
+	Max *int
+}
+
+// Key is the value of the range in the price facet, e.g. "1000-5000", "*-1000" or "20000-*".
+func (priceRange PriceRange) Key() string {
+	bound := func(value *int) string {
+		if value == nil {
+			return "*"
+		}
+		return strconv.Itoa(*value)
+	}
+
+	return fmt.Sprintf("%s-%s", bound(priceRange.Min), bound(priceRange.Max))
+}
+
+// Contains reports whether the price falls in the range.
+func (priceRange PriceRange) Contains(price int) bool {
+	return (priceRange.Min == nil || price >= *priceRange.Min) && (priceRange.Max == nil || price < *priceRange.Max)
+}
+
+// PriceRanges splits the prices at the given increasing edges, from an open lower range to an open upper one.
+func PriceRanges(edges []int) []PriceRange {
+	ranges := make([]PriceRange, 0, len(edges)+1)
+
+	var previous *int
+	for i := range edges {
+		edge := edges[i]
+		ranges = append(ranges, PriceRange{Min: previous, Max: &edge})
+		previous = &edge
+	}
+
+	return append(ranges, PriceRange{Min: previous})
+}
+
+// FacetCount is the number of items with a value of a facet. Selected tells whether the
+// value is part of the filter of the search.
+type FacetCount struct {
+	Value    string
+	Count    int
+	Selected bool
+}
+
+// FacetValue returns the value of an item for a facet, or "" when it has none.
+func FacetValue(item *Item, facet string, priceRanges []PriceRange) string {
+	switch facet {
+	case FacetStatus:
+		return item.Status
+	case FacetItemType:
+		return item.ItemType
+	case FacetLeader:
+		return strconv.FormatBool(item.Leader)
+	case FacetLeaderLevel:
+		return item.LeaderLevel
+	case FacetPrice:
+		for _, priceRange := range priceRanges {
+			if priceRange.Contains(item.Price) {
+				return priceRange.Key()
+			}
+		}
+	}
+
+	return ""
+}
+
+// FacetSelected reports whether a value of a facet is part of the filter.
+func FacetSelected(filter ItemFilter, facet, value string) bool {
+	switch facet {
+	case FacetStatus:
+		return filter.Status == value
+	case FacetItemType:
+		return filter.ItemType == value
+	case FacetLeader:
+		return filter.Leader != nil && strconv.FormatBool(*filter.Leader) == value
+	case FacetLeaderLevel:
+		return filter.LeaderLevel == value
+	case FacetPrice:
+		return (filter.PriceMin != nil || filter.PriceMax != nil) &&
+			PriceRange{Min: filter.PriceMin, Max: filter.PriceMax}.Key() == value
+	}
+
+	return false
+}
+
+// FacetValues returns the values counted for a facet.
+func FacetValues(facet string, priceRanges []PriceRange) []string {
+	if facet != FacetPrice {
+		return facetValues[facet]
+	}
+
+	values := make([]string, 0, len(priceRanges))
+	for _, priceRange := range priceRanges {
+		values = append(values, priceRange.Key())
+	}
+
+	return values
+}
+
+// NewFacetCounts lists, for every facet, the count of each of its values, in the order of FacetValues.
+func NewFacetCounts(counts map[string]map[string]int, filter ItemFilter,
+	priceRanges []PriceRange) map[string][]FacetCount {
+	facets := make(map[string][]FacetCount, len(Facets))
+
+	for _, facet := range Facets {
+		facets[facet] = []FacetCount{}
+		for _, value := range FacetValues(facet, priceRanges) {
+			facets[facet] = append(facets[facet], FacetCount{
+				Value:    value,
+				Count:    counts[facet][value],
+				Selected: FacetSelected(filter, facet, value),
+			})
+		}
+	}
+
+	return facets
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPriceRanges(t *testing.T) {
+	ranges := PriceRanges([]int{1000, 5000})
+
+	keys := make([]string, 0, len(ranges))
+	for _, priceRange := range ranges {
+		keys = append(keys, priceRange.Key())
+	}
+
+	if want := []string{"*-1000", "1000-5000", "5000-*"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("PriceRanges() keys = %v, want %v", keys, want)
+	}
+
+	tests := []struct {
+		price int
+		want  string
+	}{
+		{price: 0, want: "*-1000"},
+		{price: 999, want: "*-1000"},
+		{price: 1000, want: "1000-5000"},
+		{price: 4999, want: "1000-5000"},
+		{price: 5000, want: "5000-*"},
+		{price: 1000000, want: "5000-*"},
+	}
+
+	for _, test := range tests {
+		item := &Item{Price: test.price}
+		if got := FacetValue(item, FacetPrice, ranges); got != test.want {
+			t.Errorf("FacetValue(price %d) = %q, want %q", test.price, got, test.want)
+		}
+	}
+}
+
+func TestFacetSelected(t *testing.T) {
+	leader := true
+	priceMin, priceMax := 1000, 5000
+
+	filter := ItemFilter{
+		Status:   StatusActive,
+		Leader:   &leader,
+		PriceMin: &priceMin,
+		PriceMax: &priceMax,
+	}
+
+	tests := []struct {
+		facet string
+		value string
+		want  bool
+	}{
+		{facet: FacetStatus, value: StatusActive, want: true},
+		{facet: FacetStatus, value: StatusInactive, want: false},
+		{facet: FacetItemType, value: ItemTypeOwn, want: false},
+		{facet: FacetLeader, value: "true", want: true},
+		{facet: FacetLeader, value: "false", want: false},
+		{facet: FacetLeaderLevel, value: LeaderLevelGold, want: false},
+		{facet: FacetPrice, value: "1000-5000", want: true},
+		{facet: FacetPrice, value: "5000-*", want: false},
+	}
+
+	for _, test := range tests {
+		if got := FacetSelected(filter, test.facet, test.value); got != test.want {
+			t.Errorf("FacetSelected(%s, %s) = %v, want %v", test.facet, test.value, got, test.want)
+		}
+	}
+
+	if FacetSelected(ItemFilter{}, FacetPrice, "*-*") {
+		t.Error("FacetSelected() of the price without a price filter = true, want false")
+	}
+}
+
+func TestItemFilterWithout(t *testing.T) {
+	leader := false
+	priceMin := 1000
+
+	filter := ItemFilter{
+		ItemType:    ItemTypeSeller,
+		Status:      StatusActive,
+		Leader:      &leader,
+		LeaderLevel: LeaderLevelGold,
+		PriceMin:    &priceMin,
+	}
+
+	tests := []struct {
+		facet string
+		clear func(filter *ItemFilter)
+	}{
+		{facet: FacetStatus, clear: func(filter *ItemFilter) { filter.Status = "" }},
+		{facet: FacetItemType, clear: func(filter *ItemFilter) { filter.ItemType = "" }},
+		{facet: FacetLeader, clear: func(filter *ItemFilter) { filter.Leader = nil }},
+		{facet: FacetLeaderLevel, clear: func(filter *ItemFilter) { filter.LeaderLevel = "" }},
+		{facet: FacetPrice, clear: func(filter *ItemFilter) { filter.PriceMin = nil }},
+	}
+
+	for _, test := range tests {
+		want := filter
+		test.clear(&want)
+
+		if got := filter.Without(test.facet); !reflect.DeepEqual(got, want) {
+			t.Errorf("Without(%s) = %+v, want %+v", test.facet, got, want)
+		}
+	}
+}
+
+func TestNewFacetCounts(t *testing.T) {
+	counts := map[string]map[string]int{
+		FacetStatus: {StatusActive: 3, StatusInactive: 1},
+		FacetPrice:  {"*-1000": 2},
+	}
+
+	facets := NewFacetCounts(counts, ItemFilter{Status: StatusInactive}, PriceRanges([]int{1000}))
+
+	if len(facets) != len(Facets) {
+		t.Fatalf("NewFacetCounts() has %d facets, want %d", len(facets), len(Facets))
+	}
+
+	want := map[string][]FacetCount{
+		FacetStatus: {
+			{Value: StatusActive, Count: 3},
+			{Value: StatusInactive, Count: 1, Selected: true},
+		},
+		FacetLeader: {
+			{Value: "true"},
+			{Value: "false"},
+		},
+		FacetPrice: {
+			{Value: "*-1000", Count: 2},
+			{Value: "1000-*"},
+		},
+	}
+
+	for facet, wantCounts := range want {
+		if !reflect.DeepEqual(facets[facet], wantCounts) {
+			t.Errorf("NewFacetCounts()[%s] = %+v, want %+v", facet, facets[facet], wantCounts)
+		}
+	}
+}
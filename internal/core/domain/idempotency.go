@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key.
+// A record that is not Completed belongs to a request still being processed, which renews
+// its lease while it runs. Once the lease expires the request is taken for dead and the
+// next request with the key takes it over. Owner identifies the claim of the request that
+// reserved the key, so a request whose claim was taken over can not change the record.
+type IdempotencyRecord struct {
+	Key            string
+	Owner          string
+	Fingerprint    string
+	Completed      bool
+	StatusCode     int
+	Header         http.Header
+	Body           []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+	LeaseExpiresAt time.Time
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+	ImportStatusCancelled = "cancelled"
+)
+
+// Import is a batch of items loaded from a file. Rows are upserted by item code: a code that
+// already exists updates its item, any other creates one. A dry run only validates the rows and
+// counts what would be created and updated. Imports started by the API run as the job JobID.
+type Import struct {
+	ID            uint
+	Format        string
+	DryRun        bool
+	Status        string
+	TotalRows     int
+	ProcessedRows int
+	CreatedRows   int
+	UpdatedRows   int
+	FailedRows    int
+	JobID         uint
+	Error         string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	CompletedAt   *time.Time
+}
+
+// ImportRow is a parsed row of an import file. Line is the line of the row in the file, and
+// Errors are the problems found while parsing it, in which case Item is not usable.
+type ImportRow struct {
+	Line   int
+	Item   Item
+	Errors []ImportError
+}
+
+// ImportError is a problem of one row of an import. Field is empty when it is not about a single field.
+// Args are the values interpolated in Message, as in ItemError, for the errors of a whole row.
+type ImportError struct {
+	Line    int
+	Field   string
+	Code    string
+	Message string
+	Args    []interface{}
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 const (
 	ItemTypeOwn         = "OWN"
@@ -11,6 +14,8 @@ const (
 	LeaderLevelBasic    = "BASIC"
 	LeaderLevelGold     = "GOLD"
 	LeaderLevelPlatinum = "PLATINUM"
+	// MaxPhotosPerItem is the largest number of photos an item can have.
+	MaxPhotosPerItem = 20
 )
 
 type Item struct {
@@ -24,6 +29,7 @@ type Item struct {
 	Leader      bool
 	LeaderLevel string
 	Status      string
+	Version     uint
 	Photos      []Photo
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
@@ -33,15 +39,173 @@ type Photo struct {
 	ID        uint
 	Path      string
 	ItemID    uint
+	Position  int
+	IsPrimary bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// BlobKey is the key of the uploaded content in the blob store, empty for a photo given by URL.
+	BlobKey string
+	// Variants are the URLs of the resized copies of an uploaded photo, by PhotoVariant name.
+	// Like any field of the photos, setting them is a new version of the item.
+	Variants map[string]string
+	// Hash is the perceptual hash of an uploaded photo, nil until it is computed along with
+	// its variants.
+	Hash *uint64
+	// Check is the last check of the URL of a photo given by URL, nil until it is checked.
+	// Like the variants, recording it is a new version of the item.
+	Check *PhotoCheck
 }
 
+// SetStatus derives the status from the stock. An item whose primary photo was found broken
+// by a check that deactivates items is inactive as well, until the photo is fixed or replaced.
 func (item *Item) SetStatus() {
-	if item.Stock > 0 {
+	if item.Stock > 0 && !item.primaryPhotoDeactivates() {
 		item.Status = StatusActive
 		return
 	}
 
 	item.Status = StatusInactive
 }
+
+func (item *Item) primaryPhotoDeactivates() bool {
+	primary := item.primaryPhotoIndex()
+	if primary < 0 {
+		return false
+	}
+
+	return item.Photos[primary].Check.Deactivates()
+}
+
+// ArrangePhotos numbers the photos by their order in the slice and keeps a single primary
+// photo: the first one flagged as primary, or the first photo when none is.
+func (item *Item) ArrangePhotos() {
+	primary := item.primaryPhotoIndex()
+
+	for i := range item.Photos {
+		item.Photos[i].Position = i
+		item.Photos[i].IsPrimary = i == primary
+	}
+}
+
+// primaryPhotoIndex returns the index of the photo ArrangePhotos makes the primary one, or -1
+// when there is no photo.
+func (item *Item) primaryPhotoIndex() int {
+	for i := range item.Photos {
+		if item.Photos[i].IsPrimary {
+			return i
+		}
+	}
+
+	if len(item.Photos) == 0 {
+		return -1
+	}
+
+	return 0
+}
+
+// KeepPhotoChecks copies the checks of photos to the photos of the item with the same path
+// and no check, since the photos of a full update only carry their paths.
+func (item *Item) KeepPhotoChecks(photos []Photo) {
+	checks := make(map[string]*PhotoCheck, len(photos))
+	for _, photo := range photos {
+		if photo.Check != nil {
+			checks[photo.Path] = photo.Check
+		}
+	}
+
+	for i := range item.Photos {
+		if item.Photos[i].Check == nil {
+			item.Photos[i].Check = checks[item.Photos[i].Path]
+		}
+	}
+}
+
+// KeepPhotoArrangement copies the position, the primary flag and the blob key of photos to
+// the photos of the item with the same path, and orders them by the kept positions with the
+// new ones last, so a full update, whose photos only carry their paths, does not undo the
+// order and the primary photo chosen through the photo endpoints. The photos that already
+// have an ID come from the photo endpoints and are kept as they are.
+func (item *Item) KeepPhotoArrangement(photos []Photo) {
+	for i := range item.Photos {
+		if item.Photos[i].ID != 0 {
+			return
+		}
+	}
+
+	stored := make(map[string]Photo, len(photos))
+	for _, photo := range photos {
+		stored[photo.Path] = photo
+	}
+
+	for i := range item.Photos {
+		photo, ok := stored[item.Photos[i].Path]
+		if !ok {
+			item.Photos[i].Position = len(photos) + i
+			item.Photos[i].IsPrimary = false
+
+			continue
+		}
+
+		item.Photos[i].Position = photo.Position
+		item.Photos[i].IsPrimary = photo.IsPrimary
+		item.Photos[i].BlobKey = photo.BlobKey
+	}
+
+	sort.SliceStable(item.Photos, func(i, j int) bool {
+		return item.Photos[i].Position < item.Photos[j].Position
+	})
+}
+
+// PhotoIndex returns the index of the photo with the given ID, or -1.
+func (item *Item) PhotoIndex(photoID uint) int {
+	for i := range item.Photos {
+		if item.Photos[i].ID == photoID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// ItemPatch holds the fields of a partial update. Nil fields are left unchanged.
+type ItemPatch struct {
+	Code        *string
+	Title       *string
+	Description *string
+	Price       *int
+	Stock       *int
+	ItemType    *string
+	Leader      *bool
+	LeaderLevel *string
+	Photos      *[]Photo
+}
+
+func (patch ItemPatch) Apply(item *Item) {
+	if patch.Code != nil {
+		item.Code = *patch.Code
+	}
+	if patch.Title != nil {
+		item.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		item.Description = *patch.Description
+	}
+	if patch.Price != nil {
+		item.Price = *patch.Price
+	}
+	if patch.Stock != nil {
+		item.Stock = *patch.Stock
+	}
+	if patch.ItemType != nil {
+		item.ItemType = *patch.ItemType
+	}
+	if patch.Leader != nil {
+		item.Leader = *patch.Leader
+	}
+	if patch.LeaderLevel != nil {
+		item.LeaderLevel = *patch.LeaderLevel
+	}
+	if patch.Photos != nil {
+		item.Photos = *patch.Photos
+	}
+}
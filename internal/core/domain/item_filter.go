@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// ItemFilter selects items by their attributes. Zero values do not filter. The price range
+// includes PriceMin and excludes PriceMax.
+type ItemFilter struct {
+	ItemType    string
+	Status      string
+	Leader      *bool
+	LeaderLevel string
+	PriceMin    *int
+	PriceMax    *int
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+}
+
+// Matches reports whether the item is selected by the filter.
+func (filter ItemFilter) Matches(item *Item) bool {
+	switch {
+	case filter.ItemType != "" && item.ItemType != filter.ItemType,
+		filter.Status != "" && item.Status != filter.Status,
+		filter.Leader != nil && item.Leader != *filter.Leader,
+		filter.LeaderLevel != "" && item.LeaderLevel != filter.LeaderLevel,
+		filter.PriceMin != nil && item.Price < *filter.PriceMin,
+		filter.PriceMax != nil && item.Price >= *filter.PriceMax,
+		!filter.UpdatedFrom.IsZero() && item.UpdatedAt.Before(filter.UpdatedFrom),
+		!filter.UpdatedTo.IsZero() && item.UpdatedAt.After(filter.UpdatedTo):
+		return false
+	}
+
+	return true
+}
+
+// Without returns the filter without the selection of a facet, which is how the counts of
+// that facet are computed, so the other values of a selected facet keep their counts.
+func (filter ItemFilter) Without(facet string) ItemFilter {
+	switch facet {
+	case FacetStatus:
+		filter.Status = ""
+	case FacetItemType:
+		filter.ItemType = ""
+	case FacetLeader:
+		filter.Leader = nil
+	case FacetLeaderLevel:
+		filter.LeaderLevel = ""
+	case FacetPrice:
+		filter.PriceMin = nil
+		filter.PriceMax = nil
+	}
+
+	return filter
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"reflect"
+	"time"
+)
+
+const (
+	RevisionOperationCreate = "CREATE"
+	RevisionOperationUpdate = "UPDATE"
+	RevisionOperationDelete = "DELETE"
+	// RevisionOperationBaseline is the first revision of an item created before the revisions
+	// were recorded, with the state it had then, taken at its last update.
+	RevisionOperationBaseline = "BASELINE"
+)
+
+// ItemRevision is a snapshot of an item taken after a mutation. Revisions are numbered
+// with the item version they produced.
+type ItemRevision struct {
+	ItemID    uint
+	Version   uint
+	Operation string
+	Item      Item
+	CreatedAt time.Time
+}
+
+type FieldChange struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+// DiffItems returns the fields whose values differ between two snapshots of an item.
+// Photos are compared by their paths, in order.
+func DiffItems(from, to Item) []FieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"code", from.Code, to.Code},
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"price", from.Price, to.Price},
+		{"stock", from.Stock, to.Stock},
+		{"itemType", from.ItemType, to.ItemType},
+		{"leader", from.Leader, to.Leader},
+		{"leaderLevel", from.LeaderLevel, to.LeaderLevel},
+		{"status", from.Status, to.Status},
+		{"photos", photoPaths(from.Photos), photoPaths(to.Photos)},
+	}
+
+	changes := []FieldChange{}
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.from, field.to) {
+			changes = append(changes, FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+
+	return changes
+}
+
+func photoPaths(photos []Photo) []string {
+	paths := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		paths = append(paths, photo.Path)
+	}
+
+	return paths
+}
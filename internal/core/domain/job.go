@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+const (
+	JobTypeItemImport       = "item_import"
+	JobTypePhotoVariants    = "photo_variants"
+	JobTypePhotoCheck       = "photo_check"
+	JobTypeIdempotencyPurge = "idempotency_purge"
+)
+
+// ErrJobClaimLost is returned by the writes of a runner whose claim on a job was taken over by
+// another runner, after its heartbeat went stale.
+var ErrJobClaimLost = errors.New("job claim lost")
+
+// Job is a unit of work run outside of the request path by the job runner. A failed attempt
+// is queued again at RunAt until MaxAttempts is reached. ClaimToken identifies the claim of
+// the runner currently running the job, so a runner that lost it can not overwrite its state.
+type Job struct {
+	ID              uint
+	Type            string
+	Payload         json.RawMessage
+	Status          string
+	Progress        int
+	ProgressMessage string
+	Attempts        int
+	MaxAttempts     int
+	Error           string
+	Actor           string
+	CancelRequested bool
+	ClaimToken      string
+	RunAt           time.Time
+	HeartbeatAt     *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	StartedAt       *time.Time
+	CompletedAt     *time.Time
+}
+
+// Finished reports whether the job reached a status it never leaves.
+func (job Job) Finished() bool {
+	return job.Status == JobStatusSucceeded || job.Status == JobStatusFailed || job.Status == JobStatusCancelled
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	PhotoCheckOK     = "ok"
+	PhotoCheckBroken = "broken"
+	// PhotoCheckUnreachable is a check whose request got no response, e.g. on a timeout or a
+	// DNS error, which tells nothing about the photo.
+	PhotoCheckUnreachable = "unreachable"
+	// PhotoCheckUnchecked selects in a PhotoCheckFilter the photos not checked yet.
+	PhotoCheckUnchecked = "unchecked"
+)
+
+// PhotoCheck is the result of the last request to the URL of a photo. StatusCode is 0 when no
+// response was received, and Error then tells why.
+type PhotoCheck struct {
+	Status     string
+	StatusCode int
+	Error      string
+	CheckedAt  time.Time
+	// Failures counts the checks in a row that found the photo broken, the unreachable ones
+	// being skipped.
+	Failures int
+	// DeactivatesItem is set when the deactivation of items is enabled and the photo was found
+	// broken enough checks in a row, so as a primary photo it keeps its item inactive, see
+	// Item.SetStatus. An unreachable check keeps the flag of the check before it.
+	DeactivatesItem bool
+}
+
+// Broken reports whether the check found the photo broken.
+func (check *PhotoCheck) Broken() bool {
+	return check != nil && check.Status == PhotoCheckBroken
+}
+
+// Deactivates reports whether the check keeps the item of a primary photo inactive.
+func (check *PhotoCheck) Deactivates() bool {
+	return check != nil && check.DeactivatesItem
+}
+
+// PhotoHostAllowed reports whether host is one of hosts, or a subdomain of an entry starting
+// with "*.", e.g. "*.example.com".
+func PhotoHostAllowed(hosts []string, host string) bool {
+	for _, allowed := range hosts {
+		if suffix := strings.TrimPrefix(allowed, "*"); suffix != allowed {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PhotoCheckFilter selects the photos of a check report by the status of their last check,
+// ordered by ID after AfterID.
+type PhotoCheckFilter struct {
+	Status  string
+	AfterID uint
+	Limit   int
+}
+
+// PhotoCheckReport counts the photos given by URL by the status of their last check, and lists
+// the ones selected by a PhotoCheckFilter. DeactivatedItems counts the items kept inactive by the
+// check of their primary photo.
+type PhotoCheckReport struct {
+	Total            int
+	Unchecked        int
+	OK               int
+	Broken           int
+	Unreachable      int
+	DeactivatedItems int
+	Photos           []Photo
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+)
+
+// PhotoContentTypes are the accepted photo formats with the extension of their blob keys.
+var PhotoContentTypes = map[string]string{ //nolint:gochecknoglobals
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ErrPhotoTooManyPixels is returned by a ports.PhotoProcessor for a photo whose dimensions are
+// above the limit it decodes.
+var ErrPhotoTooManyPixels = errors.New("photo has too many pixels")
+
+// DetectPhotoType returns the content type of a photo from its magic bytes, whatever the
+// client declared, or an empty string when it is not one of PhotoContentTypes.
+func DetectPhotoType(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(content, []byte("GIF87a")), bytes.HasPrefix(content, []byte("GIF89a")):
+		return "image/gif"
+	case len(content) >= 12 && bytes.Equal(content[:4], []byte("RIFF")) && bytes.Equal(content[8:12], []byte("WEBP")):
+		return "image/webp"
+	default:
+		return ""
+	}
+}
+
+// PhotoBlobKey addresses a photo by the SHA-256 of its content, so uploading the same file
+// twice stores it once.
+func PhotoBlobKey(content []byte, contentType string) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:]) + PhotoContentTypes[contentType]
+}
+
+// PhotoVariant is a resized copy of the uploaded photos that fits in a square of Size pixels.
+type PhotoVariant struct {
+	Name string
+	Size int
+}
+
+// PhotoVariants are generated for every uploaded photo, from the smallest to the largest.
+var PhotoVariants = []PhotoVariant{ //nolint:gochecknoglobals
+	{Name: "thumbnail", Size: 150},
+	{Name: "medium", Size: 600},
+	{Name: "large", Size: 1200},
+}
+
+// PhotoVariantKey stores a variant next to its original, e.g. "<sha256>-thumbnail.jpg".
+func PhotoVariantKey(blobKey string, variant string, contentType string) string {
+	return strings.TrimSuffix(blobKey, path.Ext(blobKey)) + "-" + variant + PhotoContentTypes[contentType]
+}
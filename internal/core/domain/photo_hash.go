@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	// PhotoHashChunks is how many parts of 16 bits a photo hash is split into to find similar
+	// hashes: two hashes at most d bits apart share a part at most d/PhotoHashChunks bits apart.
+	PhotoHashChunks = 4
+	// MaxPhotoHashDistance bounds the distance of a search, as the parts searched grow with it.
+	MaxPhotoHashDistance = 12
+	// DefaultPhotoHashDistance finds the resized and recompressed copies of a photo.
+	DefaultPhotoHashDistance = 10
+)
+
+// PhotoHashDistance is the number of bits two photo hashes differ in.
+func PhotoHashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// PhotoHashChunk returns the part i of a photo hash, counting from the lowest bits.
+func PhotoHashChunk(hash uint64, i int) uint16 {
+	return uint16(hash >> (16 * i))
+}
+
+// PhotoHashChunkNeighbours returns every part at most radius bits from chunk, chunk included.
+func PhotoHashChunkNeighbours(chunk uint16, radius int) []uint16 {
+	neighbours := []uint16{chunk}
+
+	var flip func(value uint16, from int, left int)
+	flip = func(value uint16, from int, left int) {
+		for bit := from; bit < 16 && left > 0; bit++ {
+			flipped := value ^ 1<<bit
+			neighbours = append(neighbours, flipped)
+			flip(flipped, bit+1, left-1)
+		}
+	}
+
+	flip(chunk, 0, radius)
+
+	return neighbours
+}
+
+// PhotoMatch is a photo of another item similar to the photo MatchedPhotoID of the item.
+type PhotoMatch struct {
+	PhotoID        uint
+	MatchedPhotoID uint
+	Distance       int
+}
+
+// DuplicateItem is an item with photos similar to the photos of another item. Distance is the
+// smallest distance of its matches.
+type DuplicateItem struct {
+	ItemID   uint
+	Distance int
+	Matches  []PhotoMatch
+}
+
+// SortDuplicateItems orders the items by distance, the closest first, and then by ID.
+func SortDuplicateItems(items []DuplicateItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Distance != items[j].Distance {
+			return items[i].Distance < items[j].Distance
+		}
+
+		return items[i].ItemID < items[j].ItemID
+	})
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPhotoHashChunk(t *testing.T) {
+	const hash = 0x1111_2222_3333_4444
+
+	for i, want := range []uint16{0x4444, 0x3333, 0x2222, 0x1111} {
+		if got := PhotoHashChunk(hash, i); got != want {
+			t.Errorf("PhotoHashChunk(%d) = %#x, want %#x", i, got, want)
+		}
+	}
+}
+
+func TestPhotoHashChunkNeighbours(t *testing.T) {
+	// 1 + 16 + 16*15/2 parts at most 2 bits away.
+	neighbours := PhotoHashChunkNeighbours(0xF0F0, 2)
+	if len(neighbours) != 137 {
+		t.Fatalf("got %d neighbours, want 137", len(neighbours))
+	}
+
+	seen := make(map[uint16]bool, len(neighbours))
+	for _, neighbour := range neighbours {
+		if seen[neighbour] {
+			t.Errorf("neighbour %#x listed twice", neighbour)
+		}
+
+		if distance := PhotoHashDistance(uint64(neighbour), 0xF0F0); distance > 2 {
+			t.Errorf("neighbour %#x is %d bits away", neighbour, distance)
+		}
+
+		seen[neighbour] = true
+	}
+
+	if got := PhotoHashChunkNeighbours(0xF0F0, 0); len(got) != 1 || got[0] != 0xF0F0 {
+		t.Errorf("PhotoHashChunkNeighbours(radius 0) = %v, want the part alone", got)
+	}
+}
+
+// TestPhotoHashChunksFindEverySimilarHash checks the property the search of similar photos
+// relies on: a hash at most MaxPhotoHashDistance bits away shares a part with the searched
+// hash, give or take distance/PhotoHashChunks bits.
+func TestPhotoHashChunksFindEverySimilarHash(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 2000; n++ {
+		hash := random.Uint64()
+		distance := random.Intn(MaxPhotoHashDistance + 1)
+
+		similar := hash
+		for _, bit := range random.Perm(64)[:distance] {
+			similar ^= 1 << bit
+		}
+
+		if !sharesPart(hash, similar, distance/PhotoHashChunks) {
+			t.Fatalf("%#x and %#x are %d bits apart without a part in common", hash, similar, distance)
+		}
+	}
+}
+
+func sharesPart(hash uint64, similar uint64, radius int) bool {
+	for i := 0; i < PhotoHashChunks; i++ {
+		for _, neighbour := range PhotoHashChunkNeighbours(PhotoHashChunk(hash, i), radius) {
+			if neighbour == PhotoHashChunk(similar, i) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func TestSortDuplicateItems(t *testing.T) {
+	items := []DuplicateItem{{ItemID: 3, Distance: 4}, {ItemID: 2, Distance: 1}, {ItemID: 1, Distance: 4}}
+	SortDuplicateItems(items)
+
+	for i, want := range []uint{2, 1, 3} {
+		if items[i].ItemID != want {
+			t.Fatalf("got %v, want the items 2, 1 and 3", items)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// RateLimit describes a token bucket: Burst tokens of capacity refilled at Rate tokens per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
@@ -0,0 +1,30 @@
+package domain
+
+// SearchQuery is a full-text search of items. Text is free text matched against the code,
+// title and description of the items; without it the search lists the items matching the
+// filter by ID. PriceRanges are the buckets of the price facet.
+type SearchQuery struct {
+	Text        string
+	Filter      ItemFilter
+	PriceRanges []PriceRange
+	Offset      int
+	Limit       int
+}
+
+// SearchHit is an item matching a search. Highlights has, for each matched field, its text
+// with the matched words wrapped in <em>. Item is loaded by the search service, indexes only
+// return its ID.
+type SearchHit struct {
+	ItemID     uint
+	Score      float64
+	Highlights map[string]string
+	Item       *Item
+}
+
+// SearchResult is a page of hits ordered by relevance, the total of matching items, and the
+// counts of the values of every facet, see ItemFilter.Without.
+type SearchResult struct {
+	Total  int
+	Hits   []SearchHit
+	Facets map[string][]FacetCount
+}
@@ -0,0 +1,16 @@
+package domain
+
+const (
+	SuggestionKindTitle = "title"
+	SuggestionKindCode  = "code"
+)
+
+// Suggestion completes what was typed with the title or the code of an item. Fuzzy tells
+// that it matched with one typo in what was typed.
+type Suggestion struct {
+	Text   string
+	Kind   string
+	ItemID uint
+	Score  float64
+	Fuzzy  bool
+}
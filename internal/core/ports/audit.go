@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+// AuditRepository reads the audit log. Its entries are appended by the item writes they audit,
+// see WithAudit, and never updated.
+//
+//go:generate mockgen -source=./audit.go -destination=../test/mocks/audit_repository_mock.go -package=mocks
+type AuditRepository interface {
+	FindAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+type auditKey struct{}
+
+// WithAudit returns a context whose item writes are audited. The ItemRepository completes the
+// entry with the item and its states, and appends it in the transaction of the write, so a write
+// is never stored without its entry.
+func WithAudit(ctx context.Context, entry domain.AuditEntry) context.Context {
+	return context.WithValue(ctx, auditKey{}, entry)
+}
+
+// AuditFromContext returns the audit entry of the writes made with ctx, if they are audited.
+func AuditFromContext(ctx context.Context) (domain.AuditEntry, bool) {
+	entry, ok := ctx.Value(auditKey{}).(domain.AuditEntry)
+
+	return entry, ok
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore keeps binary content, e.g. uploaded photos, by key.
+//
+//go:generate mockgen -source=./blob.go -destination=../test/mocks/blob_store_mock.go -package=mocks
+type BlobStore interface {
+	// Put stores size bytes of content under key, replacing any previous content.
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Get returns the content of key with its content type, or a domain.ResourceNotFoundError.
+	Get(ctx context.Context, key string) (io.ReadCloser, string, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// URL is the stable address clients download the content of key from.
+	URL(key string) string
+}
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+//go:generate mockgen -source=./idempotency.go -destination=../test/mocks/idempotency_store_mock.go -package=mocks
+type IdempotencyStore interface {
+	// Reserve atomically claims the key for the caller and returns the owner token of the claim,
+	// or returns the record already stored under the key if it has not expired. A record in
+	// progress whose lease has expired is taken over by the caller with a new owner token. The
+	// claim holds for lease, see Renew.
+	Reserve(ctx context.Context, key, fingerprint string, ttl, lease time.Duration) (string, *domain.IdempotencyRecord, error)
+	// Renew extends the lease of a key reserved by owner and not completed yet.
+	Renew(ctx context.Context, key, owner string, lease time.Duration) error
+	// Complete stores the response of a key reserved by record.Owner. It fails once the claim was
+	// taken over, so a request that outlived its lease can not overwrite the response of another.
+	Complete(ctx context.Context, record domain.IdempotencyRecord) error
+	// Release drops a key reserved by owner and not completed yet, and does nothing otherwise.
+	Release(ctx context.Context, key, owner string) error
+	// PurgeExpired deletes up to limit expired records and returns how many it deleted.
+	PurgeExpired(ctx context.Context, limit int) (int, error)
+}
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+//go:generate mockgen -source=./imports.go -destination=../test/mocks/import_repository_mock.go -package=mocks
+type ImportRepository interface {
+	SaveImport(ctx context.Context, itemImport *domain.Import) error
+	// UpdateImport stores the status and the counters of the import.
+	UpdateImport(ctx context.Context, itemImport *domain.Import) error
+	GetImport(ctx context.Context, id uint) (*domain.Import, error)
+	// SaveImportRows stores the parsed rows of an import until its job processes them.
+	SaveImportRows(ctx context.Context, importID uint, rows []domain.ImportRow) error
+	GetImportRows(ctx context.Context, importID uint) ([]domain.ImportRow, error)
+	// DeleteImportRows removes the rows of an import once its job is done with them.
+	DeleteImportRows(ctx context.Context, importID uint) error
+	SaveImportErrors(ctx context.Context, importID uint, importErrors []domain.ImportError) error
+	GetImportErrors(ctx context.Context, importID uint) ([]domain.ImportError, error)
+}
@@ -0,0 +1,47 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+//go:generate mockgen -source=./jobs.go -destination=../test/mocks/job_repository_mock.go -package=mocks
+type JobRepository interface {
+	SaveJob(ctx context.Context, job *domain.Job) error
+	GetJob(ctx context.Context, id uint) (*domain.Job, error)
+	// CountJobs counts the jobs of the given type in any of the given statuses.
+	CountJobs(ctx context.Context, jobType string, statuses []string) (int, error)
+	// ClaimJob marks as running the next job due at now, either queued or running with a heartbeat
+	// older than staleBefore, and returns it with a new claim token, or nil when there is none.
+	ClaimJob(ctx context.Context, now, staleBefore time.Time) (*domain.Job, error)
+	// UpdateJob stores the state of a job, only while the claim token of the job is still the current
+	// one, and returns domain.ErrJobClaimLost otherwise.
+	UpdateJob(ctx context.Context, job *domain.Job) error
+	// HeartbeatJob stores the progress of a running job and reports whether its cancellation was
+	// requested. It returns domain.ErrJobClaimLost as UpdateJob does.
+	HeartbeatJob(ctx context.Context, job *domain.Job, at time.Time) (bool, error)
+	// CancelJob cancels a queued job, or requests the cancellation of a running one, and returns the job.
+	CancelJob(ctx context.Context, id uint) (*domain.Job, error)
+}
+
+// JobProgress reports the progress of a running job as a percentage and a short message.
+type JobProgress func(progress int, message string)
+
+// JobHandler runs the jobs of one type. The context is cancelled when the job is cancelled or
+// when the runner stops before the job finishes, and a returned error retries the job until
+// its attempts are exhausted.
+type JobHandler interface {
+	JobType() string
+	HandleJob(ctx context.Context, job *domain.Job, progress JobProgress) error
+}
+
+// JobRunner claims and runs jobs with a pool of workers.
+type JobRunner interface {
+	Register(handler JobHandler) error
+	Start()
+	// Stop stops claiming jobs and waits for the running ones until ctx is done, when they are
+	// cancelled and queued again.
+	Stop(ctx context.Context) error
+}
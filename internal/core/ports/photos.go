@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+//go:generate mockgen -source=./photos.go -destination=../test/mocks/photo_repository_mock.go -package=mocks
+type PhotoRepository interface {
+	// SetPhotoVariants records the variant URLs, by variant name, of every photo of the blob.
+	SetPhotoVariants(ctx context.Context, blobKey string, variants map[string]string) error
+	// FindPhotosToCheck returns at most limit photos given by URL, ordered by ID after afterID,
+	// with their last check. The uploaded photos are served by the blob store and not checked.
+	FindPhotosToCheck(ctx context.Context, afterID uint, limit int) ([]domain.Photo, error)
+	// SetPhotoCheck records the check of the photos with the given IDs.
+	SetPhotoCheck(ctx context.Context, photoIDs []uint, check domain.PhotoCheck) error
+	GetPhotoCheckReport(ctx context.Context, filter domain.PhotoCheckFilter) (*domain.PhotoCheckReport, error)
+	// SetPhotoHash records the perceptual hash of every photo of the blob.
+	SetPhotoHash(ctx context.Context, blobKey string, hash uint64) error
+	// FindSimilarPhotos returns the photos of other items than itemID whose hash is at most
+	// maxDistance from hash, with their hash.
+	FindSimilarPhotos(ctx context.Context, itemID uint, hash uint64, maxDistance int) ([]domain.Photo, error)
+}
+
+// PhotoURLChecker requests the URLs of photos to find the broken ones.
+type PhotoURLChecker interface {
+	// CheckURLs returns the check of every URL, by URL.
+	CheckURLs(ctx context.Context, urls []string) map[string]domain.PhotoCheck
+}
+
+// PhotoProcessor works on the content of the photo formats of domain.PhotoContentTypes. Its
+// methods return domain.ErrPhotoTooManyPixels for a photo too large to decode.
+type PhotoProcessor interface {
+	// StripMetadata returns the photo without its EXIF metadata, applying the orientation it
+	// recorded to the pixels so the photo is still displayed upright.
+	StripMetadata(content []byte, contentType string) ([]byte, error)
+	// CanResize reports whether Resize and Hash decode photos of the content type.
+	CanResize(contentType string) bool
+	// Resize scales the photo down to fit in a square of size pixels, never up, and returns
+	// it with its content type.
+	Resize(content []byte, contentType string, size int) ([]byte, string, error)
+	// Hash returns a perceptual hash of the photo, whose distance to the hash of a similar
+	// photo, see domain.PhotoHashDistance, is small.
+	Hash(content []byte, contentType string) (uint64, error)
+}
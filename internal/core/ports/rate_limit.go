@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+//go:generate mockgen -source=./rate_limit.go -destination=../test/mocks/rate_limit_store_mock.go -package=mocks
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error)
+}
@@ -9,6 +9,21 @@ import (
 
 //go:generate mockgen -source=./repositories.go -destination=../test/mocks/item_repository_mock.go -package=mocks
 type ItemRepository interface {
+	// The writes made with a context of WithAudit append its audit entry in their own transaction.
 	SaveItem(ctx context.Context, a *domain.Item) error
+	// SaveItems stores every item in a single transaction, so either all of them are saved or none.
+	SaveItems(ctx context.Context, items []*domain.Item) error
 	GetItemByID(ctx context.Context, id uint) (*domain.Item, error)
+	// GetItemsByIDs reads a batch of items, ordered by ID, leaving out the ones that do not exist.
+	GetItemsByIDs(ctx context.Context, ids []uint) ([]*domain.Item, error)
+	GetItemByCode(ctx context.Context, code string) (*domain.Item, error)
+	// StreamItems calls fn with every item matching the filter, ordered by ID, without loading
+	// them all in memory. An error returned by fn stops the stream and is returned.
+	StreamItems(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error
+	// UpdateItem stores the item only if its current version is item.Version, or unconditionally
+	// when item.Version is 0, and returns a domain.ConflictError otherwise.
+	UpdateItem(ctx context.Context, item *domain.Item) error
+	DeleteItem(ctx context.Context, id uint, version uint) error
+	// CountItemsByStatus counts the items by their status.
+	CountItemsByStatus(ctx context.Context) (map[string]int, error)
 }
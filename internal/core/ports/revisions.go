@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+//go:generate mockgen -source=./revisions.go -destination=../test/mocks/item_revision_repository_mock.go -package=mocks
+type ItemRevisionRepository interface {
+	GetItemRevisions(ctx context.Context, itemID uint) ([]domain.ItemRevision, error)
+	GetItemRevision(ctx context.Context, itemID uint, version uint) (*domain.ItemRevision, error)
+	// GetItemRevisionAt returns the latest revision of the item recorded at or before the given time.
+	GetItemRevisionAt(ctx context.Context, itemID uint, at time.Time) (*domain.ItemRevision, error)
+}
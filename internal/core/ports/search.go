@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+// ItemIndex is a read model of the items kept in sync with every write of an item.
+//
+//go:generate mockgen -source=./search.go -destination=../test/mocks/item_search_index_mock.go -package=mocks
+type ItemIndex interface {
+	// IndexItem adds an item to the index or replaces its previous version.
+	IndexItem(ctx context.Context, item *domain.Item) error
+	RemoveItem(ctx context.Context, id uint) error
+}
+
+type ItemSearchIndex interface {
+	ItemIndex
+	Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error)
+}
+
+type ItemSuggester interface {
+	ItemIndex
+	// Suggest returns at most limit suggestions for what was typed, best first.
+	Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error)
+	// Rebuild replaces every item of the suggester with the items passed to fn by stream,
+	// while readers keep the previous items until it finishes.
+	Rebuild(ctx context.Context, stream func(fn func(*domain.Item) error) error) error
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/osalomon89/test-crud-api/internal/core/domain"
 )
@@ -9,5 +10,99 @@ import (
 //go:generate mockgen -source=./services.go -destination=../test/mocks/item_service_mock.go -package=mocks
 type ItemService interface {
 	CreateItem(ctx context.Context, item domain.Item) (*domain.Item, error)
+	// CreateItems creates a batch of items in one of the domain.BulkMode modes and returns the
+	// result of every item. An atomic batch with any failure returns an error and creates nothing.
+	CreateItems(ctx context.Context, items []domain.Item, mode string) ([]domain.BulkItemResult, error)
 	GetItemByID(ctx context.Context, itemID uint) (*domain.Item, error)
+	// ExportItems streams the items matching the filter to fn, see ItemRepository.StreamItems.
+	ExportItems(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error
+	UpdateItem(ctx context.Context, item domain.Item) (*domain.Item, error)
+	PatchItem(ctx context.Context, itemID uint, version uint, patch domain.ItemPatch) (*domain.Item, error)
+	DeleteItem(ctx context.Context, itemID uint, version uint) error
+	GetItemAsOf(ctx context.Context, itemID uint, asOf time.Time) (*domain.Item, error)
+	GetItemRevisions(ctx context.Context, itemID uint) ([]domain.ItemRevision, error)
+	DiffItemRevisions(ctx context.Context, itemID uint, fromVersion, toVersion uint) ([]domain.FieldChange, error)
+	RevertItem(ctx context.Context, itemID uint, revisionVersion uint, version uint) (*domain.Item, error)
+}
+
+type AuditService interface {
+	FindAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	// VerifyAuditChain walks the whole chain and returns the ID of the first entry that does not match, or 0.
+	VerifyAuditChain(ctx context.Context) (uint, error)
+}
+
+// ImportService is also the JobHandler of the import jobs it enqueues.
+type ImportService interface {
+	JobHandler
+	// StartImport stores the import and processes its rows in the background.
+	StartImport(ctx context.Context, itemImport domain.Import, rows []domain.ImportRow) (*domain.Import, error)
+	// RunImport stores the import and processes its rows before returning.
+	RunImport(ctx context.Context, itemImport domain.Import, rows []domain.ImportRow) (*domain.Import, error)
+	GetImport(ctx context.Context, id uint) (*domain.Import, error)
+	GetImportErrors(ctx context.Context, id uint) ([]domain.ImportError, error)
+}
+
+type JobService interface {
+	// EnqueueJob queues a job of the given type with the JSON of payload, to be attempted at most maxAttempts times.
+	EnqueueJob(ctx context.Context, jobType string, payload interface{}, maxAttempts int) (*domain.Job, error)
+	// ScheduleJob queues a job as EnqueueJob does, to be run at runAt.
+	ScheduleJob(ctx context.Context, jobType string, payload interface{}, maxAttempts int,
+		runAt time.Time) (*domain.Job, error)
+	// CountJobs counts the jobs of the given type in any of the given statuses.
+	CountJobs(ctx context.Context, jobType string, statuses ...string) (int, error)
+	GetJob(ctx context.Context, id uint) (*domain.Job, error)
+	CancelJob(ctx context.Context, id uint) (*domain.Job, error)
+}
+
+type SearchService interface {
+	SearchItems(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error)
+	// ReindexItems adds every stored item to the search index.
+	ReindexItems(ctx context.Context) error
+}
+
+type SuggestService interface {
+	SuggestItems(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error)
+	RebuildSuggestions(ctx context.Context) error
+	// RefreshSuggestions rebuilds the suggestions every interval until ctx is done. The suggester
+	// lives in the process, so every instance refreshes its own.
+	RefreshSuggestions(ctx context.Context, interval time.Duration)
+}
+
+// PhotoService changes the photos of an item through ItemService.UpdateItem, so every change
+// is a new version of the item. A version of 0 accepts any current version. It is also the
+// JobHandler of the jobs generating the variants and hashes of the uploaded photos.
+type PhotoService interface {
+	JobHandler
+	GetPhotos(ctx context.Context, itemID uint) ([]domain.Photo, error)
+	// AddPhoto inserts the photo at position, or appends it when position is nil.
+	AddPhoto(ctx context.Context, itemID uint, version uint, photo domain.Photo, position *int) (*domain.Item, error)
+	// UploadPhoto stores content in the blob store and adds it as AddPhoto does, with the blob URL as path.
+	UploadPhoto(ctx context.Context, itemID uint, version uint, content []byte, photo domain.Photo,
+		position *int) (*domain.Item, error)
+	DeletePhoto(ctx context.Context, itemID uint, version uint, photoID uint) (*domain.Item, error)
+	// ReorderPhotos sorts the photos as photoIDs, which must list every photo of the item once.
+	// A primaryID other than 0 also makes that photo the primary one.
+	ReorderPhotos(ctx context.Context, itemID uint, version uint, photoIDs []uint, primaryID uint) (*domain.Item, error)
+	// FindDuplicates returns at most limit items with uploaded photos whose hash is at most
+	// maxDistance from the hash of an uploaded photo of the item, the closest first.
+	FindDuplicates(ctx context.Context, itemID uint, maxDistance int, limit int) ([]domain.DuplicateItem, error)
+}
+
+// IdempotencyPurgeService deletes the expired idempotency records in jobs, of which it is the
+// JobHandler. Every purge job schedules the next one, so the purges run periodically.
+type IdempotencyPurgeService interface {
+	JobHandler
+	// SchedulePurges enqueues a purge job unless one is already queued or running.
+	SchedulePurges(ctx context.Context) error
+}
+
+// PhotoCheckService checks the URLs of the photos given by URL in jobs, of which it is the
+// JobHandler. Every check job schedules the next one, so the checks run periodically.
+type PhotoCheckService interface {
+	JobHandler
+	// ScheduleChecks enqueues a check job unless one is already queued or running.
+	ScheduleChecks(ctx context.Context) error
+	// StartCheck enqueues a check job to run at once.
+	StartCheck(ctx context.Context) (*domain.Job, error)
+	GetReport(ctx context.Context, filter domain.PhotoCheckFilter) (*domain.PhotoCheckReport, error)
 }
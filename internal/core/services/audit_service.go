@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const auditVerifyPageSize = 500
+
+type auditService struct {
+	auditRepository ports.AuditRepository
+}
+
+func NewAuditService(auditRepository ports.AuditRepository) (ports.AuditService, error) {
+	if auditRepository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	return &auditService{auditRepository: auditRepository}, nil
+}
+
+func (svc *auditService) FindAuditEntries(ctx context.Context,
+	filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering AuditService. FindAuditEntries()")
+
+	entries, err := svc.auditRepository.FindAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (svc *auditService) VerifyAuditChain(ctx context.Context) (uint, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering AuditService. VerifyAuditChain()")
+
+	prevHash := ""
+	filter := domain.AuditFilter{Limit: auditVerifyPageSize}
+
+	for {
+		entries, err := svc.auditRepository.FindAuditEntries(ctx, filter)
+		if err != nil {
+			return 0, fmt.Errorf("error in repository: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.PrevHash != prevHash || entry.ComputeHash() != entry.Hash {
+				return entry.ID, nil
+			}
+
+			prevHash = entry.Hash
+			filter.AfterID = entry.ID
+		}
+
+		if len(entries) < auditVerifyPageSize {
+			return 0, nil
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const anonymousActor = "anonymous"
+
+// auditedItemService decorates an ItemService so every write is audited. The entry is appended by
+// the repository in the transaction of the write, see ports.WithAudit, so a write whose entry can
+// not be appended fails. Reads go straight to the decorated service.
+type auditedItemService struct {
+	ports.ItemService
+}
+
+func NewAuditedItemService(itemService ports.ItemService) (ports.ItemService, error) {
+	if itemService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	return &auditedItemService{ItemService: itemService}, nil
+}
+
+func (svc *auditedItemService) CreateItem(ctx context.Context, item domain.Item) (*domain.Item, error) {
+	return svc.ItemService.CreateItem(audit(ctx, domain.AuditOperationCreate), item)
+}
+
+func (svc *auditedItemService) CreateItems(ctx context.Context, items []domain.Item,
+	mode string) ([]domain.BulkItemResult, error) {
+	return svc.ItemService.CreateItems(audit(ctx, domain.AuditOperationCreate), items, mode)
+}
+
+func (svc *auditedItemService) UpdateItem(ctx context.Context, item domain.Item) (*domain.Item, error) {
+	return svc.ItemService.UpdateItem(audit(ctx, domain.AuditOperationUpdate), item)
+}
+
+func (svc *auditedItemService) PatchItem(ctx context.Context, itemID uint, version uint,
+	patch domain.ItemPatch) (*domain.Item, error) {
+	return svc.ItemService.PatchItem(audit(ctx, domain.AuditOperationPatch), itemID, version, patch)
+}
+
+func (svc *auditedItemService) DeleteItem(ctx context.Context, itemID uint, version uint) error {
+	return svc.ItemService.DeleteItem(audit(ctx, domain.AuditOperationDelete), itemID, version)
+}
+
+func (svc *auditedItemService) RevertItem(ctx context.Context, itemID uint, revisionVersion uint,
+	version uint) (*domain.Item, error) {
+	return svc.ItemService.RevertItem(audit(ctx, domain.AuditOperationRevert), itemID, revisionVersion, version)
+}
+
+// audit returns a context whose writes are recorded as the given operation. The actor is the
+// authenticated caller and the client IP the one resolved by marketcontext.ClientIP, neither of
+// which the client can choose.
+func audit(ctx context.Context, operation string) context.Context {
+	caller := marketcontext.GetCaller(ctx)
+
+	actor := caller.ID
+	if actor == "" {
+		actor = anonymousActor
+	}
+
+	return ports.WithAudit(ctx, domain.AuditEntry{
+		Actor:      actor,
+		RequestID:  marketcontext.Logger(ctx).GetRequestID(),
+		ClientIP:   caller.IP,
+		Operation:  operation,
+		EntityType: domain.AuditEntityItem,
+	})
+}
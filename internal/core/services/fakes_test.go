@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+// itemStore is an in-memory ItemRepository that records the revisions of its writes as the
+// MySQL one does, and is also their ItemRevisionRepository.
+type itemStore struct {
+	mutex     sync.Mutex
+	items     map[uint]domain.Item
+	revisions map[uint][]domain.ItemRevision
+	lastID    uint
+	lastPhoto uint
+}
+
+func newItemStore() *itemStore {
+	return &itemStore{
+		items:     map[uint]domain.Item{},
+		revisions: map[uint][]domain.ItemRevision{},
+	}
+}
+
+// put stores an item as it is, without a revision, like the items written before the revisions
+// were recorded.
+func (store *itemStore) put(item domain.Item) *domain.Item {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.lastID++
+	item.ID = store.lastID
+	item.Version = 1
+	store.items[item.ID] = store.stored(item)
+
+	return &item
+}
+
+func (store *itemStore) SaveItem(ctx context.Context, item *domain.Item) error {
+	return store.SaveItems(ctx, []*domain.Item{item})
+}
+
+func (store *itemStore) SaveItems(ctx context.Context, items []*domain.Item) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, item := range items {
+		for _, stored := range store.items {
+			if stored.Code == item.Code {
+				return domain.ItemError{Code: domain.ErrCodeItemCodeDuplicated, Message: "The item code must be unique"}
+			}
+		}
+	}
+
+	for _, item := range items {
+		store.lastID++
+		item.ID = store.lastID
+		item.Version = 1
+		item.CreatedAt = time.Now()
+		item.UpdatedAt = item.CreatedAt
+		store.write(*item, domain.RevisionOperationCreate)
+	}
+
+	return nil
+}
+
+func (store *itemStore) GetItemByID(ctx context.Context, id uint) (*domain.Item, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	item, ok := store.items[id]
+	if !ok {
+		return nil, domain.ResourceNotFoundError{Message: "Item not found"}
+	}
+
+	item = copyItem(item)
+
+	return &item, nil
+}
+
+func (store *itemStore) GetItemsByIDs(ctx context.Context, ids []uint) ([]*domain.Item, error) {
+	var items []*domain.Item
+
+	for _, id := range ids {
+		if item, err := store.GetItemByID(ctx, id); err == nil {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	return items, nil
+}
+
+func (store *itemStore) GetItemByCode(ctx context.Context, code string) (*domain.Item, error) {
+	store.mutex.Lock()
+
+	for id, item := range store.items {
+		if item.Code == code {
+			store.mutex.Unlock()
+			return store.GetItemByID(ctx, id)
+		}
+	}
+
+	store.mutex.Unlock()
+
+	return nil, domain.ResourceNotFoundError{Message: "Item not found"}
+}
+
+func (store *itemStore) StreamItems(ctx context.Context, filter domain.ItemFilter,
+	fn func(*domain.Item) error) error {
+	store.mutex.Lock()
+	ids := make([]uint, 0, len(store.items))
+
+	for id := range store.items {
+		ids = append(ids, id)
+	}
+	store.mutex.Unlock()
+
+	items, _ := store.GetItemsByIDs(ctx, ids)
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (store *itemStore) UpdateItem(ctx context.Context, item *domain.Item) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored, ok := store.items[item.ID]
+	if !ok {
+		return domain.ResourceNotFoundError{Message: "Item not found"}
+	}
+
+	if item.Version != 0 && item.Version != stored.Version {
+		return domain.ConflictError{Message: "The item has been modified by another request"}
+	}
+
+	updated := copyItem(*item)
+	updated.Version = stored.Version + 1
+	updated.CreatedAt = stored.CreatedAt
+	updated.UpdatedAt = time.Now()
+	store.write(updated, domain.RevisionOperationUpdate)
+	item.UpdatedAt = updated.UpdatedAt
+
+	return nil
+}
+
+func (store *itemStore) DeleteItem(ctx context.Context, id uint, version uint) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored, ok := store.items[id]
+	if !ok {
+		return domain.ResourceNotFoundError{Message: "Item not found"}
+	}
+
+	if version != 0 && version != stored.Version {
+		return domain.ConflictError{Message: "The item has been modified by another request"}
+	}
+
+	stored.Version++
+	store.revisions[id] = append(store.revisions[id], domain.ItemRevision{
+		ItemID: id, Version: stored.Version, Operation: domain.RevisionOperationDelete,
+		Item: stored, CreatedAt: time.Now(),
+	})
+	delete(store.items, id)
+
+	return nil
+}
+
+func (store *itemStore) CountItemsByStatus(ctx context.Context) (map[string]int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	counts := map[string]int{}
+	for _, item := range store.items {
+		counts[item.Status]++
+	}
+
+	return counts, nil
+}
+
+func (store *itemStore) GetItemRevisions(ctx context.Context, itemID uint) ([]domain.ItemRevision, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	revisions := store.revisions[itemID]
+	if len(revisions) == 0 {
+		return nil, domain.ResourceNotFoundError{Message: "Item not found"}
+	}
+
+	return append([]domain.ItemRevision(nil), revisions...), nil
+}
+
+func (store *itemStore) GetItemRevision(ctx context.Context, itemID uint,
+	version uint) (*domain.ItemRevision, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, revision := range store.revisions[itemID] {
+		if revision.Version == version {
+			return &revision, nil
+		}
+	}
+
+	return nil, domain.ResourceNotFoundError{Message: "Item revision not found"}
+}
+
+func (store *itemStore) GetItemRevisionAt(ctx context.Context, itemID uint,
+	at time.Time) (*domain.ItemRevision, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	revisions := store.revisions[itemID]
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if !revisions[i].CreatedAt.After(at) {
+			revision := revisions[i]
+			return &revision, nil
+		}
+	}
+
+	return nil, domain.ResourceNotFoundError{Message: "Item not found"}
+}
+
+// write stores an item and its revision. The mutex must be held.
+func (store *itemStore) write(item domain.Item, operation string) {
+	item = store.stored(item)
+	store.items[item.ID] = item
+	store.revisions[item.ID] = append(store.revisions[item.ID], domain.ItemRevision{
+		ItemID: item.ID, Version: item.Version, Operation: operation, Item: copyItem(item),
+		CreatedAt: item.UpdatedAt,
+	})
+}
+
+// stored gives IDs to the new photos of an item. The mutex must be held.
+func (store *itemStore) stored(item domain.Item) domain.Item {
+	item = copyItem(item)
+
+	for i := range item.Photos {
+		if item.Photos[i].ID == 0 {
+			store.lastPhoto++
+			item.Photos[i].ID = store.lastPhoto
+		}
+
+		item.Photos[i].ItemID = item.ID
+	}
+
+	return item
+}
+
+func copyItem(item domain.Item) domain.Item {
+	item.Photos = append([]domain.Photo(nil), item.Photos...)
+
+	return item
+}
+
+// jobQueue is a JobService that records the jobs it is given instead of running them.
+type jobQueue struct {
+	mutex sync.Mutex
+	jobs  []domain.Job
+}
+
+func (queue *jobQueue) EnqueueJob(ctx context.Context, jobType string, payload interface{},
+	maxAttempts int) (*domain.Job, error) {
+	return queue.ScheduleJob(ctx, jobType, payload, maxAttempts, time.Now())
+}
+
+func (queue *jobQueue) ScheduleJob(ctx context.Context, jobType string, payload interface{}, maxAttempts int,
+	runAt time.Time) (*domain.Job, error) {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	job := domain.Job{
+		ID:          uint(len(queue.jobs) + 1),
+		Type:        jobType,
+		Status:      domain.JobStatusQueued,
+		Payload:     content,
+		MaxAttempts: maxAttempts,
+		RunAt:       runAt,
+	}
+	queue.jobs = append(queue.jobs, job)
+
+	return &job, nil
+}
+
+func (queue *jobQueue) CountJobs(ctx context.Context, jobType string, statuses ...string) (int, error) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	count := 0
+	for _, job := range queue.jobs {
+		if job.Type != jobType {
+			continue
+		}
+
+		for _, status := range statuses {
+			if job.Status == status {
+				count++
+				break
+			}
+		}
+	}
+
+	return count, nil
+}
+
+func (queue *jobQueue) GetJob(ctx context.Context, id uint) (*domain.Job, error) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	if id == 0 || int(id) > len(queue.jobs) {
+		return nil, domain.ResourceNotFoundError{Message: "Job not found"}
+	}
+
+	job := queue.jobs[id-1]
+
+	return &job, nil
+}
+
+func (queue *jobQueue) CancelJob(ctx context.Context, id uint) (*domain.Job, error) {
+	return queue.GetJob(ctx, id)
+}
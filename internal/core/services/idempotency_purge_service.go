@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	// idempotencyPurgeBatch is how many expired records are deleted at a time, so a purge does not
+	// hold a long lock on the table.
+	idempotencyPurgeBatch    = 1000
+	idempotencyPurgeAttempts = 3
+)
+
+type idempotencyPurgeService struct {
+	store      ports.IdempotencyStore
+	jobService ports.JobService
+	interval   time.Duration
+}
+
+// NewIdempotencyPurgeService runs a purge every interval. Expired records are also replaced
+// when their key is used again, but the keys never used again are only deleted by the purges.
+func NewIdempotencyPurgeService(store ports.IdempotencyStore, jobService ports.JobService,
+	interval time.Duration) (ports.IdempotencyPurgeService, error) {
+	if store == nil {
+		return nil, fmt.Errorf("idempotency store cannot be nil")
+	}
+
+	if jobService == nil {
+		return nil, fmt.Errorf("job service cannot be nil")
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("idempotency purge interval must be positive")
+	}
+
+	return &idempotencyPurgeService{
+		store:      store,
+		jobService: jobService,
+		interval:   interval,
+	}, nil
+}
+
+func (svc *idempotencyPurgeService) SchedulePurges(ctx context.Context) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering IdempotencyPurgeService. SchedulePurges()")
+
+	pending, err := svc.jobService.CountJobs(ctx, domain.JobTypeIdempotencyPurge, domain.JobStatusQueued,
+		domain.JobStatusRunning)
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		return nil
+	}
+
+	_, err = svc.jobService.EnqueueJob(ctx, domain.JobTypeIdempotencyPurge, struct{}{}, idempotencyPurgeAttempts)
+
+	return err
+}
+
+func (svc *idempotencyPurgeService) JobType() string {
+	return domain.JobTypeIdempotencyPurge
+}
+
+// HandleJob deletes the expired records a batch at a time until none is left. The next purge is
+// scheduled first, so a purge that fails does not stop the periodic ones.
+func (svc *idempotencyPurgeService) HandleJob(ctx context.Context, job *domain.Job,
+	progress ports.JobProgress) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering IdempotencyPurgeService. HandleJob()")
+
+	if err := svc.scheduleNext(ctx); err != nil {
+		return err
+	}
+
+	total := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		purged, err := svc.store.PurgeExpired(ctx, idempotencyPurgeBatch)
+		if err != nil {
+			return fmt.Errorf("error in idempotency store: %w", err)
+		}
+
+		total += purged
+
+		if purged < idempotencyPurgeBatch {
+			progress(100, fmt.Sprintf("Purged %d expired idempotency keys", total))
+			return nil
+		}
+	}
+}
+
+// scheduleNext schedules the purge after this one unless one is already queued.
+func (svc *idempotencyPurgeService) scheduleNext(ctx context.Context) error {
+	queued, err := svc.jobService.CountJobs(ctx, domain.JobTypeIdempotencyPurge, domain.JobStatusQueued)
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		return nil
+	}
+
+	_, err = svc.jobService.ScheduleJob(ctx, domain.JobTypeIdempotencyPurge, struct{}{}, idempotencyPurgeAttempts,
+		time.Now().Add(svc.interval))
+
+	return err
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+// importProgressRows is how often the counters and the errors of a running import are stored.
+const importProgressRows = 100
+
+// importJobAttempts is 1 because rows written by a failed attempt would be counted again by a retry.
+const importJobAttempts = 1
+
+// importJobPayload only names the import. Its parsed rows are stored with the import, so the
+// job does not depend on the uploaded file and the jobs table never holds a whole file.
+type importJobPayload struct {
+	ImportID uint
+}
+
+type importService struct {
+	itemService      ports.ItemService
+	itemRepository   ports.ItemRepository
+	importRepository ports.ImportRepository
+	jobService       ports.JobService
+}
+
+// NewImportService writes the rows through the item service, so imported items go through
+// the same validation and auditing as the ones created by the API.
+func NewImportService(itemService ports.ItemService, itemRepository ports.ItemRepository,
+	importRepository ports.ImportRepository, jobService ports.JobService) (ports.ImportService, error) {
+	if itemService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	if itemRepository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if importRepository == nil {
+		return nil, fmt.Errorf("import repository cannot be nil")
+	}
+
+	if jobService == nil {
+		return nil, fmt.Errorf("job service cannot be nil")
+	}
+
+	return &importService{
+		itemService:      itemService,
+		itemRepository:   itemRepository,
+		importRepository: importRepository,
+		jobService:       jobService,
+	}, nil
+}
+
+func (svc *importService) StartImport(ctx context.Context, itemImport domain.Import,
+	rows []domain.ImportRow) (*domain.Import, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ImportService. StartImport()")
+
+	if err := svc.saveImport(ctx, &itemImport, rows); err != nil {
+		return nil, err
+	}
+
+	if err := svc.importRepository.SaveImportRows(ctx, itemImport.ID, rows); err != nil {
+		err = fmt.Errorf("error in repository: %w", err)
+		svc.fail(ctx, &itemImport, err)
+
+		return nil, err
+	}
+
+	job, err := svc.jobService.EnqueueJob(ctx, domain.JobTypeItemImport,
+		importJobPayload{ImportID: itemImport.ID}, importJobAttempts)
+	if err != nil {
+		svc.fail(ctx, &itemImport, err)
+		return nil, err
+	}
+
+	itemImport.JobID = job.ID
+	if err := svc.importRepository.UpdateImport(ctx, &itemImport); err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return &itemImport, nil
+}
+
+func (svc *importService) RunImport(ctx context.Context, itemImport domain.Import,
+	rows []domain.ImportRow) (*domain.Import, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ImportService. RunImport()")
+
+	if err := svc.saveImport(ctx, &itemImport, rows); err != nil {
+		return nil, err
+	}
+
+	// A failure to process the rows is recorded in the import itself.
+	_ = svc.process(ctx, &itemImport, rows, nil)
+
+	return &itemImport, nil
+}
+
+func (svc *importService) JobType() string {
+	return domain.JobTypeItemImport
+}
+
+// HandleJob processes the rows of an import started by StartImport.
+func (svc *importService) HandleJob(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ImportService. HandleJob()")
+
+	var payload importJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("error decoding import job payload: %w", err)
+	}
+
+	// The job has a single attempt, so its rows are not needed once it is done, whatever the outcome.
+	defer svc.deleteRows(ctx, payload.ImportID)
+
+	itemImport, err := svc.importRepository.GetImport(ctx, payload.ImportID)
+	if err != nil {
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	// An import interrupted by a stop of the runner is not processed again, as its rows were partly written.
+	if itemImport.Status != domain.ImportStatusPending {
+		return fmt.Errorf("import %d was interrupted with status %s", itemImport.ID, itemImport.Status)
+	}
+
+	rows, err := svc.importRepository.GetImportRows(ctx, itemImport.ID)
+	if err != nil {
+		svc.fail(ctx, itemImport, err)
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	return svc.process(ctx, itemImport, rows, progress)
+}
+
+func (svc *importService) GetImport(ctx context.Context, id uint) (*domain.Import, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ImportService. GetImport()")
+
+	itemImport, err := svc.importRepository.GetImport(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return itemImport, nil
+}
+
+func (svc *importService) GetImportErrors(ctx context.Context, id uint) ([]domain.ImportError, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ImportService. GetImportErrors()")
+
+	if _, err := svc.importRepository.GetImport(ctx, id); err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	importErrors, err := svc.importRepository.GetImportErrors(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return importErrors, nil
+}
+
+func (svc *importService) saveImport(ctx context.Context, itemImport *domain.Import, rows []domain.ImportRow) error {
+	itemImport.Status = domain.ImportStatusPending
+	itemImport.TotalRows = len(rows)
+
+	if err := svc.importRepository.SaveImport(ctx, itemImport); err != nil {
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	return nil
+}
+
+// process upserts the rows one by one, storing and reporting the progress every importProgressRows
+// rows. A failing row never stops the import, only a cancelled context or a failure to store the
+// progress does, which is the returned error.
+func (svc *importService) process(ctx context.Context, itemImport *domain.Import, rows []domain.ImportRow,
+	progress ports.JobProgress) error {
+	logger := marketcontext.Logger(ctx).With(log.Uint("import_id", itemImport.ID))
+
+	itemImport.Status = domain.ImportStatusRunning
+	if err := svc.importRepository.UpdateImport(ctx, itemImport); err != nil {
+		svc.fail(ctx, itemImport, err)
+		return err
+	}
+
+	// A dry run writes nothing, so the codes of the rows that would be created are kept
+	// to count a later row with the same code as an update.
+	pending := make(map[string]bool)
+	var importErrors []domain.ImportError
+
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			svc.cancel(itemImport, importErrors)
+			return err
+		}
+
+		rowErrors := row.Errors
+		if len(rowErrors) == 0 {
+			created, err := svc.importRow(ctx, itemImport.DryRun, row.Item, pending)
+			switch {
+			case err != nil:
+				if domain.ErrorCodeOf(err) == domain.ErrCodeInternal {
+					logger.Error(svc, nil, err, "error importing row %d", row.Line)
+				}
+
+				rowErrors = []domain.ImportError{importErrorOf(row.Line, err)}
+			case created:
+				itemImport.CreatedRows++
+			default:
+				itemImport.UpdatedRows++
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			itemImport.FailedRows++
+			importErrors = append(importErrors, rowErrors...)
+		}
+
+		itemImport.ProcessedRows++
+		if itemImport.ProcessedRows%importProgressRows != 0 {
+			continue
+		}
+
+		if err := svc.saveProgress(ctx, itemImport, importErrors); err != nil {
+			svc.fail(ctx, itemImport, err)
+			return err
+		}
+
+		importErrors = nil
+		if progress != nil {
+			progress(itemImport.ProcessedRows*100/len(rows),
+				fmt.Sprintf("%d of %d rows processed", itemImport.ProcessedRows, len(rows)))
+		}
+	}
+
+	completedAt := time.Now()
+	itemImport.Status = domain.ImportStatusCompleted
+	itemImport.CompletedAt = &completedAt
+
+	if err := svc.saveProgress(ctx, itemImport, importErrors); err != nil {
+		svc.fail(ctx, itemImport, err)
+		return err
+	}
+
+	return nil
+}
+
+// importRow creates or updates the item with the code of the row and reports whether it was created.
+func (svc *importService) importRow(ctx context.Context, dryRun bool, item domain.Item,
+	pending map[string]bool) (bool, error) {
+	existing, err := svc.itemRepository.GetItemByCode(ctx, item.Code)
+
+	var notFound domain.ResourceNotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return false, fmt.Errorf("error in repository: %w", err)
+	}
+
+	if dryRun {
+		item.SetStatus()
+		if err := validateItemModel(&item); err != nil {
+			return false, err
+		}
+
+		created := existing == nil && !pending[item.Code]
+		pending[item.Code] = true
+
+		return created, nil
+	}
+
+	if existing == nil {
+		_, err := svc.itemService.CreateItem(ctx, item)
+		return true, err
+	}
+
+	item.ID = existing.ID
+	item.Version = existing.Version
+	_, err = svc.itemService.UpdateItem(ctx, item)
+
+	return false, err
+}
+
+func (svc *importService) saveProgress(ctx context.Context, itemImport *domain.Import,
+	importErrors []domain.ImportError) error {
+	if err := svc.importRepository.SaveImportErrors(ctx, itemImport.ID, importErrors); err != nil {
+		return err
+	}
+
+	return svc.importRepository.UpdateImport(ctx, itemImport)
+}
+
+// deleteRows removes the stored rows of an import. The context of the job may be cancelled, so
+// the rows are deleted with a context of their own.
+func (svc *importService) deleteRows(ctx context.Context, importID uint) {
+	logger := marketcontext.Logger(ctx).With(log.Uint("import_id", importID))
+
+	if err := svc.importRepository.DeleteImportRows(marketcontext.Detach(ctx), importID); err != nil {
+		logger.Error(svc, nil, err, "error deleting import rows")
+	}
+}
+
+func (svc *importService) fail(ctx context.Context, itemImport *domain.Import, err error) {
+	logger := marketcontext.Logger(ctx).With(log.Uint("import_id", itemImport.ID))
+	logger.Error(svc, nil, err, "error processing import")
+
+	completedAt := time.Now()
+	itemImport.Status = domain.ImportStatusFailed
+	itemImport.Error = "The import could not be processed"
+	itemImport.CompletedAt = &completedAt
+
+	if err := svc.importRepository.UpdateImport(ctx, itemImport); err != nil {
+		logger.Error(svc, nil, err, "error updating import")
+	}
+}
+
+// cancel stores the progress of an import whose job was cancelled. The context of the job is
+// already done, so the import is stored with a context of its own.
+func (svc *importService) cancel(itemImport *domain.Import, importErrors []domain.ImportError) {
+	ctx := context.Background()
+	logger := marketcontext.Logger(ctx).With(log.Uint("import_id", itemImport.ID))
+
+	completedAt := time.Now()
+	itemImport.Status = domain.ImportStatusCancelled
+	itemImport.CompletedAt = &completedAt
+
+	if err := svc.saveProgress(ctx, itemImport, importErrors); err != nil {
+		logger.Error(svc, nil, err, "error updating import")
+	}
+}
+
+// importErrorOf reports the error of a row without leaking the messages of unexpected errors.
+func importErrorOf(line int, err error) domain.ImportError {
+	importError := domain.ImportError{
+		Line: line,
+		Code: domain.ErrorCodeOf(err),
+	}
+
+	var itemErr domain.ItemError
+	var coded domain.CodedError
+
+	switch {
+	case errors.As(err, &itemErr):
+		importError.Message = itemErr.Message
+		importError.Args = itemErr.Args
+	case errors.As(err, &coded):
+		importError.Message = coded.Error()
+	default:
+		importError.Message = "An unexpected error occurred"
+	}
+
+	return importError
+}
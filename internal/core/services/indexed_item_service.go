@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+// indexedItemService decorates an ItemService and keeps the item indexes, e.g. the search
+// index, in sync with every successful write. Reads go straight to the decorated service.
+type indexedItemService struct {
+	ports.ItemService
+	indexes []ports.ItemIndex
+}
+
+func NewIndexedItemService(itemService ports.ItemService, indexes ...ports.ItemIndex) (ports.ItemService, error) {
+	if itemService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	for _, index := range indexes {
+		if index == nil {
+			return nil, fmt.Errorf("item index cannot be nil")
+		}
+	}
+
+	return &indexedItemService{
+		ItemService: itemService,
+		indexes:     indexes,
+	}, nil
+}
+
+func (svc *indexedItemService) CreateItem(ctx context.Context, item domain.Item) (*domain.Item, error) {
+	created, err := svc.ItemService.CreateItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.index(ctx, created)
+
+	return created, nil
+}
+
+func (svc *indexedItemService) CreateItems(ctx context.Context, items []domain.Item,
+	mode string) ([]domain.BulkItemResult, error) {
+	results, err := svc.ItemService.CreateItems(ctx, items, mode)
+	if err != nil {
+		return results, err
+	}
+
+	for _, result := range results {
+		if result.Status == domain.BulkStatusCreated {
+			svc.index(ctx, result.Item)
+		}
+	}
+
+	return results, nil
+}
+
+func (svc *indexedItemService) UpdateItem(ctx context.Context, item domain.Item) (*domain.Item, error) {
+	updated, err := svc.ItemService.UpdateItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.index(ctx, updated)
+
+	return updated, nil
+}
+
+func (svc *indexedItemService) PatchItem(ctx context.Context, itemID uint, version uint,
+	patch domain.ItemPatch) (*domain.Item, error) {
+	patched, err := svc.ItemService.PatchItem(ctx, itemID, version, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.index(ctx, patched)
+
+	return patched, nil
+}
+
+func (svc *indexedItemService) DeleteItem(ctx context.Context, itemID uint, version uint) error {
+	if err := svc.ItemService.DeleteItem(ctx, itemID, version); err != nil {
+		return err
+	}
+
+	for _, index := range svc.indexes {
+		if err := index.RemoveItem(ctx, itemID); err != nil {
+			marketcontext.Logger(ctx).With(log.Uint("item_id", itemID)).Error(svc, nil, err,
+				"error removing item from index")
+		}
+	}
+
+	return nil
+}
+
+func (svc *indexedItemService) RevertItem(ctx context.Context, itemID uint, revisionVersion uint,
+	version uint) (*domain.Item, error) {
+	reverted, err := svc.ItemService.RevertItem(ctx, itemID, revisionVersion, version)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.index(ctx, reverted)
+
+	return reverted, nil
+}
+
+// index adds an item that was already written. A failure is logged rather than returned,
+// the item is stored and a reindex brings it back into the index.
+func (svc *indexedItemService) index(ctx context.Context, item *domain.Item) {
+	for _, index := range svc.indexes {
+		if err := index.IndexItem(ctx, item); err != nil {
+			marketcontext.Logger(ctx).With(log.Uint("item_id", item.ID)).Error(svc, nil, err,
+				"error indexing item")
+		}
+	}
+}
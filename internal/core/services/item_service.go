@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/osalomon89/test-crud-api/internal/core/domain"
 	"github.com/osalomon89/test-crud-api/internal/core/ports"
@@ -10,15 +12,24 @@ import (
 )
 
 type itemService struct {
-	itemRepository ports.ItemRepository
+	itemRepository     ports.ItemRepository
+	revisionRepository ports.ItemRevisionRepository
 }
 
-func NewItemService(itemRepository ports.ItemRepository) (ports.ItemService, error) {
+func NewItemService(itemRepository ports.ItemRepository,
+	revisionRepository ports.ItemRevisionRepository) (ports.ItemService, error) {
 	if itemRepository == nil {
 		return nil, fmt.Errorf("repository cannot be nil")
 	}
 
-	return &itemService{itemRepository: itemRepository}, nil
+	if revisionRepository == nil {
+		return nil, fmt.Errorf("revision repository cannot be nil")
+	}
+
+	return &itemService{
+		itemRepository:     itemRepository,
+		revisionRepository: revisionRepository,
+	}, nil
 }
 
 func (svc *itemService) CreateItem(ctx context.Context,
@@ -40,6 +51,87 @@ func (svc *itemService) CreateItem(ctx context.Context,
 	return &item, nil
 }
 
+// CreateItems validates every item with the same rules as CreateItem, plus the uniqueness of
+// codes inside the batch, and stores the valid ones with a single repository call.
+func (svc *itemService) CreateItems(ctx context.Context, items []domain.Item,
+	mode string) ([]domain.BulkItemResult, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. CreateItems()")
+
+	results := make([]domain.BulkItemResult, len(items))
+	valid := make([]*domain.Item, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	codes := make(map[string]bool, len(items))
+
+	for i := range items {
+		item := items[i]
+		item.SetStatus()
+		results[i] = domain.BulkItemResult{Index: i, Status: domain.BulkStatusSkipped}
+
+		if err := validateItemModel(&item); err != nil {
+			results[i].Status = domain.BulkStatusFailed
+			results[i].Err = err
+			continue
+		}
+
+		if codes[item.Code] {
+			results[i].Status = domain.BulkStatusFailed
+			results[i].Err = domain.ItemError{
+				Code:    domain.ErrCodeItemCodeDuplicated,
+				Message: "The item code must be unique",
+			}
+			continue
+		}
+
+		codes[item.Code] = true
+		valid = append(valid, &item)
+		indexes = append(indexes, i)
+	}
+
+	if mode == domain.BulkModeAtomic && domain.BulkFailed(results) {
+		return results, domain.ItemError{
+			Code:    domain.ErrCodeBulkRejected,
+			Message: "No item was created because some of them are not valid",
+		}
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	err := svc.itemRepository.SaveItems(ctx, valid)
+	if err != nil && mode == domain.BulkModeAtomic {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	if err != nil {
+		// A single conflicting item, e.g. a code that already exists, fails the whole multi-row
+		// insert, so the batch is retried item by item to tell the failing ones apart.
+		logger.Warn(svc, nil, "bulk insert failed, retrying item by item: %s", err.Error())
+
+		for k, item := range valid {
+			result := &results[indexes[k]]
+			if err := svc.itemRepository.SaveItem(ctx, item); err != nil {
+				result.Status = domain.BulkStatusFailed
+				result.Err = fmt.Errorf("error in repository: %w", err)
+				continue
+			}
+
+			result.Status = domain.BulkStatusCreated
+			result.Item = item
+		}
+
+		return results, nil
+	}
+
+	for k, item := range valid {
+		results[indexes[k]].Status = domain.BulkStatusCreated
+		results[indexes[k]].Item = item
+	}
+
+	return results, nil
+}
+
 func (svc *itemService) GetItemByID(ctx context.Context, itemID uint) (*domain.Item, error) {
 	logger := marketcontext.Logger(ctx)
 	logger.Debug(svc, nil, "Entering ItemService. GetItemByID()")
@@ -52,12 +144,189 @@ func (svc *itemService) GetItemByID(ctx context.Context, itemID uint) (*domain.I
 	return item, nil
 }
 
+func (svc *itemService) ExportItems(ctx context.Context, filter domain.ItemFilter,
+	fn func(*domain.Item) error) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. ExportItems()")
+
+	if err := svc.itemRepository.StreamItems(ctx, filter, fn); err != nil {
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateItem keeps the checks of the stored photos the item still has, as its status depends
+// on the check of its primary photo, and their arrangement, which a full update does not carry.
+func (svc *itemService) UpdateItem(ctx context.Context, item domain.Item) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. UpdateItem()")
+
+	stored, err := svc.itemRepository.GetItemByID(ctx, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	item.KeepPhotoChecks(stored.Photos)
+	item.KeepPhotoArrangement(stored.Photos)
+	item.SetStatus()
+
+	if err := validateItemModel(&item); err != nil {
+		return nil, err
+	}
+
+	if err := svc.itemRepository.UpdateItem(ctx, &item); err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return svc.GetItemByID(ctx, item.ID)
+}
+
+func (svc *itemService) PatchItem(ctx context.Context, itemID uint, version uint,
+	patch domain.ItemPatch) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. PatchItem()")
+
+	item, err := svc.itemRepository.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	if version != 0 && item.Version != version {
+		return nil, domain.ConflictError{
+			Message: "The item has been modified by another request",
+		}
+	}
+
+	photos := item.Photos
+	patch.Apply(item)
+	item.KeepPhotoChecks(photos)
+	item.KeepPhotoArrangement(photos)
+	item.SetStatus()
+
+	if err := validateItemModel(item); err != nil {
+		return nil, err
+	}
+
+	// The update is always conditional on the version read above, so a concurrent
+	// write between the read and the update is never overwritten.
+	if err := svc.itemRepository.UpdateItem(ctx, item); err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return svc.GetItemByID(ctx, item.ID)
+}
+
+func (svc *itemService) DeleteItem(ctx context.Context, itemID uint, version uint) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. DeleteItem()")
+
+	if err := svc.itemRepository.DeleteItem(ctx, itemID, version); err != nil {
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	return nil
+}
+
+func (svc *itemService) GetItemAsOf(ctx context.Context, itemID uint, asOf time.Time) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. GetItemAsOf()")
+
+	revision, err := svc.revisionRepository.GetItemRevisionAt(ctx, itemID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	if revision.Operation == domain.RevisionOperationDelete {
+		return nil, domain.ResourceNotFoundError{
+			Message: "Item not found",
+		}
+	}
+
+	return &revision.Item, nil
+}
+
+func (svc *itemService) GetItemRevisions(ctx context.Context, itemID uint) ([]domain.ItemRevision, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. GetItemRevisions()")
+
+	revisions, err := svc.revisionRepository.GetItemRevisions(ctx, itemID)
+	if err == nil {
+		return revisions, nil
+	}
+
+	// An item written by a version that did not record revisions yet has none until its next
+	// write, which is not the same as a missing item.
+	var notFound domain.ResourceNotFoundError
+	if !errors.As(err, &notFound) {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	if _, err := svc.itemRepository.GetItemByID(ctx, itemID); err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return []domain.ItemRevision{}, nil
+}
+
+func (svc *itemService) DiffItemRevisions(ctx context.Context, itemID uint,
+	fromVersion, toVersion uint) ([]domain.FieldChange, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. DiffItemRevisions()")
+
+	from, err := svc.revisionRepository.GetItemRevision(ctx, itemID, fromVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	to, err := svc.revisionRepository.GetItemRevision(ctx, itemID, toVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return domain.DiffItems(from.Item, to.Item), nil
+}
+
+// RevertItem writes the content of a past revision as a new revision of the item,
+// going through the same validation as any other update. The photos keep their current
+// checks rather than the ones the revision recorded.
+func (svc *itemService) RevertItem(ctx context.Context, itemID uint, revisionVersion uint,
+	version uint) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering ItemService. RevertItem()")
+
+	revision, err := svc.revisionRepository.GetItemRevision(ctx, itemID, revisionVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	if revision.Operation == domain.RevisionOperationDelete {
+		return nil, domain.ItemError{
+			Code:    domain.ErrCodeRevisionNotRestorable,
+			Message: "A deletion revision can not be restored",
+		}
+	}
+
+	item := revision.Item
+	item.ID = itemID
+	item.Version = version
+	item.Photos = append([]domain.Photo(nil), item.Photos...)
+
+	for i := range item.Photos {
+		item.Photos[i].Check = nil
+	}
+
+	return svc.UpdateItem(ctx, item)
+}
+
 func validateItemModel(item *domain.Item) error {
 	if item.ItemType == domain.ItemTypeSeller {
 		if item.Leader {
 			if !isAValidLeaderLevel(item.LeaderLevel) {
 				return domain.ItemError{
-					Message: fmt.Sprintf("Error in params validation. Leader level is not valid: %s", item.LeaderLevel),
+					Code:    domain.ErrCodeItemLeaderLevelInvalid,
+					Message: fmt.Sprintf("Leader level is not valid: %s", item.LeaderLevel),
+					Args:    []interface{}{item.LeaderLevel},
 				}
 			}
 		} else {
@@ -67,10 +336,21 @@ func validateItemModel(item *domain.Item) error {
 
 	if len(item.Photos) == 0 {
 		return domain.ItemError{
-			Message: "Error in params validation: photos array len can not be null",
+			Code:    domain.ErrCodeItemPhotosRequired,
+			Message: "The item must have at least one photo",
+		}
+	}
+
+	if len(item.Photos) > domain.MaxPhotosPerItem {
+		return domain.ItemError{
+			Code:    domain.ErrCodeItemPhotosLimit,
+			Message: fmt.Sprintf("An item can have at most %d photos", domain.MaxPhotosPerItem),
+			Args:    []interface{}{domain.MaxPhotosPerItem},
 		}
 	}
 
+	item.ArrangePhotos()
+
 	return nil
 }
 
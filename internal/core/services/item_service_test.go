@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+)
+
+func newTestItemService(t *testing.T) (*itemService, *itemStore) {
+	store := newItemStore()
+
+	svc, err := NewItemService(store, store)
+	if err != nil {
+		t.Fatalf("NewItemService() error = %v", err)
+	}
+
+	return svc.(*itemService), store
+}
+
+func TestGetItemRevisionsOfAnItemWithoutRevisions(t *testing.T) {
+	svc, store := newTestItemService(t)
+	ctx := context.Background()
+
+	item := store.put(domain.Item{Code: "A1", Title: "Item", Stock: 1})
+
+	revisions, err := svc.GetItemRevisions(ctx, item.ID)
+	if err != nil || len(revisions) != 0 {
+		t.Fatalf("GetItemRevisions() = %v, %v, want no revisions for an existing item", revisions, err)
+	}
+
+	_, err = svc.GetItemRevisions(ctx, item.ID+1)
+
+	var notFound domain.ResourceNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("GetItemRevisions() of a missing item error = %v, want not found", err)
+	}
+}
+
+// TestCreateItemValidationProblems checks the problems of the errors of validateItemModel in
+// every language, as the item handlers write them.
+func TestCreateItemValidationProblems(t *testing.T) {
+	photos := make([]domain.Photo, domain.MaxPhotosPerItem+1)
+	for i := range photos {
+		photos[i] = domain.Photo{Path: "https://images.example.com/photo.jpg"}
+	}
+
+	tests := []struct {
+		name       string
+		item       domain.Item
+		language   string
+		wantCode   string
+		wantDetail string
+	}{
+		{
+			name:       "photos required",
+			item:       domain.Item{Code: "A1", Title: "Item"},
+			language:   "es-AR",
+			wantCode:   domain.ErrCodeItemPhotosRequired,
+			wantDetail: "El ítem debe tener al menos una foto",
+		},
+		{
+			name: "leader level not valid",
+			item: domain.Item{Code: "A1", Title: "Item", ItemType: domain.ItemTypeSeller, Leader: true,
+				LeaderLevel: "DIAMOND", Photos: photos[:1]},
+			language:   "pt-BR",
+			wantCode:   domain.ErrCodeItemLeaderLevelInvalid,
+			wantDetail: "O nível de líder não é válido: DIAMOND",
+		},
+		{
+			name:       "too many photos",
+			item:       domain.Item{Code: "A1", Title: "Item", Photos: photos},
+			language:   "es",
+			wantCode:   domain.ErrCodeItemPhotosLimit,
+			wantDetail: "Un ítem puede tener como máximo 20 fotos",
+		},
+		{
+			name:       "too many photos in english",
+			item:       domain.Item{Code: "A1", Title: "Item", Photos: photos},
+			language:   "en",
+			wantCode:   domain.ErrCodeItemPhotosLimit,
+			wantDetail: "An item can have at most 20 photos",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc, _ := newTestItemService(t)
+
+			_, err := svc.CreateItem(context.Background(), test.item)
+			if err == nil {
+				t.Fatal("CreateItem() error = nil, want a validation error")
+			}
+
+			req := httptest.NewRequest("POST", "/v1/items", nil)
+			req.Header.Set("Accept-Language", test.language)
+
+			got := problem.Resolve(req, err)
+			if got.Code != test.wantCode || got.Detail != test.wantDetail {
+				t.Errorf("problem = %s %q, want %s %q", got.Code, got.Detail, test.wantCode, test.wantDetail)
+			}
+		})
+	}
+}
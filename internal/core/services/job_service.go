@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type jobService struct {
+	jobRepository ports.JobRepository
+}
+
+func NewJobService(jobRepository ports.JobRepository) (ports.JobService, error) {
+	if jobRepository == nil {
+		return nil, fmt.Errorf("job repository cannot be nil")
+	}
+
+	return &jobService{
+		jobRepository: jobRepository,
+	}, nil
+}
+
+// EnqueueJob records the caller as the actor of the job, so what the job changes is audited
+// as done by whoever started it.
+func (svc *jobService) EnqueueJob(ctx context.Context, jobType string, payload interface{},
+	maxAttempts int) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering JobService. EnqueueJob()")
+
+	return svc.ScheduleJob(ctx, jobType, payload, maxAttempts, time.Time{})
+}
+
+// ScheduleJob runs the job at once when runAt is zero.
+func (svc *jobService) ScheduleJob(ctx context.Context, jobType string, payload interface{},
+	maxAttempts int, runAt time.Time) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering JobService. ScheduleJob()")
+
+	if maxAttempts <= 0 {
+		return nil, fmt.Errorf("job max attempts must be positive")
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding job payload: %w", err)
+	}
+
+	job := &domain.Job{
+		Type:        jobType,
+		Payload:     encoded,
+		Status:      domain.JobStatusQueued,
+		MaxAttempts: maxAttempts,
+		Actor:       marketcontext.GetCaller(ctx).ID,
+		RunAt:       runAt,
+	}
+
+	if err := svc.jobRepository.SaveJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return job, nil
+}
+
+func (svc *jobService) CountJobs(ctx context.Context, jobType string, statuses ...string) (int, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering JobService. CountJobs()")
+
+	count, err := svc.jobRepository.CountJobs(ctx, jobType, statuses)
+	if err != nil {
+		return 0, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return count, nil
+}
+
+func (svc *jobService) GetJob(ctx context.Context, id uint) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering JobService. GetJob()")
+
+	job, err := svc.jobRepository.GetJob(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return job, nil
+}
+
+// CancelJob cancels a queued job at once, while a running one stops at its next heartbeat.
+func (svc *jobService) CancelJob(ctx context.Context, id uint) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering JobService. CancelJob()")
+
+	job, err := svc.jobRepository.GetJob(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	if job.Finished() {
+		return nil, domain.Error{
+			Code:    domain.ErrCodeJobFinished,
+			Message: "The job has already finished",
+		}
+	}
+
+	job, err = svc.jobRepository.CancelJob(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return job, nil
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+const (
+	// photoCheckBatch is how many photos are read and checked at a time.
+	photoCheckBatch = 100
+	// photoCheckAttempts retries a check, which starts over from the first photo.
+	photoCheckAttempts = 3
+)
+
+type photoCheckService struct {
+	itemService     ports.ItemService
+	photoRepository ports.PhotoRepository
+	checker         ports.PhotoURLChecker
+	jobService      ports.JobService
+	interval        time.Duration
+	deactivate      bool
+	deactivateAfter int
+}
+
+// NewPhotoCheckService runs a check every interval. With deactivate, a primary photo found
+// broken by deactivateAfter checks in a row makes its item inactive, through the decorated
+// ItemService so the change gets a revision, an audit entry and an index update like any
+// other update.
+func NewPhotoCheckService(itemService ports.ItemService, photoRepository ports.PhotoRepository,
+	checker ports.PhotoURLChecker, jobService ports.JobService, interval time.Duration,
+	deactivate bool, deactivateAfter int) (ports.PhotoCheckService, error) {
+	if itemService == nil {
+		return nil, fmt.Errorf("item service cannot be nil")
+	}
+
+	if photoRepository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if checker == nil {
+		return nil, fmt.Errorf("photo url checker cannot be nil")
+	}
+
+	if jobService == nil {
+		return nil, fmt.Errorf("job service cannot be nil")
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("photo check interval must be positive")
+	}
+
+	if deactivateAfter <= 0 {
+		return nil, fmt.Errorf("photo check failures before deactivation must be positive")
+	}
+
+	return &photoCheckService{
+		itemService:     itemService,
+		photoRepository: photoRepository,
+		checker:         checker,
+		jobService:      jobService,
+		interval:        interval,
+		deactivate:      deactivate,
+		deactivateAfter: deactivateAfter,
+	}, nil
+}
+
+func (svc *photoCheckService) ScheduleChecks(ctx context.Context) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoCheckService. ScheduleChecks()")
+
+	pending, err := svc.jobService.CountJobs(ctx, domain.JobTypePhotoCheck, domain.JobStatusQueued,
+		domain.JobStatusRunning)
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		return nil
+	}
+
+	_, err = svc.jobService.EnqueueJob(ctx, domain.JobTypePhotoCheck, struct{}{}, photoCheckAttempts)
+
+	return err
+}
+
+func (svc *photoCheckService) StartCheck(ctx context.Context) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoCheckService. StartCheck()")
+
+	return svc.jobService.EnqueueJob(ctx, domain.JobTypePhotoCheck, struct{}{}, photoCheckAttempts)
+}
+
+func (svc *photoCheckService) GetReport(ctx context.Context,
+	filter domain.PhotoCheckFilter) (*domain.PhotoCheckReport, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoCheckService. GetReport()")
+
+	report, err := svc.photoRepository.GetPhotoCheckReport(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	return report, nil
+}
+
+func (svc *photoCheckService) JobType() string {
+	return domain.JobTypePhotoCheck
+}
+
+// HandleJob checks every photo given by URL, requesting each URL once per batch. The next check
+// is scheduled first, so a check that fails does not stop the periodic ones.
+func (svc *photoCheckService) HandleJob(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoCheckService. HandleJob()")
+
+	if err := svc.scheduleNext(ctx); err != nil {
+		return err
+	}
+
+	report, err := svc.photoRepository.GetPhotoCheckReport(ctx, domain.PhotoCheckFilter{})
+	if err != nil {
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	var afterID uint
+	checked := 0
+
+	for {
+		photos, err := svc.photoRepository.FindPhotosToCheck(ctx, afterID, photoCheckBatch)
+		if err != nil {
+			return fmt.Errorf("error in repository: %w", err)
+		}
+
+		if len(photos) == 0 {
+			return nil
+		}
+
+		if err := svc.checkPhotos(ctx, photos); err != nil {
+			return err
+		}
+
+		afterID = photos[len(photos)-1].ID
+		checked += len(photos)
+
+		percent := 100
+		if checked < report.Total {
+			percent = checked * 100 / report.Total
+		}
+
+		progress(percent, fmt.Sprintf("Checked %d photos", checked))
+	}
+}
+
+// scheduleNext schedules the check after this one unless one is already queued, e.g. when a
+// check was also started by hand, so the schedules of concurrent checks merge into one.
+func (svc *photoCheckService) scheduleNext(ctx context.Context) error {
+	queued, err := svc.jobService.CountJobs(ctx, domain.JobTypePhotoCheck, domain.JobStatusQueued)
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		return nil
+	}
+
+	_, err = svc.jobService.ScheduleJob(ctx, domain.JobTypePhotoCheck, struct{}{}, photoCheckAttempts,
+		time.Now().Add(svc.interval))
+
+	return err
+}
+
+// checkPhotos records the checks of a batch of photos, and updates the status of the items whose
+// primary photo got or lost a check that deactivates them. The photos of a URL share its check
+// but not their count of failures, so the photos are saved by the check they end up with.
+func (svc *photoCheckService) checkPhotos(ctx context.Context, photos []domain.Photo) error {
+	urls := make([]string, 0, len(photos))
+	seen := make(map[string]bool, len(photos))
+
+	for _, photo := range photos {
+		if !seen[photo.Path] {
+			seen[photo.Path] = true
+			urls = append(urls, photo.Path)
+		}
+	}
+
+	checks := svc.checker.CheckURLs(ctx, urls)
+
+	// The requests of a cancelled job fail, which is not a broken photo.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	updates := make(map[domain.PhotoCheck][]uint, len(checks))
+	itemIDs := make([]uint, 0)
+
+	for _, photo := range photos {
+		check, ok := checks[photo.Path]
+		if !ok {
+			continue
+		}
+
+		check = svc.followCheck(photo.Check, check)
+		updates[check] = append(updates[check], photo.ID)
+
+		if photo.IsPrimary && check.Deactivates() != photo.Check.Deactivates() {
+			itemIDs = append(itemIDs, photo.ItemID)
+		}
+	}
+
+	for check, photoIDs := range updates {
+		if err := svc.photoRepository.SetPhotoCheck(ctx, photoIDs, check); err != nil {
+			return fmt.Errorf("error in repository: %w", err)
+		}
+	}
+
+	for _, itemID := range itemIDs {
+		if err := svc.updateStatus(ctx, itemID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// followCheck counts the failures of a photo from its previous check. An unreachable check
+// says nothing about the photo, so it neither counts as a failure nor resets the count, and
+// keeps the item of the photo as it was.
+func (svc *photoCheckService) followCheck(previous *domain.PhotoCheck, check domain.PhotoCheck) domain.PhotoCheck {
+	switch check.Status {
+	case domain.PhotoCheckBroken:
+		check.Failures = 1
+		if previous != nil {
+			check.Failures += previous.Failures
+		}
+
+		check.DeactivatesItem = svc.deactivate && check.Failures >= svc.deactivateAfter
+	case domain.PhotoCheckUnreachable:
+		if previous != nil {
+			check.Failures = previous.Failures
+			check.DeactivatesItem = previous.DeactivatesItem
+		}
+	}
+
+	return check
+}
+
+// updateStatus saves the item when its status changed with the checks of its photos. An item
+// changed or deleted meanwhile is left to the next check.
+func (svc *photoCheckService) updateStatus(ctx context.Context, itemID uint) error {
+	logger := marketcontext.Logger(ctx).With(log.Uint("item_id", itemID))
+
+	var (
+		notFound domain.ResourceNotFoundError
+		conflict domain.ConflictError
+	)
+
+	item, err := svc.itemService.GetItemByID(ctx, itemID)
+	if errors.As(err, &notFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	updated := *item
+	updated.SetStatus()
+
+	if updated.Status == item.Status {
+		return nil
+	}
+
+	if _, err := svc.itemService.UpdateItem(ctx, *item); err != nil {
+		if errors.As(err, &conflict) || errors.As(err, &notFound) {
+			logger.Warn(svc, nil, "item changed while checking its photos")
+			return nil
+		}
+
+		return err
+	}
+
+	logger.Info(svc, nil, "item status changed to "+updated.Status+" by the check of its primary photo")
+
+	return nil
+}
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// photoVariantsAttempts retries the generation of the variants, which skips the ones already stored.
+const photoVariantsAttempts = 3
+
+var errPhotoNotFound = domain.ResourceNotFoundError{
+	Message: "Photo not found",
+}
+
+// photoVariantsJobPayload names the blob whose variants are generated, for every photo using it.
+type photoVariantsJobPayload struct {
+	BlobKey     string `json:"blobKey"`
+	ContentType string `json:"contentType"`
+}
+
+type photoService struct {
+	itemService     ports.ItemService
+	photoRepository ports.PhotoRepository
+	blobStore       ports.BlobStore
+	processor       ports.PhotoProcessor
+	jobService      ports.JobService
+}
+
+// NewPhotoService builds the service on the decorated ItemService, so photo changes get
+// revisions, audit entries and index updates like any other update.
+func NewPhotoService(itemService ports.ItemService, photoRepository ports.PhotoRepository,
+	blobStore ports.BlobStore, processor ports.PhotoProcessor, jobService ports.JobService) (ports.PhotoService, error) {
+	if itemService == nil {
+		return nil, fmt.Errorf("item service cannot be nil")
+	}
+
+	if photoRepository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if blobStore == nil {
+		return nil, fmt.Errorf("blob store cannot be nil")
+	}
+
+	if processor == nil {
+		return nil, fmt.Errorf("photo processor cannot be nil")
+	}
+
+	if jobService == nil {
+		return nil, fmt.Errorf("job service cannot be nil")
+	}
+
+	return &photoService{
+		itemService:     itemService,
+		photoRepository: photoRepository,
+		blobStore:       blobStore,
+		processor:       processor,
+		jobService:      jobService,
+	}, nil
+}
+
+func (svc *photoService) GetPhotos(ctx context.Context, itemID uint) ([]domain.Photo, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoService. GetPhotos()")
+
+	item, err := svc.itemService.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return item.Photos, nil
+}
+
+func (svc *photoService) AddPhoto(ctx context.Context, itemID uint, version uint, photo domain.Photo,
+	position *int) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoService. AddPhoto()")
+
+	item, err := svc.getItem(ctx, itemID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPhotosLimit(item); err != nil {
+		return nil, err
+	}
+
+	index := len(item.Photos)
+	if position != nil && *position >= 0 && *position < index {
+		index = *position
+	}
+
+	if photo.IsPrimary {
+		for i := range item.Photos {
+			item.Photos[i].IsPrimary = false
+		}
+	}
+
+	photo.ID = 0
+	item.Photos = append(item.Photos, domain.Photo{})
+	copy(item.Photos[index+1:], item.Photos[index:])
+	item.Photos[index] = photo
+
+	return svc.itemService.UpdateItem(ctx, *item)
+}
+
+// UploadPhoto checks the limit of photos before storing the content, which is kept when the
+// update fails afterwards: blobs are addressed by content, so a retry finds it already stored.
+// The content is stored without its metadata, and its variants are generated by a job.
+func (svc *photoService) UploadPhoto(ctx context.Context, itemID uint, version uint, content []byte,
+	photo domain.Photo, position *int) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoService. UploadPhoto()")
+
+	contentType := domain.DetectPhotoType(content)
+	if contentType == "" {
+		return nil, domain.ItemError{
+			Code:    domain.ErrCodePhotoTypeUnsupported,
+			Message: "The photo must be a JPEG, PNG, GIF or WebP image",
+		}
+	}
+
+	item, err := svc.getItem(ctx, itemID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPhotosLimit(item); err != nil {
+		return nil, err
+	}
+
+	content, err = svc.processor.StripMetadata(content, contentType)
+	if errors.Is(err, domain.ErrPhotoTooManyPixels) {
+		return nil, domain.ItemError{
+			Code:    domain.ErrCodePhotoTooManyPixels,
+			Message: "The photo has too many pixels to be processed",
+		}
+	}
+
+	if err != nil {
+		return nil, domain.ItemError{
+			Code:    domain.ErrCodePhotoTypeUnsupported,
+			Message: fmt.Sprintf("The photo can not be read: %s", err.Error()),
+		}
+	}
+
+	key := domain.PhotoBlobKey(content, contentType)
+
+	exists, err := svc.blobStore.Exists(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("error in blob store: %w", err)
+	}
+
+	if !exists {
+		err := svc.blobStore.Put(ctx, key, bytes.NewReader(content), int64(len(content)), contentType)
+		if err != nil {
+			return nil, fmt.Errorf("error in blob store: %w", err)
+		}
+	}
+
+	photo.Path = svc.blobStore.URL(key)
+	photo.BlobKey = key
+
+	item, err = svc.AddPhoto(ctx, itemID, version, photo, position)
+	if err != nil {
+		return nil, err
+	}
+
+	if svc.processor.CanResize(contentType) {
+		payload := photoVariantsJobPayload{BlobKey: key, ContentType: contentType}
+		if _, err := svc.jobService.EnqueueJob(ctx, domain.JobTypePhotoVariants, payload, photoVariantsAttempts); err != nil {
+			// The photo is already added, and is served in full size until its variants exist.
+			logger.Error(svc, nil, err, "error enqueuing photo variants job")
+		}
+	}
+
+	return item, nil
+}
+
+// DeletePhoto removes a photo. When it was the primary one, the first remaining photo takes its place.
+func (svc *photoService) DeletePhoto(ctx context.Context, itemID uint, version uint,
+	photoID uint) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoService. DeletePhoto()")
+
+	item, err := svc.getItem(ctx, itemID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	index := item.PhotoIndex(photoID)
+	if index < 0 {
+		return nil, errPhotoNotFound
+	}
+
+	item.Photos = append(item.Photos[:index], item.Photos[index+1:]...)
+
+	return svc.itemService.UpdateItem(ctx, *item)
+}
+
+func (svc *photoService) ReorderPhotos(ctx context.Context, itemID uint, version uint, photoIDs []uint,
+	primaryID uint) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoService. ReorderPhotos()")
+
+	item, err := svc.getItem(ctx, itemID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	errInvalidOrder := domain.ItemError{
+		Code:    domain.ErrCodeItemPhotoOrderInvalid,
+		Message: "The order must list every photo of the item exactly once",
+	}
+
+	if len(photoIDs) != len(item.Photos) {
+		return nil, errInvalidOrder
+	}
+
+	photos := make([]domain.Photo, 0, len(photoIDs))
+	seen := make(map[uint]bool, len(photoIDs))
+
+	for _, photoID := range photoIDs {
+		index := item.PhotoIndex(photoID)
+		if index < 0 || seen[photoID] {
+			return nil, errInvalidOrder
+		}
+
+		seen[photoID] = true
+		photos = append(photos, item.Photos[index])
+	}
+
+	if primaryID != 0 {
+		if !seen[primaryID] {
+			return nil, errPhotoNotFound
+		}
+
+		for i := range photos {
+			photos[i].IsPrimary = photos[i].ID == primaryID
+		}
+	}
+
+	item.Photos = photos
+
+	return svc.itemService.UpdateItem(ctx, *item)
+}
+
+func (svc *photoService) JobType() string {
+	return domain.JobTypePhotoVariants
+}
+
+// HandleJob resizes the blob to every domain.PhotoVariant, storing the ones not stored yet,
+// and records the URLs of the variants and the perceptual hash on every photo of the blob.
+func (svc *photoService) HandleJob(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoService. HandleJob()")
+
+	var payload photoVariantsJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("error decoding photo variants job payload: %w", err)
+	}
+
+	blob, _, err := svc.blobStore.Get(ctx, payload.BlobKey)
+	if err != nil {
+		return fmt.Errorf("error in blob store: %w", err)
+	}
+
+	content, err := io.ReadAll(blob)
+	blob.Close()
+	if err != nil {
+		return fmt.Errorf("error in blob store: %w", err)
+	}
+
+	// The hash is one more step after the variants.
+	steps := len(domain.PhotoVariants) + 1
+
+	variants := make(map[string]string, len(domain.PhotoVariants))
+	for i, variant := range domain.PhotoVariants {
+		progress(i*100/steps, "Generating the "+variant.Name+" variant")
+
+		resized, contentType, err := svc.processor.Resize(content, payload.ContentType, variant.Size)
+		if err != nil {
+			return fmt.Errorf("error resizing photo: %w", err)
+		}
+
+		key := domain.PhotoVariantKey(payload.BlobKey, variant.Name, contentType)
+
+		exists, err := svc.blobStore.Exists(ctx, key)
+		if err != nil {
+			return fmt.Errorf("error in blob store: %w", err)
+		}
+
+		if !exists {
+			err := svc.blobStore.Put(ctx, key, bytes.NewReader(resized), int64(len(resized)), contentType)
+			if err != nil {
+				return fmt.Errorf("error in blob store: %w", err)
+			}
+		}
+
+		variants[variant.Name] = svc.blobStore.URL(key)
+	}
+
+	if err := svc.photoRepository.SetPhotoVariants(ctx, payload.BlobKey, variants); err != nil {
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	progress(len(domain.PhotoVariants)*100/steps, "Hashing the photo")
+
+	hash, err := svc.processor.Hash(content, payload.ContentType)
+	if err != nil {
+		return fmt.Errorf("error hashing photo: %w", err)
+	}
+
+	if err := svc.photoRepository.SetPhotoHash(ctx, payload.BlobKey, hash); err != nil {
+		return fmt.Errorf("error in repository: %w", err)
+	}
+
+	return nil
+}
+
+// FindDuplicates searches the photos similar to every hashed photo of the item, and groups
+// them by their item.
+func (svc *photoService) FindDuplicates(ctx context.Context, itemID uint, maxDistance int,
+	limit int) ([]domain.DuplicateItem, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering PhotoService. FindDuplicates()")
+
+	item, err := svc.itemService.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	byItem := make(map[uint]*domain.DuplicateItem)
+
+	for _, photo := range item.Photos {
+		if photo.Hash == nil {
+			continue
+		}
+
+		similar, err := svc.photoRepository.FindSimilarPhotos(ctx, itemID, *photo.Hash, maxDistance)
+		if err != nil {
+			return nil, fmt.Errorf("error in repository: %w", err)
+		}
+
+		for _, match := range similar {
+			distance := domain.PhotoHashDistance(*photo.Hash, *match.Hash)
+
+			duplicate, ok := byItem[match.ItemID]
+			if !ok {
+				duplicate = &domain.DuplicateItem{ItemID: match.ItemID, Distance: distance}
+				byItem[match.ItemID] = duplicate
+			}
+
+			if distance < duplicate.Distance {
+				duplicate.Distance = distance
+			}
+
+			duplicate.Matches = append(duplicate.Matches, domain.PhotoMatch{
+				PhotoID:        match.ID,
+				MatchedPhotoID: photo.ID,
+				Distance:       distance,
+			})
+		}
+	}
+
+	duplicates := make([]domain.DuplicateItem, 0, len(byItem))
+	for _, duplicate := range byItem {
+		sort.SliceStable(duplicate.Matches, func(i, j int) bool {
+			return duplicate.Matches[i].Distance < duplicate.Matches[j].Distance
+		})
+
+		duplicates = append(duplicates, *duplicate)
+	}
+
+	domain.SortDuplicateItems(duplicates)
+
+	if len(duplicates) > limit {
+		duplicates = duplicates[:limit]
+	}
+
+	return duplicates, nil
+}
+
+// getItem reads the item to change, failing as ItemService.PatchItem does when its version is
+// not the expected one. The update that follows is conditional on the version read.
+func (svc *photoService) getItem(ctx context.Context, itemID uint, version uint) (*domain.Item, error) {
+	item, err := svc.itemService.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && item.Version != version {
+		return nil, domain.ConflictError{
+			Message: "The item has been modified by another request",
+		}
+	}
+
+	return item, nil
+}
+
+// checkPhotosLimit fails when the item can not get one more photo.
+func checkPhotosLimit(item *domain.Item) error {
+	if len(item.Photos) < domain.MaxPhotosPerItem {
+		return nil
+	}
+
+	return domain.ItemError{
+		Code:    domain.ErrCodeItemPhotosLimit,
+		Message: fmt.Sprintf("An item can have at most %d photos", domain.MaxPhotosPerItem),
+		Args:    []interface{}{domain.MaxPhotosPerItem},
+	}
+}
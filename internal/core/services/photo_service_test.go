@@ -0,0 +1,389 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"reflect"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/blob"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/imaging"
+)
+
+const testBlobURL = "http://localhost:8080/v1/blobs"
+
+// unusedPhotoRepository is the PhotoRepository of the tests that only change the photos of
+// items, which go through the item service.
+type unusedPhotoRepository struct {
+	ports.PhotoRepository
+}
+
+func newTestPhotoService(t *testing.T) (*photoService, ports.ItemService, *jobQueue) {
+	itemSvc, _ := newTestItemService(t)
+	jobs := &jobQueue{}
+
+	blobStore, err := blob.NewLocalStore(t.TempDir(), testBlobURL)
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+
+	svc, err := NewPhotoService(itemSvc, unusedPhotoRepository{}, blobStore,
+		imaging.NewPhotoProcessor(imaging.Config{MaxPixels: 1 << 20}), jobs)
+	if err != nil {
+		t.Fatalf("NewPhotoService() error = %v", err)
+	}
+
+	return svc.(*photoService), itemSvc, jobs
+}
+
+// createItemWithPhotos creates an item whose photos are at the paths /1.jpg to /n.jpg, the first
+// one being the primary one, and reads it back with the IDs of its photos.
+func createItemWithPhotos(t *testing.T, itemSvc ports.ItemService, n int) *domain.Item {
+	t.Helper()
+
+	photos := make([]domain.Photo, n)
+	for i := range photos {
+		photos[i] = domain.Photo{Path: fmt.Sprintf("/%d.jpg", i+1)}
+	}
+
+	item, err := itemSvc.CreateItem(context.Background(), domain.Item{Code: "A1", Title: "Item", Photos: photos})
+	if err != nil {
+		t.Fatalf("CreateItem() error = %v", err)
+	}
+
+	item, err = itemSvc.GetItemByID(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("GetItemByID() error = %v", err)
+	}
+
+	return item
+}
+
+// photoPaths returns the paths of the photos in order, and the path of the primary one.
+func photoPaths(t *testing.T, photos []domain.Photo) ([]string, string) {
+	t.Helper()
+
+	paths := make([]string, 0, len(photos))
+	primary := ""
+
+	for i, photo := range photos {
+		if photo.Position != i {
+			t.Errorf("photo %s position = %d, want %d", photo.Path, photo.Position, i)
+		}
+
+		if photo.IsPrimary {
+			if primary != "" {
+				t.Errorf("photos %s and %s are both primary", primary, photo.Path)
+			}
+			primary = photo.Path
+		}
+
+		paths = append(paths, photo.Path)
+	}
+
+	return paths, primary
+}
+
+func TestAddPhoto(t *testing.T) {
+	position := func(value int) *int { return &value }
+
+	tests := []struct {
+		name        string
+		photos      int
+		version     uint
+		photo       domain.Photo
+		position    *int
+		wantPaths   []string
+		wantPrimary string
+		wantCode    string
+		wantErr     error
+	}{
+		{
+			name:        "last by default",
+			photos:      2,
+			photo:       domain.Photo{Path: "/new.jpg"},
+			wantPaths:   []string{"/1.jpg", "/2.jpg", "/new.jpg"},
+			wantPrimary: "/1.jpg",
+		},
+		{
+			name:        "at a position",
+			photos:      2,
+			photo:       domain.Photo{Path: "/new.jpg"},
+			position:    position(0),
+			wantPaths:   []string{"/new.jpg", "/1.jpg", "/2.jpg"},
+			wantPrimary: "/1.jpg",
+		},
+		{
+			name:        "position past the last photo",
+			photos:      2,
+			photo:       domain.Photo{Path: "/new.jpg"},
+			position:    position(7),
+			wantPaths:   []string{"/1.jpg", "/2.jpg", "/new.jpg"},
+			wantPrimary: "/1.jpg",
+		},
+		{
+			name:        "primary photo",
+			photos:      2,
+			photo:       domain.Photo{Path: "/new.jpg", IsPrimary: true},
+			position:    position(1),
+			wantPaths:   []string{"/1.jpg", "/new.jpg", "/2.jpg"},
+			wantPrimary: "/new.jpg",
+		},
+		{
+			name:     "limit of photos",
+			photos:   domain.MaxPhotosPerItem,
+			photo:    domain.Photo{Path: "/new.jpg"},
+			wantCode: domain.ErrCodeItemPhotosLimit,
+		},
+		{
+			name:    "stale version",
+			photos:  1,
+			version: 7,
+			photo:   domain.Photo{Path: "/new.jpg"},
+			wantErr: domain.ConflictError{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc, itemSvc, _ := newTestPhotoService(t)
+			item := createItemWithPhotos(t, itemSvc, test.photos)
+
+			got, err := svc.AddPhoto(context.Background(), item.ID, test.version, test.photo, test.position)
+			checkPhotoChange(t, got, err, test.wantPaths, test.wantPrimary, test.wantCode, test.wantErr)
+		})
+	}
+}
+
+func TestDeletePhoto(t *testing.T) {
+	tests := []struct {
+		name        string
+		delete      int
+		wantPaths   []string
+		wantPrimary string
+		wantErr     error
+	}{
+		{
+			name:        "primary photo",
+			delete:      0,
+			wantPaths:   []string{"/2.jpg", "/3.jpg"},
+			wantPrimary: "/2.jpg",
+		},
+		{
+			name:        "other photo",
+			delete:      1,
+			wantPaths:   []string{"/1.jpg", "/3.jpg"},
+			wantPrimary: "/1.jpg",
+		},
+		{
+			name:    "photo of another item",
+			delete:  -1,
+			wantErr: domain.ResourceNotFoundError{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc, itemSvc, _ := newTestPhotoService(t)
+			item := createItemWithPhotos(t, itemSvc, 3)
+
+			photoID := uint(1000)
+			if test.delete >= 0 {
+				photoID = item.Photos[test.delete].ID
+			}
+
+			got, err := svc.DeletePhoto(context.Background(), item.ID, item.Version, photoID)
+			checkPhotoChange(t, got, err, test.wantPaths, test.wantPrimary, "", test.wantErr)
+		})
+	}
+}
+
+func TestReorderPhotos(t *testing.T) {
+	// The photos are given by their index in the item, or as one of these.
+	const (
+		keepPrimary = -1
+		otherPhoto  = -2
+	)
+
+	tests := []struct {
+		name        string
+		order       []int
+		primary     int
+		wantPaths   []string
+		wantPrimary string
+		wantCode    string
+		wantErr     error
+	}{
+		{
+			name:        "primary photo kept",
+			order:       []int{2, 0, 1},
+			primary:     keepPrimary,
+			wantPaths:   []string{"/3.jpg", "/1.jpg", "/2.jpg"},
+			wantPrimary: "/1.jpg",
+		},
+		{
+			name:        "primary photo chosen",
+			order:       []int{1, 2, 0},
+			primary:     2,
+			wantPaths:   []string{"/2.jpg", "/3.jpg", "/1.jpg"},
+			wantPrimary: "/3.jpg",
+		},
+		{
+			name:     "photo missing",
+			order:    []int{1, 0},
+			primary:  keepPrimary,
+			wantCode: domain.ErrCodeItemPhotoOrderInvalid,
+		},
+		{
+			name:     "photo repeated",
+			order:    []int{1, 0, 1},
+			primary:  keepPrimary,
+			wantCode: domain.ErrCodeItemPhotoOrderInvalid,
+		},
+		{
+			name:     "photo of another item",
+			order:    []int{1, 0, otherPhoto},
+			primary:  keepPrimary,
+			wantCode: domain.ErrCodeItemPhotoOrderInvalid,
+		},
+		{
+			name:    "primary photo of another item",
+			order:   []int{0, 1, 2},
+			primary: otherPhoto,
+			wantErr: domain.ResourceNotFoundError{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc, itemSvc, _ := newTestPhotoService(t)
+			item := createItemWithPhotos(t, itemSvc, 3)
+
+			photoID := func(index int) uint {
+				switch index {
+				case keepPrimary:
+					return 0
+				case otherPhoto:
+					return 1000
+				}
+				return item.Photos[index].ID
+			}
+
+			order := make([]uint, 0, len(test.order))
+			for _, index := range test.order {
+				order = append(order, photoID(index))
+			}
+
+			got, err := svc.ReorderPhotos(context.Background(), item.ID, item.Version, order, photoID(test.primary))
+			checkPhotoChange(t, got, err, test.wantPaths, test.wantPrimary, test.wantCode, test.wantErr)
+		})
+	}
+}
+
+// TestUpdateItemKeepsThePhotoArrangement checks that a full update, whose photos only carry
+// their paths, keeps the order and the primary photo chosen through the photo endpoints.
+func TestUpdateItemKeepsThePhotoArrangement(t *testing.T) {
+	ctx := context.Background()
+	svc, itemSvc, _ := newTestPhotoService(t)
+	item := createItemWithPhotos(t, itemSvc, 3)
+
+	reordered, err := svc.ReorderPhotos(ctx, item.ID, item.Version,
+		[]uint{item.Photos[2].ID, item.Photos[0].ID, item.Photos[1].ID}, item.Photos[1].ID)
+	if err != nil {
+		t.Fatalf("ReorderPhotos() error = %v", err)
+	}
+
+	update := *reordered
+	update.Photos = []domain.Photo{{Path: "/1.jpg"}, {Path: "/new.jpg"}, {Path: "/2.jpg"}, {Path: "/3.jpg"}}
+
+	updated, err := itemSvc.UpdateItem(ctx, update)
+	if err != nil {
+		t.Fatalf("UpdateItem() error = %v", err)
+	}
+
+	want := []string{"/3.jpg", "/1.jpg", "/2.jpg", "/new.jpg"}
+
+	paths, primary := photoPaths(t, updated.Photos)
+	if !reflect.DeepEqual(paths, want) || primary != "/2.jpg" {
+		t.Errorf("photos = %v with %s primary, want %v with /2.jpg primary", paths, primary, want)
+	}
+}
+
+func TestUploadPhoto(t *testing.T) {
+	ctx := context.Background()
+	svc, itemSvc, jobs := newTestPhotoService(t)
+	item := createItemWithPhotos(t, itemSvc, 1)
+
+	var content bytes.Buffer
+	if err := jpeg.Encode(&content, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+
+	_, err := svc.UploadPhoto(ctx, item.ID, 0, []byte("not a photo"), domain.Photo{}, nil)
+
+	var itemErr domain.ItemError
+	if !errors.As(err, &itemErr) || itemErr.Code != domain.ErrCodePhotoTypeUnsupported {
+		t.Fatalf("UploadPhoto() of a text error = %v, want %s", err, domain.ErrCodePhotoTypeUnsupported)
+	}
+
+	var keys []string
+	for i := 0; i < 2; i++ {
+		updated, err := svc.UploadPhoto(ctx, item.ID, 0, content.Bytes(), domain.Photo{}, nil)
+		if err != nil {
+			t.Fatalf("UploadPhoto() error = %v", err)
+		}
+
+		uploaded := updated.Photos[len(updated.Photos)-1]
+		if uploaded.BlobKey == "" || uploaded.Path != testBlobURL+"/"+uploaded.BlobKey {
+			t.Errorf("uploaded photo = %s %s, want the URL of its blob", uploaded.Path, uploaded.BlobKey)
+		}
+
+		keys = append(keys, uploaded.BlobKey)
+	}
+
+	if keys[0] != keys[1] {
+		t.Errorf("blob keys of the same content = %v, want the same key", keys)
+	}
+
+	count, err := jobs.CountJobs(ctx, domain.JobTypePhotoVariants, domain.JobStatusQueued)
+	if err != nil || count != 2 {
+		t.Errorf("photo variants jobs = %d, %v, want one per upload", count, err)
+	}
+}
+
+// checkPhotoChange checks the photos of the item a photo change returned, or its error: an item
+// error of wantCode, or an error of the type of wantErr.
+func checkPhotoChange(t *testing.T, item *domain.Item, err error, wantPaths []string, wantPrimary string,
+	wantCode string, wantErr error) {
+	t.Helper()
+
+	if wantCode != "" {
+		var itemErr domain.ItemError
+		if !errors.As(err, &itemErr) || itemErr.Code != wantCode {
+			t.Errorf("error = %v, want %s", err, wantCode)
+		}
+		return
+	}
+
+	if wantErr != nil {
+		if target := reflect.New(reflect.TypeOf(wantErr)).Interface(); !errors.As(err, target) {
+			t.Errorf("error = %v, want a %T", err, wantErr)
+		}
+		return
+	}
+
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	paths, primary := photoPaths(t, item.Photos)
+	if !reflect.DeepEqual(paths, wantPaths) || primary != wantPrimary {
+		t.Errorf("photos = %v with %s primary, want %v with %s primary", paths, primary, wantPaths, wantPrimary)
+	}
+}
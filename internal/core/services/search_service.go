@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type searchService struct {
+	itemRepository ports.ItemRepository
+	searchIndex    ports.ItemSearchIndex
+}
+
+func NewSearchService(itemRepository ports.ItemRepository, searchIndex ports.ItemSearchIndex) (ports.SearchService, error) {
+	if itemRepository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if searchIndex == nil {
+		return nil, fmt.Errorf("search index cannot be nil")
+	}
+
+	return &searchService{
+		itemRepository: itemRepository,
+		searchIndex:    searchIndex,
+	}, nil
+}
+
+// SearchItems loads the items of the hits with a single read. A hit whose item was deleted
+// after the index was read is dropped from the page.
+func (svc *searchService) SearchItems(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering SearchService. SearchItems()")
+
+	result, err := svc.searchIndex.Search(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error in search index: %w", err)
+	}
+
+	ids := make([]uint, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ItemID)
+	}
+
+	items, err := svc.itemRepository.GetItemsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error in repository: %w", err)
+	}
+
+	byID := make(map[uint]*domain.Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	hits := make([]domain.SearchHit, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		if hit.Item = byID[hit.ItemID]; hit.Item != nil {
+			hits = append(hits, hit)
+		}
+	}
+
+	result.Hits = hits
+
+	return result, nil
+}
+
+func (svc *searchService) ReindexItems(ctx context.Context) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering SearchService. ReindexItems()")
+
+	err := svc.itemRepository.StreamItems(ctx, domain.ItemFilter{}, func(item *domain.Item) error {
+		return svc.searchIndex.IndexItem(ctx, item)
+	})
+	if err != nil {
+		return fmt.Errorf("error reindexing items: %w", err)
+	}
+
+	return nil
+}
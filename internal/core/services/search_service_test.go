@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/memory"
+)
+
+// newTestSearchService returns an item service that keeps a memory search index in sync, and
+// the search service of the same index.
+func newTestSearchService(t *testing.T) (ports.ItemService, ports.SearchService, *itemStore, ports.ItemSearchIndex) {
+	itemSvc, store := newTestItemService(t)
+	index := memory.NewItemSearchIndex()
+
+	indexed, err := NewIndexedItemService(itemSvc, index)
+	if err != nil {
+		t.Fatalf("NewIndexedItemService() error = %v", err)
+	}
+
+	searchSvc, err := NewSearchService(store, index)
+	if err != nil {
+		t.Fatalf("NewSearchService() error = %v", err)
+	}
+
+	return indexed, searchSvc, store, index
+}
+
+func searchedCodes(t *testing.T, searchSvc ports.SearchService, query domain.SearchQuery) []string {
+	t.Helper()
+
+	result, err := searchSvc.SearchItems(context.Background(), query)
+	if err != nil {
+		t.Fatalf("SearchItems(%q) error = %v", query.Text, err)
+	}
+
+	codes := []string{}
+	for _, hit := range result.Hits {
+		codes = append(codes, hit.Item.Code)
+	}
+
+	return codes
+}
+
+func TestSearchItemsFollowsTheWrites(t *testing.T) {
+	ctx := context.Background()
+	itemSvc, searchSvc, _, _ := newTestSearchService(t)
+	photos := []domain.Photo{{Path: "https://images.example.com/photo.jpg"}}
+
+	lamp, err := itemSvc.CreateItem(ctx, domain.Item{Code: "L-1", Title: "Lámpara de escritorio",
+		Description: "Con brazo articulado", Stock: 1, Photos: photos})
+	if err != nil {
+		t.Fatalf("CreateItem() error = %v", err)
+	}
+
+	chair, err := itemSvc.CreateItem(ctx, domain.Item{Code: "C-1", Title: "Silla de escritorio",
+		Description: "Con ruedas", Stock: 1, Photos: photos})
+	if err != nil {
+		t.Fatalf("CreateItem() error = %v", err)
+	}
+
+	steps := []struct {
+		name  string
+		write func() error
+		text  string
+		want  []string
+	}{
+		{
+			name: "created items",
+			text: "escritorio",
+			want: []string{"C-1", "L-1"},
+		},
+		{
+			name: "accents and plurals",
+			text: "lamparas",
+			want: []string{"L-1"},
+		},
+		{
+			name: "updated item",
+			write: func() error {
+				chair.Description = "Con ruedas y lámpara de lectura"
+				_, err := itemSvc.UpdateItem(ctx, *chair)
+				return err
+			},
+			text: "lampara",
+			want: []string{"L-1", "C-1"},
+		},
+		{
+			name: "patched item",
+			write: func() error {
+				title := "Lámpara de pie"
+				_, err := itemSvc.PatchItem(ctx, lamp.ID, 0, domain.ItemPatch{Title: &title})
+				return err
+			},
+			text: "escritorio",
+			want: []string{"C-1"},
+		},
+		{
+			name: "deleted item",
+			write: func() error {
+				return itemSvc.DeleteItem(ctx, chair.ID, 0)
+			},
+			text: "lampara",
+			want: []string{"L-1"},
+		},
+		{
+			name: "failed write",
+			write: func() error {
+				if _, err := itemSvc.CreateItem(ctx, domain.Item{Code: "D-1", Title: "Escritorio"}); err == nil {
+					return errors.New("created an item without photos")
+				}
+				return nil
+			},
+			text: "escritorio",
+			want: []string{},
+		},
+	}
+
+	for _, step := range steps {
+		if step.write != nil {
+			if err := step.write(); err != nil {
+				t.Fatalf("%s: write error = %v", step.name, err)
+			}
+		}
+
+		got := searchedCodes(t, searchSvc, domain.SearchQuery{Text: step.text, Limit: 10})
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: SearchItems(%q) = %v, want %v", step.name, step.text, got, step.want)
+		}
+	}
+}
+
+func TestSearchItemsDropsTheHitsOfDeletedItems(t *testing.T) {
+	ctx := context.Background()
+	_, searchSvc, store, index := newTestSearchService(t)
+
+	for _, item := range []domain.Item{
+		{Code: "L-1", Title: "Lámpara de escritorio"},
+		{Code: "L-2", Title: "Lámpara de pie"},
+	} {
+		if err := index.IndexItem(ctx, store.put(item)); err != nil {
+			t.Fatalf("IndexItem() error = %v", err)
+		}
+	}
+
+	// The item is deleted behind the index, as by another instance of the API.
+	if err := store.DeleteItem(ctx, 1, 0); err != nil {
+		t.Fatalf("DeleteItem() error = %v", err)
+	}
+
+	result, err := searchSvc.SearchItems(ctx, domain.SearchQuery{Text: "lampara", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchItems() error = %v", err)
+	}
+
+	if len(result.Hits) != 1 || result.Hits[0].Item.Code != "L-2" {
+		t.Fatalf("SearchItems() hits = %v, want only L-2", result.Hits)
+	}
+
+	if got := result.Hits[0].Highlights["title"]; got != "<em>Lámpara</em> de pie" {
+		t.Errorf("SearchItems() title highlight = %q, want %q", got, "<em>Lámpara</em> de pie")
+	}
+}
+
+func TestReindexItems(t *testing.T) {
+	ctx := context.Background()
+	_, searchSvc, store, _ := newTestSearchService(t)
+
+	store.put(domain.Item{Code: "L-1", Title: "Lámpara de escritorio"})
+	store.put(domain.Item{Code: "C-1", Title: "Silla"})
+
+	if got := searchedCodes(t, searchSvc, domain.SearchQuery{Text: "lampara", Limit: 10}); len(got) != 0 {
+		t.Fatalf("SearchItems() before the reindex = %v, want no hits", got)
+	}
+
+	if err := searchSvc.ReindexItems(ctx); err != nil {
+		t.Fatalf("ReindexItems() error = %v", err)
+	}
+
+	got := searchedCodes(t, searchSvc, domain.SearchQuery{Text: "lampara", Limit: 10})
+	if !reflect.DeepEqual(got, []string{"L-1"}) {
+		t.Errorf("SearchItems() after the reindex = %v, want [L-1]", got)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type suggestService struct {
+	itemRepository ports.ItemRepository
+	suggester      ports.ItemSuggester
+}
+
+func NewSuggestService(itemRepository ports.ItemRepository, suggester ports.ItemSuggester) (ports.SuggestService, error) {
+	if itemRepository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if suggester == nil {
+		return nil, fmt.Errorf("suggester cannot be nil")
+	}
+
+	return &suggestService{
+		itemRepository: itemRepository,
+		suggester:      suggester,
+	}, nil
+}
+
+func (svc *suggestService) SuggestItems(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering SuggestService. SuggestItems()")
+
+	suggestions, err := svc.suggester.Suggest(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error in suggester: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+func (svc *suggestService) RebuildSuggestions(ctx context.Context) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(svc, nil, "Entering SuggestService. RebuildSuggestions()")
+
+	err := svc.suggester.Rebuild(ctx, func(fn func(*domain.Item) error) error {
+		return svc.itemRepository.StreamItems(ctx, domain.ItemFilter{}, fn)
+	})
+	if err != nil {
+		return fmt.Errorf("error rebuilding suggestions: %w", err)
+	}
+
+	return nil
+}
+
+func (svc *suggestService) RefreshSuggestions(ctx context.Context, interval time.Duration) {
+	logger := marketcontext.Logger(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := svc.RebuildSuggestions(ctx); err != nil && ctx.Err() == nil {
+			logger.Error(svc, nil, err, "error refreshing suggestions")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package blob
+
+import "os"
+
+var (
+	blobStore         = "BLOB_STORE"
+	blobLocalDir      = "BLOB_LOCAL_DIR"
+	blobBaseURL       = "BLOB_BASE_URL"
+	s3Endpoint        = "S3_ENDPOINT"
+	s3Region          = "S3_REGION"
+	s3Bucket          = "S3_BUCKET"
+	s3AccessKeyID     = "S3_ACCESS_KEY_ID"
+	s3SecretAccessKey = "S3_SECRET_ACCESS_KEY"
+)
+
+const (
+	StoreLocal = "local"
+	StoreS3    = "s3"
+
+	defaultLocalDir = "data/blobs"
+	defaultBaseURL  = "http://localhost:8080/v1/blobs"
+	defaultS3Region = "us-east-1"
+)
+
+type Config struct {
+	// Store is where the blobs are kept: local writes them under LocalDir, s3 sends them to
+	// an S3 compatible service, e.g. AWS S3 or MinIO.
+	Store    string
+	LocalDir string
+	// BaseURL prefixes the keys in the blob URLs. It defaults to the API blobs endpoint for the
+	// local store and to the bucket URL for s3.
+	BaseURL string
+	S3      S3Config
+}
+
+type S3Config struct {
+	// Endpoint is the scheme and host of the service, the bucket being addressed by path.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+func LoadConfig() Config {
+	config := Config{
+		Store:    os.Getenv(blobStore),
+		LocalDir: os.Getenv(blobLocalDir),
+		BaseURL:  os.Getenv(blobBaseURL),
+		S3: S3Config{
+			Endpoint:        os.Getenv(s3Endpoint),
+			Region:          os.Getenv(s3Region),
+			Bucket:          os.Getenv(s3Bucket),
+			AccessKeyID:     os.Getenv(s3AccessKeyID),
+			SecretAccessKey: os.Getenv(s3SecretAccessKey),
+		},
+	}
+
+	if config.Store != StoreS3 {
+		config.Store = StoreLocal
+	}
+
+	if config.LocalDir == "" {
+		config.LocalDir = defaultLocalDir
+	}
+
+	if config.S3.Region == "" {
+		config.S3.Region = defaultS3Region
+	}
+
+	if config.BaseURL == "" && config.Store == StoreLocal {
+		config.BaseURL = defaultBaseURL
+	}
+
+	return config
+}
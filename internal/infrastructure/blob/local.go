@@ -0,0 +1,168 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+var errBlobNotFound = domain.ResourceNotFoundError{
+	Message: "Blob not found",
+}
+
+// localStore keeps the blobs as files, spread over two levels of directories named after the
+// first characters of the key so no directory grows too large.
+type localStore struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStore(dir, baseURL string) (ports.BlobStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("blob directory cannot be empty")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating blob directory: %w", err)
+	}
+
+	return &localStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Put writes the content to a temporary file that is renamed once complete, so a reader
+// never sees a partial blob.
+func (store *localStore) Put(ctx context.Context, key string, content io.Reader, size int64,
+	contentType string) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering LocalStore. Put()")
+
+	if !ValidKey(key) {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+
+	name := store.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("error creating blob directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("error creating blob: %w", err)
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck
+
+	written, err := io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("error writing blob: %w", err)
+	}
+
+	if written != size {
+		return fmt.Errorf("error writing blob: wrote %d bytes, expected %d", written, size)
+	}
+
+	if err := os.Rename(file.Name(), name); err != nil {
+		return fmt.Errorf("error writing blob: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the content type of the extension of the key, which the local store does not keep.
+func (store *localStore) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering LocalStore. Get()")
+
+	if !ValidKey(key) {
+		return nil, "", errBlobNotFound
+	}
+
+	file, err := os.Open(store.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", errBlobNotFound
+		}
+
+		return nil, "", fmt.Errorf("error reading blob: %w", err)
+	}
+
+	return file, contentTypeOf(key), nil
+}
+
+func (store *localStore) Exists(ctx context.Context, key string) (bool, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering LocalStore. Exists()")
+
+	if !ValidKey(key) {
+		return false, nil
+	}
+
+	_, err := os.Stat(store.path(key))
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("error reading blob: %w", err)
+}
+
+func (store *localStore) URL(key string) string {
+	return store.baseURL + "/" + key
+}
+
+func (store *localStore) path(key string) string {
+	return filepath.Join(store.dir, key[:2], key[2:4], key)
+}
+
+// ValidKey reports whether key can be used as a blob key: at least four characters among
+// lowercase letters, digits, dots, dashes and underscores, not starting with a dot. Keys are
+// never paths, so they can not escape the directory or bucket of the store.
+func ValidKey(key string) bool {
+	if len(key) < 4 || len(key) > 255 || key[0] == '.' {
+		return false
+	}
+
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '.' && r != '-' && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// contentTypeOf returns the content type matching the extension of a key.
+func contentTypeOf(key string) string {
+	ext := path.Ext(key)
+	for contentType, photoExt := range domain.PhotoContentTypes {
+		if photoExt == ext {
+			return contentType
+		}
+	}
+
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
+}
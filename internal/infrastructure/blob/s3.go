@@ -0,0 +1,220 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	// unsignedPayload lets the uploads be streamed instead of hashed before being sent.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// emptyPayloadHash is the SHA-256 of the empty body of the GET and HEAD requests.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Store talks to an S3 compatible service through its REST API, signing the requests with
+// AWS Signature Version 4 and addressing the bucket by path, which every implementation supports.
+type s3Store struct {
+	config  S3Config
+	baseURL string
+	client  *http.Client
+}
+
+func NewS3Store(config S3Config, baseURL string, client *http.Client) (ports.BlobStore, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 endpoint and bucket cannot be empty")
+	}
+
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 credentials cannot be empty")
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("http client cannot be nil")
+	}
+
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if baseURL == "" {
+		baseURL = config.Endpoint + "/" + config.Bucket
+	}
+
+	return &s3Store{
+		config:  config,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}, nil
+}
+
+func (store *s3Store) Put(ctx context.Context, key string, content io.Reader, size int64,
+	contentType string) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering S3Store. Put()")
+
+	if !ValidKey(key) {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, store.objectURL(key), content)
+	if err != nil {
+		return fmt.Errorf("error creating s3 request: %w", err)
+	}
+
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := store.do(req, unsignedPayload)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(req, res)
+	}
+
+	return nil
+}
+
+func (store *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering S3Store. Get()")
+
+	if !ValidKey(key) {
+		return nil, "", errBlobNotFound
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, store.objectURL(key), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating s3 request: %w", err)
+	}
+
+	res, err := store.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		contentType := res.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = contentTypeOf(key)
+		}
+
+		return res.Body, contentType, nil
+	case http.StatusNotFound:
+		res.Body.Close()
+		return nil, "", errBlobNotFound
+	default:
+		defer res.Body.Close()
+		return nil, "", responseError(req, res)
+	}
+}
+
+func (store *s3Store) Exists(ctx context.Context, key string) (bool, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering S3Store. Exists()")
+
+	if !ValidKey(key) {
+		return false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, store.objectURL(key), nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating s3 request: %w", err)
+	}
+
+	res, err := store.do(req, emptyPayloadHash)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, responseError(req, res)
+	}
+}
+
+func (store *s3Store) URL(key string) string {
+	return store.baseURL + "/" + key
+}
+
+func (store *s3Store) objectURL(key string) string {
+	return store.config.Endpoint + "/" + store.config.Bucket + "/" + key
+}
+
+func (store *s3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	store.sign(req, payloadHash, time.Now().UTC())
+
+	res, err := store.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling s3: %w", err)
+	}
+
+	return res, nil
+}
+
+// sign adds the Signature Version 4 headers to a request without query params, signing the
+// host and the x-amz headers.
+func (store *s3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + store.config.Region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+store.config.SecretAccessKey), date)
+	key = hmacSHA256(key, store.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		store.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+// responseError reads the start of an error response, which holds the S3 error code.
+func responseError(req *http.Request, res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+	return fmt.Errorf("s3 %s %s failed with %s: %s", req.Method, req.URL.Path, res.Status,
+		strings.TrimSpace(string(body)))
+}
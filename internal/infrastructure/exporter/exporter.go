@@ -0,0 +1,124 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+)
+
+// flushRows is how often the buffered rows are written out, so a stream reaches the client steadily.
+const flushRows = 100
+
+// csvHeader has the columns of an import file, plus the read-only ones the importer ignores.
+var csvHeader = []string{"id", "code", "title", "description", "price", "stock", "itemType", "leader",
+	"leaderLevel", "photos", "status", "version", "createdAt", "updatedAt"}
+
+// ItemWriter writes items in an export format. Close flushes the pending rows, it does not
+// close the underlying writer.
+type ItemWriter interface {
+	Write(item *domain.Item) error
+	Close() error
+}
+
+func NewItemWriter(format string, w io.Writer) (ItemWriter, error) {
+	switch format {
+	case domain.ExportFormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case domain.ExportFormatJSONL:
+		buffered := bufio.NewWriter(w)
+		return &jsonlWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	rows   int
+}
+
+func (w *csvWriter) Write(item *domain.Item) error {
+	if w.rows == 0 {
+		if err := w.writer.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	photos := make([]string, 0, len(item.Photos))
+	for _, photo := range item.Photos {
+		photos = append(photos, photo.Path)
+	}
+
+	record := []string{
+		strconv.FormatUint(uint64(item.ID), 10),
+		item.Code,
+		item.Title,
+		item.Description,
+		strconv.Itoa(item.Price),
+		strconv.Itoa(item.Stock),
+		item.ItemType,
+		strconv.FormatBool(item.Leader),
+		item.LeaderLevel,
+		strings.Join(photos, "|"),
+		item.Status,
+		strconv.FormatUint(uint64(item.Version), 10),
+		item.CreatedAt.Format(time.RFC3339),
+		item.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if err := w.writer.Write(record); err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%flushRows == 0 {
+		w.writer.Flush()
+		return w.writer.Error()
+	}
+
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	if w.rows == 0 {
+		if err := w.writer.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+// jsonlWriter writes every item as the JSON of the API responses, one item per line.
+type jsonlWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+	rows     int
+}
+
+func (w *jsonlWriter) Write(item *domain.Item) error {
+	if err := w.encoder.Encode(dto.CreateItemResponse(item)); err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%flushRows == 0 {
+		return w.buffered.Flush()
+	}
+
+	return nil
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.buffered.Flush()
+}
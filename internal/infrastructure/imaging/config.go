@@ -0,0 +1,28 @@
+package imaging
+
+import (
+	"os"
+	"strconv"
+)
+
+var photoMaxPixels = "PHOTO_MAX_PIXELS"
+
+// defaultMaxPixels lets through the photos of any phone camera, about 160 MB once decoded.
+const defaultMaxPixels = 40_000_000
+
+type Config struct {
+	// MaxPixels is the largest width times height of a photo the processor decodes, checked
+	// on the header of the photo before its pixels are allocated.
+	MaxPixels int64
+}
+
+func LoadConfig() Config {
+	maxPixels, err := strconv.ParseInt(os.Getenv(photoMaxPixels), 10, 64)
+	if err != nil || maxPixels <= 0 {
+		maxPixels = defaultMaxPixels
+	}
+
+	return Config{
+		MaxPixels: maxPixels,
+	}
+}
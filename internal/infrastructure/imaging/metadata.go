@@ -0,0 +1,219 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformed = errors.New("malformed photo")
+
+// jpegSegments splits a JPEG into its marker segments up to the start of the scan, which is
+// returned in rest with the compressed data that follows. Every segment keeps its marker.
+func jpegSegments(content []byte) (segments [][]byte, rest []byte, err error) {
+	if len(content) < 2 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, nil, errMalformed
+	}
+
+	i := 2
+	for i+1 < len(content) {
+		if content[i] != 0xFF {
+			return nil, nil, errMalformed
+		}
+
+		marker := content[i+1]
+		switch {
+		case marker == 0xFF:
+			// A fill byte before the marker.
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// Start of scan or end of image.
+			return segments, content[i:], nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// Markers without a length.
+			segments = append(segments, content[i:i+2])
+			i += 2
+			continue
+		}
+
+		if i+4 > len(content) {
+			return nil, nil, errMalformed
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:i+4]))
+		if end > len(content) {
+			return nil, nil, errMalformed
+		}
+
+		segments = append(segments, content[i:end])
+		i = end
+	}
+
+	return nil, nil, errMalformed
+}
+
+// stripJPEG drops the comments and the application segments, which hold the EXIF, XMP, IPTC
+// and maker metadata, except for the ones needed to display the photo: APP0 (JFIF), APP2
+// (the ICC color profile) and APP14 (the Adobe color transform).
+func stripJPEG(content []byte) ([]byte, error) {
+	segments, rest, err := jpegSegments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(content))
+	out = append(out, 0xFF, 0xD8)
+
+	for _, segment := range segments {
+		if keepJPEGSegment(segment[1]) {
+			out = append(out, segment...)
+		}
+	}
+
+	return append(out, rest...), nil
+}
+
+func keepJPEGSegment(marker byte) bool {
+	switch {
+	case marker == 0xFE:
+		// COM
+		return false
+	case marker >= 0xE0 && marker <= 0xEF:
+		return marker == 0xE0 || marker == 0xE2 || marker == 0xEE
+	default:
+		return true
+	}
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG, from 1 to 8, 1 being upright and
+// also returned when the photo has no readable orientation.
+func jpegOrientation(content []byte) int {
+	segments, _, err := jpegSegments(content)
+	if err != nil {
+		return 1
+	}
+
+	for _, segment := range segments {
+		if segment[1] == 0xE1 && len(segment) > 10 && bytes.Equal(segment[4:10], []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[10:])
+		}
+	}
+
+	return 1
+}
+
+// exifOrientation reads the orientation tag of the first IFD of a TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for k := 0; k < count; k++ {
+		entry := offset + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// stripPNG drops the eXIf chunks.
+func stripPNG(content []byte) ([]byte, error) {
+	const signatureLength = 8
+	if len(content) < signatureLength {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(content))
+	out = append(out, content[:signatureLength]...)
+
+	for i := signatureLength; i < len(content); {
+		if i+8 > len(content) {
+			return nil, errMalformed
+		}
+
+		// Length, type, data and CRC.
+		end := i + 12 + int(binary.BigEndian.Uint32(content[i:i+4]))
+		if end > len(content) || end < i {
+			return nil, errMalformed
+		}
+
+		if string(content[i+4:i+8]) != "eXIf" {
+			out = append(out, content[i:end]...)
+		}
+
+		i = end
+	}
+
+	return out, nil
+}
+
+// stripWebP drops the EXIF and XMP chunks of an extended WebP and clears their flags in the
+// VP8X chunk, fixing the size of the RIFF container.
+func stripWebP(content []byte) ([]byte, error) {
+	const headerLength = 12
+	if len(content) < headerLength {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(content))
+	out = append(out, content[:headerLength]...)
+
+	for i := headerLength; i < len(content); {
+		if i+8 > len(content) {
+			return nil, errMalformed
+		}
+
+		// Chunks are padded to an even size.
+		size := int(binary.LittleEndian.Uint32(content[i+4 : i+8]))
+		end := i + 8 + size + size%2
+		if end > len(content) || end < i {
+			return nil, errMalformed
+		}
+
+		switch string(content[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), content[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, content[i:end]...)
+		}
+
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+
+	return out, nil
+}
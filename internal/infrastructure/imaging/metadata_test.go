@@ -0,0 +1,104 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/jpeg"
+	"testing"
+)
+
+func segment(marker byte, payload string) []byte {
+	out := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(out[2:], uint16(len(payload)+2))
+
+	return append(out, payload...)
+}
+
+// withSegments inserts the segments right after the start of image of a JPEG.
+func withSegments(content []byte, segments ...[]byte) []byte {
+	out := append([]byte(nil), content[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+
+	return append(out, content[2:]...)
+}
+
+// exifWithOrientation returns an APP1 segment whose EXIF only records the orientation.
+func exifWithOrientation(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+
+	return segment(0xE1, "Exif\x00\x00"+string(tiff)+string(entry))
+}
+
+func TestStripJPEGKeepsOnlyTheSegmentsNeededToDisplayThePhoto(t *testing.T) {
+	content := withSegments(encodeJPEG(t, gradient(16, 16, false)),
+		segment(0xE0, "JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"),
+		exifWithOrientation(1),
+		segment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"),
+		segment(0xE2, "ICC_PROFILE\x00\x01\x01"),
+		segment(0xED, "Photoshop 3.0\x00"),
+		segment(0xEE, "Adobe\x00\x64\x00\x00\x00\x00\x01"),
+		segment(0xFE, "taken at home"),
+	)
+
+	stripped, err := stripJPEG(content)
+	if err != nil {
+		t.Fatalf("stripJPEG() error = %v", err)
+	}
+
+	segments, _, err := jpegSegments(stripped)
+	if err != nil {
+		t.Fatalf("jpegSegments() error = %v", err)
+	}
+
+	kept := map[byte]bool{}
+	for _, segment := range segments {
+		kept[segment[1]] = true
+	}
+
+	for _, marker := range []byte{0xE0, 0xE2, 0xEE, 0xDB, 0xC0} {
+		if !kept[marker] {
+			t.Errorf("segment %X dropped, want it kept", marker)
+		}
+	}
+
+	for _, marker := range []byte{0xE1, 0xED, 0xFE} {
+		if kept[marker] {
+			t.Errorf("segment %X kept, want it dropped", marker)
+		}
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("the stripped photo can not be decoded: %v", err)
+	}
+}
+
+func TestStripMetadataRotatesAPhotoThatIsNotUpright(t *testing.T) {
+	processor := NewPhotoProcessor(Config{MaxPixels: defaultMaxPixels})
+	content := withSegments(encodeJPEG(t, gradient(32, 16, false)), exifWithOrientation(6))
+
+	if got := jpegOrientation(content); got != 6 {
+		t.Fatalf("jpegOrientation() = %d, want 6", got)
+	}
+
+	stripped, err := processor.StripMetadata(content, "image/jpeg")
+	if err != nil {
+		t.Fatalf("StripMetadata() error = %v", err)
+	}
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("DecodeConfig() error = %v", err)
+	}
+
+	if config.Width != 16 || config.Height != 32 || jpegOrientation(stripped) != 1 {
+		t.Errorf("got a %dx%d photo with orientation %d, want 16x32 upright",
+			config.Width, config.Height, jpegOrientation(stripped))
+	}
+}
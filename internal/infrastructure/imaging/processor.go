@@ -0,0 +1,192 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+// jpegQuality is used for every JPEG the processor encodes.
+const jpegQuality = 85
+
+// photoProcessor only uses the decoders and encoders of the standard library, which do not
+// read WebP: WebP photos have their metadata stripped but can not be resized.
+type photoProcessor struct {
+	maxPixels int64
+}
+
+func NewPhotoProcessor(config Config) ports.PhotoProcessor {
+	return &photoProcessor{
+		maxPixels: config.MaxPixels,
+	}
+}
+
+// StripMetadata removes the metadata without decoding the photo, except for a JPEG that is
+// not upright, which is decoded, rotated and encoded again. The size of every photo the
+// processor can decode is checked here, so an upload is rejected before its variants fail.
+func (processor *photoProcessor) StripMetadata(content []byte, contentType string) ([]byte, error) {
+	if processor.CanResize(contentType) {
+		if err := processor.checkSize(content, contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	switch contentType {
+	case "image/jpeg":
+		orientation := jpegOrientation(content)
+		if orientation <= 1 {
+			return stripJPEG(content)
+		}
+
+		img, err := jpeg.Decode(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding photo: %w", err)
+		}
+
+		// Encoding never writes the metadata of the original.
+		return encode(orient(img, orientation), contentType)
+	case "image/png":
+		return stripPNG(content)
+	case "image/webp":
+		return stripWebP(content)
+	default:
+		return content, nil
+	}
+}
+
+func (processor *photoProcessor) CanResize(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	default:
+		return false
+	}
+}
+
+// Resize encodes the variants of a JPEG as JPEG and the others as PNG, the first frame of an
+// animated GIF being enough for a still variant.
+func (processor *photoProcessor) Resize(content []byte, contentType string, size int) ([]byte, string, error) {
+	img, err := processor.decode(content, contentType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if contentType == "image/gif" {
+		contentType = "image/png"
+	}
+
+	resized, err := encode(fit(img, size), contentType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resized, contentType, nil
+}
+
+// Hash returns the difference hash of the photo: the photo is shrunk to a grid of gray cells,
+// and every bit tells whether a cell is brighter than its right neighbour. Resized, recompressed
+// or slightly retouched copies of a photo get hashes a few bits apart.
+func (processor *photoProcessor) Hash(content []byte, contentType string) (uint64, error) {
+	img, err := processor.decode(content, contentType)
+	if err != nil {
+		return 0, err
+	}
+
+	cells := grayCells(toRGBA(img), hashWidth, hashHeight)
+
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if cells[y*hashWidth+x] > cells[y*hashWidth+x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash, nil
+}
+
+// checkSize reads the dimensions declared by the header of the photo, so a small file that
+// declares a huge image is rejected before its pixels are allocated.
+func (processor *photoProcessor) checkSize(content []byte, contentType string) error {
+	var (
+		config image.Config
+		err    error
+	)
+
+	switch contentType {
+	case "image/jpeg":
+		config, err = jpeg.DecodeConfig(bytes.NewReader(content))
+	case "image/png":
+		config, err = png.DecodeConfig(bytes.NewReader(content))
+	case "image/gif":
+		config, err = gif.DecodeConfig(bytes.NewReader(content))
+	default:
+		return fmt.Errorf("can not decode photos of type %s", contentType)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error decoding photo: %w", err)
+	}
+
+	if pixels := int64(config.Width) * int64(config.Height); pixels > processor.maxPixels {
+		return fmt.Errorf("%w: %dx%d, the most is %d pixels", domain.ErrPhotoTooManyPixels,
+			config.Width, config.Height, processor.maxPixels)
+	}
+
+	return nil
+}
+
+// decode reads the first frame of the photo formats CanResize accepts, once checkSize let
+// them through.
+func (processor *photoProcessor) decode(content []byte, contentType string) (image.Image, error) {
+	if err := processor.checkSize(content, contentType); err != nil {
+		return nil, err
+	}
+
+	var (
+		img image.Image
+		err error
+	)
+
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(content))
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(content))
+	case "image/gif":
+		img, err = gif.Decode(bytes.NewReader(content))
+	default:
+		return nil, fmt.Errorf("can not decode photos of type %s", contentType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding photo: %w", err)
+	}
+
+	return img, nil
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error encoding photo: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,126 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+// gradient draws an image whose brightness grows from left to right, or the other way round.
+func gradient(width, height int, reversed bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level := uint8(x * 255 / width)
+			if reversed {
+				level = 255 - level
+			}
+
+			img.Set(x, y, color.RGBA{R: level, G: level, B: level, A: 255})
+		}
+	}
+
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// declareSize rewrites the dimensions of the frame header of a JPEG without touching its data.
+func declareSize(t *testing.T, content []byte, width, height uint16) []byte {
+	t.Helper()
+
+	content = append([]byte(nil), content...)
+
+	for i := 2; i+9 < len(content); {
+		if content[i+1] == 0xC0 {
+			binary.BigEndian.PutUint16(content[i+5:], height)
+			binary.BigEndian.PutUint16(content[i+7:], width)
+
+			return content
+		}
+
+		i += 2 + int(binary.BigEndian.Uint16(content[i+2:]))
+	}
+
+	t.Fatal("the JPEG has no frame header")
+
+	return nil
+}
+
+func TestProcessorRejectsPhotosWithTooManyPixels(t *testing.T) {
+	processor := NewPhotoProcessor(Config{MaxPixels: defaultMaxPixels})
+	huge := declareSize(t, encodeJPEG(t, gradient(16, 16, false)), 50000, 50000)
+
+	if _, err := processor.StripMetadata(huge, "image/jpeg"); !errors.Is(err, domain.ErrPhotoTooManyPixels) {
+		t.Errorf("StripMetadata() error = %v, want %v", err, domain.ErrPhotoTooManyPixels)
+	}
+
+	if _, _, err := processor.Resize(huge, "image/jpeg", 100); !errors.Is(err, domain.ErrPhotoTooManyPixels) {
+		t.Errorf("Resize() error = %v, want %v", err, domain.ErrPhotoTooManyPixels)
+	}
+
+	if _, err := processor.Hash(huge, "image/jpeg"); !errors.Is(err, domain.ErrPhotoTooManyPixels) {
+		t.Errorf("Hash() error = %v, want %v", err, domain.ErrPhotoTooManyPixels)
+	}
+
+	if _, err := processor.StripMetadata(encodeJPEG(t, gradient(16, 16, false)), "image/jpeg"); err != nil {
+		t.Errorf("StripMetadata() of a small photo error = %v", err)
+	}
+}
+
+func TestResizeFitsThePhotoInTheSize(t *testing.T) {
+	processor := NewPhotoProcessor(Config{MaxPixels: defaultMaxPixels})
+
+	resized, contentType, err := processor.Resize(encodeJPEG(t, gradient(200, 100, false)), "image/jpeg", 50)
+	if err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(resized))
+	if err != nil {
+		t.Fatalf("DecodeConfig() error = %v", err)
+	}
+
+	if contentType != "image/jpeg" || config.Width != 50 || config.Height != 25 {
+		t.Errorf("Resize() = %s %dx%d, want image/jpeg 50x25", contentType, config.Width, config.Height)
+	}
+}
+
+func TestHashIsCloseForCopiesOfAPhoto(t *testing.T) {
+	processor := NewPhotoProcessor(Config{MaxPixels: defaultMaxPixels})
+
+	hash := func(img image.Image) uint64 {
+		value, err := processor.Hash(encodeJPEG(t, img), "image/jpeg")
+		if err != nil {
+			t.Fatalf("Hash() error = %v", err)
+		}
+
+		return value
+	}
+
+	original := hash(gradient(256, 256, false))
+
+	if distance := domain.PhotoHashDistance(original, hash(gradient(64, 64, false))); distance > 4 {
+		t.Errorf("distance to a smaller copy = %d, want at most 4", distance)
+	}
+
+	if distance := domain.PhotoHashDistance(original, hash(gradient(256, 256, true))); distance < 32 {
+		t.Errorf("distance to another photo = %d, want at least 32", distance)
+	}
+}
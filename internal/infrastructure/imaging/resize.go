@@ -0,0 +1,169 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+
+	return rgba
+}
+
+// fit scales an image down to fit in a square of size pixels, averaging the source pixels
+// covered by every destination pixel, which is what a downscale needs to avoid aliasing.
+func fit(img image.Image, size int) image.Image {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width <= size && height <= size {
+		return img
+	}
+
+	dstWidth, dstHeight := size, size
+	if width > height {
+		dstHeight = (height*size + width/2) / width
+	} else {
+		dstWidth = (width*size + height/2) / height
+	}
+
+	if dstWidth == 0 {
+		dstWidth = 1
+	}
+
+	if dstHeight == 0 {
+		dstHeight = 1
+	}
+
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for dy := 0; dy < dstHeight; dy++ {
+		sy0, sy1 := span(dy, height, dstHeight)
+
+		for dx := 0; dx < dstWidth; dx++ {
+			sx0, sx1 := span(dx, width, dstWidth)
+
+			var r, g, b, a, count int
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := sx0; sx < sx1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += int(pixel[0])
+					g += int(pixel[1])
+					b += int(pixel[2])
+					a += int(pixel[3])
+					count++
+				}
+			}
+
+			offset := dy*dst.Stride + dx*4
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+
+	return dst
+}
+
+const (
+	// hashWidth and hashHeight are the grid of a difference hash, whose rows of 9 cells give 8
+	// bits each, one for every pair of neighbours, for a hash of 64 bits.
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// grayCells averages the luminance of the pixels covered by every cell of a width by height
+// grid, in rows. A cell smaller than a pixel takes the pixel under it.
+func grayCells(src *image.RGBA, width, height int) []int {
+	srcWidth, srcHeight := src.Rect.Dx(), src.Rect.Dy()
+	cells := make([]int, width*height)
+
+	for cy := 0; cy < height; cy++ {
+		sy0, sy1 := cover(cy, srcHeight, height)
+
+		for cx := 0; cx < width; cx++ {
+			sx0, sx1 := cover(cx, srcWidth, width)
+
+			var sum, count int
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := sx0; sx < sx1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					sum += (299*int(pixel[0]) + 587*int(pixel[1]) + 114*int(pixel[2])) / 1000
+					count++
+				}
+			}
+
+			if count > 0 {
+				cells[cy*width+cx] = sum / count
+			}
+		}
+	}
+
+	return cells
+}
+
+// cover is span for any sizes, covering at least one source pixel when there is one.
+func cover(i, srcSize, dstSize int) (int, int) {
+	start, end := span(i, srcSize, dstSize)
+	if end <= start && start < srcSize {
+		end = start + 1
+	}
+
+	return start, end
+}
+
+// span returns the source pixels covered by the destination pixel i when scaling srcSize
+// pixels to dstSize, which is always at least one pixel since the image is scaled down.
+func span(i, srcSize, dstSize int) (int, int) {
+	return i * srcSize / dstSize, (i + 1) * srcSize / dstSize
+}
+
+// orient turns an image as its EXIF orientation says, so it is displayed upright without it.
+func orient(img image.Image, orientation int) image.Image {
+	src := toRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = width-1-x, y
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sx, sy = x, height-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			default:
+				sx, sy = x, y
+			}
+
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,178 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/i18n"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
+)
+
+// photoSeparator separates the photos of the photos column.
+const photoSeparator = "|"
+
+// csvColumns are the columns a CSV header can have, named after the JSON fields of dto.ItemBody.
+var csvColumns = []string{"code", "title", "description", "price", "stock", "itemType", "leader",
+	"leaderLevel", "photos"}
+
+// readOnlyColumns are written by exports and ignored, so an export can be imported back.
+var readOnlyColumns = []string{"id", "status", "version", "createdAt", "updatedAt"}
+
+func readCSV(r io.Reader, validator *validation.Validator, language string) ([]domain.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("the CSV file is empty")
+		}
+
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+
+	columns, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []domain.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseRecord(line, columns, record, validator, language))
+	}
+
+	return rows, nil
+}
+
+// parseHeader maps every column of the header to its dto.ItemBody field, ignoring case and
+// the byte order mark some spreadsheets write before the first one. Read-only columns map
+// to an empty field and are skipped.
+func parseHeader(header []string) ([]string, error) {
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+
+		for _, column := range csvColumns {
+			if strings.EqualFold(name, column) {
+				columns[i] = column
+			}
+		}
+
+		if columns[i] == "" && isReadOnlyColumn(name) {
+			continue
+		}
+
+		if columns[i] == "" {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+
+		if seen[columns[i]] {
+			return nil, fmt.Errorf("duplicated CSV column %q", name)
+		}
+
+		seen[columns[i]] = true
+	}
+
+	return columns, nil
+}
+
+func isReadOnlyColumn(name string) bool {
+	for _, column := range readOnlyColumns {
+		if strings.EqualFold(name, column) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseRecord(line int, columns, record []string, validator *validation.Validator,
+	language string) domain.ImportRow {
+	var body dto.ItemBody
+	var rowErrors []domain.ImportError
+
+	invalid := func(field, key string) {
+		message := fmt.Sprintf(i18n.Text(language, key), field)
+		rowErrors = append(rowErrors, domain.ImportError{
+			Line:    line,
+			Field:   field,
+			Code:    domain.ErrCodeRequestValidationFailed,
+			Message: message,
+		})
+	}
+
+	for i, column := range columns {
+		if i >= len(record) {
+			break
+		}
+
+		value := strings.TrimSpace(record[i])
+
+		switch column {
+		case "code":
+			body.Code = value
+		case "title":
+			body.Title = value
+		case "description":
+			body.Description = value
+		case "price":
+			if value != "" {
+				price, err := strconv.Atoi(value)
+				if err != nil {
+					invalid(column, "import.integer")
+				}
+
+				body.Price = price
+			}
+		case "stock":
+			if value != "" {
+				stock, err := strconv.Atoi(value)
+				if err != nil {
+					invalid(column, "import.integer")
+				}
+
+				body.Stock = stock
+			}
+		case "itemType":
+			body.ItemType = value
+		case "leader":
+			if value != "" {
+				leader, err := strconv.ParseBool(value)
+				if err != nil {
+					invalid(column, "import.boolean")
+				}
+
+				body.Leader = leader
+			}
+		case "leaderLevel":
+			body.LeaderLevel = value
+		case "photos":
+			for _, photo := range strings.Split(value, photoSeparator) {
+				if photo = strings.TrimSpace(photo); photo != "" {
+					body.Photos = append(body.Photos, photo)
+				}
+			}
+		}
+	}
+
+	return newRow(line, body, rowErrors, validator, language)
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/i18n"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
+)
+
+// ReadRows parses a whole import file and checks every row against the binding rules of
+// dto.ItemBody, with the messages in the given language. A row that can not be parsed is
+// reported in its ImportRow, while an error means the file itself is not readable.
+func ReadRows(format string, r io.Reader, validator *validation.Validator,
+	language string) ([]domain.ImportRow, error) {
+	switch format {
+	case domain.ImportFormatCSV:
+		return readCSV(r, validator, language)
+	case domain.ImportFormatJSONL:
+		return readJSONL(r, validator, language)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// FormatOf returns the import format of a file name, or an empty string when it is not known.
+func FormatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return domain.ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return domain.ImportFormatJSONL
+	default:
+		return ""
+	}
+}
+
+// WriteErrorReport writes the errors of an import as CSV, one line for each error. The errors of
+// a field are in the language the file was read in, while the errors of a whole row are written
+// in the given language, translated by their code and arguments. Cells are escaped so that a
+// spreadsheet does not run them as formulas.
+func WriteErrorReport(w io.Writer, importErrors []domain.ImportError, language string) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"line", "field", "code", "message"}); err != nil {
+		return err
+	}
+
+	for _, importError := range importErrors {
+		message := importError.Message
+		if importError.Field == "" {
+			if detail, ok := i18n.Detail(language, importError.Code, importError.Args...); ok {
+				message = detail
+			}
+		}
+
+		record := []string{strconv.Itoa(importError.Line), escapeCell(importError.Field),
+			escapeCell(importError.Code), escapeCell(message)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// escapeCell prefixes with a quote the values a spreadsheet would read as a formula, see
+// https://owasp.org/www-community/attacks/CSV_Injection.
+func escapeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// newRow builds the row of a parsed body, checking the binding rules of the fields that were parsed.
+func newRow(line int, body dto.ItemBody, rowErrors []domain.ImportError, validator *validation.Validator,
+	language string) domain.ImportRow {
+	unparsed := make(map[string]bool, len(rowErrors))
+	for _, rowError := range rowErrors {
+		unparsed[rowError.Field] = true
+	}
+
+	for _, fieldError := range validator.Struct(body, language) {
+		if unparsed[fieldError.Field] {
+			continue
+		}
+
+		rowErrors = append(rowErrors, domain.ImportError{
+			Line:    line,
+			Field:   fieldError.Field,
+			Code:    domain.ErrCodeRequestValidationFailed,
+			Message: fieldError.Message,
+		})
+	}
+
+	return domain.ImportRow{
+		Line:   line,
+		Item:   body.ToItemDomain(),
+		Errors: rowErrors,
+	}
+}
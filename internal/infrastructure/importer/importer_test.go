@@ -0,0 +1,160 @@
+package importer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/i18n"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
+)
+
+func newValidator(t *testing.T) *validation.Validator {
+	validator, err := validation.New(validation.Config{PhotoURLSchemes: []string{"https"}})
+	if err != nil {
+		t.Fatalf("validation.New() error = %v", err)
+	}
+
+	return validator
+}
+
+func TestReadRowsCSV(t *testing.T) {
+	file := "\ufeffID,Code,title,description,price,stock,itemType,leader,photos\n" +
+		"7,A-1,Lamp,Desk lamp,1500,3,OWN,true,https://img.example.com/a.jpg | https://img.example.com/b.jpg\n" +
+		",A-2,Chair,Office chair,cheap,1,SELLER,false,https://img.example.com/c.jpg\n"
+
+	rows, err := ReadRows(domain.ImportFormatCSV, strings.NewReader(file), newValidator(t), i18n.English)
+	if err != nil {
+		t.Fatalf("ReadRows() error = %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	item := rows[0].Item
+	if len(rows[0].Errors) != 0 || item.Code != "A-1" || item.Price != 1500 || !item.Leader || len(item.Photos) != 2 {
+		t.Errorf("first row = %+v, errors %v", item, rows[0].Errors)
+	}
+
+	want := domain.ImportError{Line: 3, Field: "price", Code: domain.ErrCodeRequestValidationFailed,
+		Message: "price must be an integer"}
+	if len(rows[1].Errors) != 1 || !reflect.DeepEqual(rows[1].Errors[0], want) {
+		t.Errorf("second row errors = %v, want [%v]", rows[1].Errors, want)
+	}
+}
+
+func TestReadRowsCSVRejectsTheHeader(t *testing.T) {
+	for _, header := range []string{"", "code,colour\n", "code,Code\n"} {
+		if _, err := ReadRows(domain.ImportFormatCSV, strings.NewReader(header), newValidator(t),
+			i18n.English); err == nil {
+			t.Errorf("ReadRows() with the header %q did not fail", header)
+		}
+	}
+}
+
+func TestReadRowsJSONL(t *testing.T) {
+	file := `{"code":"A-1","title":"Lamp","description":"Desk lamp","price":1500,"itemType":"OWN",` +
+		`"photos":["https://img.example.com/a.jpg"]}` + "\n\n" +
+		`{"code":` + "\n" +
+		`{"code":"A-3","title":"Chair","description":"Office chair","price":900,"itemType":"OWN"}` + "\n"
+
+	rows, err := ReadRows(domain.ImportFormatJSONL, strings.NewReader(file), newValidator(t), i18n.English)
+	if err != nil {
+		t.Fatalf("ReadRows() error = %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	if len(rows[0].Errors) != 0 || rows[0].Item.Code != "A-1" {
+		t.Errorf("first row = %+v, errors %v", rows[0].Item, rows[0].Errors)
+	}
+
+	if len(rows[1].Errors) != 1 || rows[1].Line != 3 || rows[1].Errors[0].Code != domain.ErrCodeMalformedRequest {
+		t.Errorf("malformed row = line %d, errors %v", rows[1].Line, rows[1].Errors)
+	}
+
+	if len(rows[2].Errors) != 1 || rows[2].Errors[0].Field != "photos" {
+		t.Errorf("row without photos errors = %v, want one on photos", rows[2].Errors)
+	}
+}
+
+func TestWriteErrorReport(t *testing.T) {
+	importErrors := []domain.ImportError{
+		{Line: 3, Field: "price", Code: domain.ErrCodeRequestValidationFailed, Message: "price debe ser un entero"},
+		{Line: 5, Code: domain.ErrCodeItemCodeDuplicated, Message: "The item code must be unique"},
+		{Line: 6, Code: domain.ErrCodeItemLeaderLevelInvalid, Message: "Leader level is not valid: =HYPERLINK(1)",
+			Args: []interface{}{"=HYPERLINK(1)"}},
+		{Line: 7, Code: domain.ErrCodeItemPhotosLimit, Message: "An item can have at most 20 photos",
+			Args: []interface{}{int64(20)}},
+		{Line: 8, Field: "title", Code: domain.ErrCodeRequestValidationFailed, Message: "@SUM(A1:A2) is not a title"},
+		{Line: 9, Code: domain.ErrCodeMalformedRequest, Message: "-unexpected end of JSON input"},
+	}
+
+	tests := []struct {
+		name     string
+		language string
+		want     string
+	}{
+		{
+			name:     "default language",
+			language: i18n.English,
+			want: "line,field,code,message\n" +
+				"3,price,REQUEST_VALIDATION_FAILED,price debe ser un entero\n" +
+				"5,,ITEM_CODE_DUPLICATED,The item code must be unique\n" +
+				"6,,ITEM_LEADER_LEVEL_INVALID,Leader level is not valid: =HYPERLINK(1)\n" +
+				"7,,ITEM_PHOTOS_LIMIT_EXCEEDED,An item can have at most 20 photos\n" +
+				"8,title,REQUEST_VALIDATION_FAILED,'@SUM(A1:A2) is not a title\n" +
+				"9,,MALFORMED_REQUEST,'-unexpected end of JSON input\n",
+		},
+		{
+			name:     "errors of a whole row translated",
+			language: i18n.Spanish,
+			want: "line,field,code,message\n" +
+				"3,price,REQUEST_VALIDATION_FAILED,price debe ser un entero\n" +
+				"5,,ITEM_CODE_DUPLICATED,El código del ítem debe ser único\n" +
+				"6,,ITEM_LEADER_LEVEL_INVALID,El nivel de líder no es válido: =HYPERLINK(1)\n" +
+				"7,,ITEM_PHOTOS_LIMIT_EXCEEDED,Un ítem puede tener como máximo 20 fotos\n" +
+				"8,title,REQUEST_VALIDATION_FAILED,'@SUM(A1:A2) is not a title\n" +
+				"9,,MALFORMED_REQUEST,'-unexpected end of JSON input\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var report bytes.Buffer
+
+			if err := WriteErrorReport(&report, importErrors, test.language); err != nil {
+				t.Fatalf("WriteErrorReport() error = %v", err)
+			}
+
+			if report.String() != test.want {
+				t.Errorf("report = %q, want %q", report.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestEscapeCell(t *testing.T) {
+	tests := map[string]string{
+		"":                     "",
+		"price":                "price",
+		"=1+1":                 "'=1+1",
+		"+1":                   "'+1",
+		"-1":                   "'-1",
+		"@SUM(A1)":             "'@SUM(A1)",
+		"\t=1":                 "'\t=1",
+		"a=1":                  "a=1",
+		"ITEM_CODE_DUPLICATED": "ITEM_CODE_DUPLICATED",
+	}
+
+	for value, want := range tests {
+		if got := escapeCell(value); got != want {
+			t.Errorf("escapeCell(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
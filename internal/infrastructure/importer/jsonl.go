@@ -0,0 +1,51 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
+)
+
+// maxJSONLLine is the longest line accepted, enough for an item with the largest description and photos.
+const maxJSONLLine = 1 << 20
+
+func readJSONL(r io.Reader, validator *validation.Validator, language string) ([]domain.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLine)
+
+	var rows []domain.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var body dto.ItemBody
+		if err := json.Unmarshal([]byte(text), &body); err != nil {
+			rows = append(rows, domain.ImportRow{
+				Line: line,
+				Errors: []domain.ImportError{{
+					Line:    line,
+					Code:    domain.ErrCodeMalformedRequest,
+					Message: err.Error(),
+				}},
+			})
+
+			continue
+		}
+
+		rows = append(rows, newRow(line, body, nil, validator, language))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading JSON Lines: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	jobWorkers           = "JOB_WORKERS"
+	jobPollInterval      = "JOB_POLL_INTERVAL"
+	jobHeartbeatInterval = "JOB_HEARTBEAT_INTERVAL"
+	jobStaleAfter        = "JOB_STALE_AFTER"
+	jobBackoffBase       = "JOB_BACKOFF_BASE"
+	jobBackoffMax        = "JOB_BACKOFF_MAX"
+	jobDrainTimeout      = "JOB_DRAIN_TIMEOUT"
+)
+
+type RunnerConfig struct {
+	// Workers is how many jobs run at the same time.
+	Workers int
+	// PollInterval is how long an idle worker waits before looking for a job again.
+	PollInterval time.Duration
+	// HeartbeatInterval is how often a running job stores its progress and checks for cancellation.
+	HeartbeatInterval time.Duration
+	// StaleAfter is how long without a heartbeat before a running job is claimed again,
+	// e.g. after its runner crashed. It must be at least three heartbeat intervals.
+	StaleAfter time.Duration
+	// BackoffBase is the delay before the second attempt, doubled on every further attempt up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// DrainTimeout is how long a stop waits for the running jobs before cancelling them.
+	DrainTimeout time.Duration
+}
+
+func LoadRunnerConfig() RunnerConfig {
+	return RunnerConfig{
+		Workers:           getEnvInt(jobWorkers, 4),
+		PollInterval:      getEnvDuration(jobPollInterval, time.Second),
+		HeartbeatInterval: getEnvDuration(jobHeartbeatInterval, 5*time.Second),
+		StaleAfter:        getEnvDuration(jobStaleAfter, time.Minute),
+		BackoffBase:       getEnvDuration(jobBackoffBase, 10*time.Second),
+		BackoffMax:        getEnvDuration(jobBackoffMax, 10*time.Minute),
+		DrainTimeout:      getEnvDuration(jobDrainTimeout, 30*time.Second),
+	}
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
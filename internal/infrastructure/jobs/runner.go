@@ -0,0 +1,337 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+// minHeartbeatsBeforeStale is how many heartbeats a running job can miss, e.g. while the
+// database is slow, before another runner claims it again.
+const minHeartbeatsBeforeStale = 3
+
+type runner struct {
+	repository ports.JobRepository
+	config     RunnerConfig
+	handlers   map[string]ports.JobHandler
+
+	// ctx is the parent of the contexts of the running jobs, cancelled when draining times out.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	stop     chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
+}
+
+func NewRunner(repository ports.JobRepository, config RunnerConfig) (ports.JobRunner, error) {
+	if repository == nil {
+		return nil, fmt.Errorf("job repository cannot be nil")
+	}
+
+	if config.Workers <= 0 {
+		return nil, fmt.Errorf("job workers must be positive")
+	}
+
+	if config.HeartbeatInterval <= 0 {
+		return nil, fmt.Errorf("job heartbeat interval must be positive")
+	}
+
+	if config.StaleAfter < minHeartbeatsBeforeStale*config.HeartbeatInterval {
+		return nil, fmt.Errorf("job stale after must be at least %d heartbeat intervals",
+			minHeartbeatsBeforeStale)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &runner{
+		repository: repository,
+		config:     config,
+		handlers:   make(map[string]ports.JobHandler),
+		ctx:        ctx,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+	}, nil
+}
+
+// Register adds the handler of a job type. Handlers are registered before Start.
+func (r *runner) Register(handler ports.JobHandler) error {
+	if handler == nil {
+		return fmt.Errorf("job handler cannot be nil")
+	}
+
+	if _, ok := r.handlers[handler.JobType()]; ok {
+		return fmt.Errorf("job handler already registered for type %s", handler.JobType())
+	}
+
+	r.handlers[handler.JobType()] = handler
+
+	return nil
+}
+
+func (r *runner) Start() {
+	for i := 0; i < r.config.Workers; i++ {
+		r.workers.Add(1)
+		go r.work()
+	}
+}
+
+func (r *runner) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stop) })
+
+	drained := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-drained
+		return fmt.Errorf("jobs not drained: %w", ctx.Err())
+	}
+}
+
+func (r *runner) work() {
+	defer r.workers.Done()
+
+	logger := marketcontext.Logger(r.ctx)
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+
+		now := time.Now()
+		job, err := r.repository.ClaimJob(r.ctx, now, now.Add(-r.config.StaleAfter))
+		if err != nil {
+			logger.Error(r, nil, err, "error claiming job")
+		}
+
+		if job != nil {
+			r.run(job)
+			continue
+		}
+
+		select {
+		case <-r.stop:
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// run runs a claimed job and stores its outcome: succeeded, cancelled, queued again for a
+// later attempt, or failed once its attempts are exhausted. A job interrupted by a stop is
+// queued again without counting the attempt. A job whose claim was lost is stopped and its
+// outcome left to the runner that claimed it again.
+func (r *runner) run(job *domain.Job) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+
+	ctx = marketcontext.WithCaller(ctx, marketcontext.Caller{ID: job.Actor})
+	logger := marketcontext.Logger(ctx).With(log.Uint("job_id", job.ID), log.String("job_type", job.Type))
+	ctx = marketcontext.WithLogger(ctx, logger)
+
+	execution := &execution{job: job}
+	heartbeatDone := make(chan struct{})
+	heartbeatStopped := make(chan struct{})
+	go func() {
+		defer close(heartbeatStopped)
+		r.heartbeat(ctx, execution, cancel, heartbeatDone)
+	}()
+
+	var err error
+	handler, ok := r.handlers[job.Type]
+	switch {
+	case !ok:
+		err = fmt.Errorf("no handler registered for job type %s", job.Type)
+		job.Attempts = job.MaxAttempts
+	case job.CancelRequested:
+		execution.cancel()
+	case job.Attempts > job.MaxAttempts:
+		err = fmt.Errorf("job abandoned after %d attempts", job.MaxAttempts)
+		job.Attempts = job.MaxAttempts
+	default:
+		err = handle(ctx, handler, job, execution.report)
+	}
+
+	close(heartbeatDone)
+	<-heartbeatStopped
+
+	if execution.lost() {
+		logger.Warn(r, nil, "job claim lost, its outcome is left to the runner that claimed it")
+		return
+	}
+
+	now := time.Now()
+	job.Progress, job.ProgressMessage = execution.progress()
+
+	switch {
+	case execution.cancelled():
+		job.Status = domain.JobStatusCancelled
+		job.CompletedAt = &now
+	case err == nil:
+		job.Status = domain.JobStatusSucceeded
+		job.Progress = 100
+		job.Error = ""
+		job.CompletedAt = &now
+	case r.ctx.Err() != nil:
+		job.Status = domain.JobStatusQueued
+		job.Attempts--
+		job.RunAt = now
+	case job.Attempts < job.MaxAttempts:
+		logger.Warn(r, nil, "job attempt %d of %d failed: %s", job.Attempts, job.MaxAttempts, err.Error())
+		job.Status = domain.JobStatusQueued
+		job.Error = err.Error()
+		job.RunAt = now.Add(r.backoff(job.Attempts))
+	default:
+		logger.Error(r, nil, err, "job failed")
+		job.Status = domain.JobStatusFailed
+		job.Error = err.Error()
+		job.CompletedAt = &now
+	}
+
+	if err := r.repository.UpdateJob(context.Background(), job); err != nil {
+		logger.Error(r, nil, err, "error updating job")
+	}
+}
+
+// heartbeat stores the progress of a running job every HeartbeatInterval and cancels it when
+// its cancellation is requested or its claim is lost, so a job never runs on two runners.
+func (r *runner) heartbeat(ctx context.Context, execution *execution, cancel context.CancelFunc,
+	done <-chan struct{}) {
+	logger := marketcontext.Logger(ctx)
+
+	ticker := time.NewTicker(r.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		beat := *execution.job
+		beat.Progress, beat.ProgressMessage = execution.progress()
+
+		cancelRequested, err := r.repository.HeartbeatJob(context.Background(), &beat, time.Now())
+		if errors.Is(err, domain.ErrJobClaimLost) {
+			execution.lose()
+			cancel()
+
+			return
+		}
+
+		if err != nil {
+			logger.Warn(r, nil, "error storing job heartbeat: %s", err.Error())
+			continue
+		}
+
+		if cancelRequested {
+			execution.cancel()
+			cancel()
+		}
+	}
+}
+
+// backoff is the delay before the attempt that follows the given one.
+func (r *runner) backoff(attempt int) time.Duration {
+	delay := r.config.BackoffBase
+	for i := 1; i < attempt && delay < r.config.BackoffMax; i++ {
+		delay *= 2
+	}
+
+	if delay > r.config.BackoffMax {
+		return r.config.BackoffMax
+	}
+
+	return delay
+}
+
+// handle runs a handler turning a panic into the error of the attempt.
+func handle(ctx context.Context, handler ports.JobHandler, job *domain.Job,
+	progress ports.JobProgress) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.New(fmt.Sprint("job handler panicked: ", recovered))
+		}
+	}()
+
+	return handler.HandleJob(ctx, job, progress)
+}
+
+// execution is the state of a running job shared by its handler and its heartbeat.
+type execution struct {
+	job *domain.Job
+
+	mu              sync.Mutex
+	percent         int
+	message         string
+	cancelRequested bool
+	claimLost       bool
+}
+
+func (e *execution) report(progress int, message string) {
+	if progress < 0 {
+		progress = 0
+	}
+
+	if progress > 100 {
+		progress = 100
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.percent = progress
+	e.message = message
+}
+
+func (e *execution) progress() (int, string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.percent, e.message
+}
+
+func (e *execution) cancel() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.cancelRequested = true
+}
+
+func (e *execution) cancelled() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.cancelRequested
+}
+
+func (e *execution) lose() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.claimLost = true
+}
+
+func (e *execution) lost() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.claimLost
+}
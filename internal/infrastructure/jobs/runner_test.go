@@ -0,0 +1,201 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+// jobRepository hands out a single job and records the outcomes stored for it.
+type jobRepository struct {
+	ports.JobRepository
+
+	mu        sync.Mutex
+	job       *domain.Job
+	heartbeat error
+	updates   []domain.Job
+}
+
+func (repo *jobRepository) ClaimJob(ctx context.Context, now, staleBefore time.Time) (*domain.Job, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	job := repo.job
+	repo.job = nil
+
+	if job != nil {
+		job.Status = domain.JobStatusRunning
+		job.Attempts++
+	}
+
+	return job, nil
+}
+
+func (repo *jobRepository) HeartbeatJob(ctx context.Context, job *domain.Job, at time.Time) (bool, error) {
+	return false, repo.heartbeat
+}
+
+func (repo *jobRepository) UpdateJob(ctx context.Context, job *domain.Job) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.updates = append(repo.updates, *job)
+
+	return nil
+}
+
+func (repo *jobRepository) outcomes() []domain.Job {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return append([]domain.Job(nil), repo.updates...)
+}
+
+type jobHandler func(ctx context.Context, job *domain.Job, progress ports.JobProgress) error
+
+func (handler jobHandler) JobType() string {
+	return "test"
+}
+
+func (handler jobHandler) HandleJob(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+	return handler(ctx, job, progress)
+}
+
+var testConfig = RunnerConfig{
+	Workers:           1,
+	PollInterval:      time.Millisecond,
+	HeartbeatInterval: 5 * time.Millisecond,
+	StaleAfter:        time.Second,
+	BackoffBase:       time.Minute,
+	BackoffMax:        time.Hour,
+}
+
+// runJob runs the job with the handler and returns once the handler has returned.
+func runJob(t *testing.T, repository *jobRepository, handler jobHandler) {
+	t.Helper()
+
+	finished := make(chan struct{})
+
+	runner, err := NewRunner(repository, testConfig)
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	err = runner.Register(jobHandler(func(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+		defer close(finished)
+		return handler(ctx, job, progress)
+	}))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	runner.Start()
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the job did not run")
+	}
+
+	if err := runner.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+func TestRunnerStoresTheOutcomeOfAJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler jobHandler
+		want    string
+	}{
+		{
+			name: "succeeded",
+			handler: func(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+				return nil
+			},
+			want: domain.JobStatusSucceeded,
+		},
+		{
+			name: "retried",
+			handler: func(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+				return errors.New("boom")
+			},
+			want: domain.JobStatusQueued,
+		},
+		{
+			name: "panicked",
+			handler: func(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+				panic("boom")
+			},
+			want: domain.JobStatusQueued,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &jobRepository{job: &domain.Job{ID: 1, Type: "test", MaxAttempts: 3}}
+			runJob(t, repository, test.handler)
+
+			outcomes := repository.outcomes()
+			if len(outcomes) != 1 || outcomes[0].Status != test.want {
+				t.Fatalf("outcomes = %+v, want one %s", outcomes, test.want)
+			}
+
+			if test.want == domain.JobStatusQueued && time.Until(outcomes[0].RunAt) < 50*time.Second {
+				t.Errorf("retried at %v, want after the backoff", outcomes[0].RunAt)
+			}
+		})
+	}
+}
+
+func TestRunnerStopsAJobWhoseClaimIsLost(t *testing.T) {
+	repository := &jobRepository{
+		job:       &domain.Job{ID: 1, Type: "test", MaxAttempts: 3},
+		heartbeat: domain.ErrJobClaimLost,
+	}
+
+	var handlerErr error
+
+	runJob(t, repository, func(ctx context.Context, job *domain.Job, progress ports.JobProgress) error {
+		select {
+		case <-ctx.Done():
+			handlerErr = ctx.Err()
+		case <-time.After(time.Second):
+		}
+
+		return handlerErr
+	})
+
+	if handlerErr == nil {
+		t.Error("the job was not cancelled when its claim was lost")
+	}
+
+	if outcomes := repository.outcomes(); len(outcomes) != 0 {
+		t.Errorf("outcomes = %+v, want none from a runner that lost the claim", outcomes)
+	}
+}
+
+func TestNewRunnerRejectsAStaleAfterOfFewHeartbeats(t *testing.T) {
+	config := testConfig
+	config.StaleAfter = 2 * config.HeartbeatInterval
+
+	if _, err := NewRunner(&jobRepository{}, config); err == nil {
+		t.Error("NewRunner() accepted a stale after of two heartbeats")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	r := &runner{config: RunnerConfig{BackoffBase: time.Second, BackoffMax: 5 * time.Second}}
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second,
+		4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := r.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"os"
+	"time"
+)
+
+var (
+	metricsAddr                 = "METRICS_ADDR"
+	metricsItemsRefreshInterval = "METRICS_ITEMS_REFRESH_INTERVAL"
+)
+
+const (
+	defaultAddr                 = "127.0.0.1:9090"
+	defaultItemsRefreshInterval = time.Minute
+)
+
+type Config struct {
+	// Addr is the address of the server of the metrics, apart from the API so it is only
+	// reachable from the internal network, e.g. ":9090" when the port is not exposed.
+	Addr string
+	// ItemsRefreshInterval is how long the counts of the items by status are reused by the
+	// scrapes before they are counted again.
+	ItemsRefreshInterval time.Duration
+}
+
+func LoadConfig() Config {
+	config := Config{
+		Addr:                 os.Getenv(metricsAddr),
+		ItemsRefreshInterval: defaultItemsRefreshInterval,
+	}
+
+	if config.Addr == "" {
+		config.Addr = defaultAddr
+	}
+
+	if value, err := time.ParseDuration(os.Getenv(metricsItemsRefreshInterval)); err == nil && value > 0 {
+		config.ItemsRefreshInterval = value
+	}
+
+	return config
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+)
+
+// RegisterDBStats registers gauges and counters of the connection pool of db, read from its
+// stats every time the metrics are written.
+func RegisterDBStats(registry *Registry, db *sql.DB) {
+	gauge := func(name, help string, value func(stats sql.DBStats) float64) {
+		registry.NewGaugeCollector(name, help, nil, func(ctx context.Context) ([]Sample, error) {
+			return []Sample{{Value: value(db.Stats())}}, nil
+		})
+	}
+
+	gauge("db_max_open_connections", "Maximum number of open connections to the database.",
+		func(stats sql.DBStats) float64 { return float64(stats.MaxOpenConnections) })
+	gauge("db_open_connections", "Connections to the database, in use and idle.",
+		func(stats sql.DBStats) float64 { return float64(stats.OpenConnections) })
+	gauge("db_in_use_connections", "Connections to the database in use.",
+		func(stats sql.DBStats) float64 { return float64(stats.InUse) })
+	gauge("db_idle_connections", "Idle connections to the database.",
+		func(stats sql.DBStats) float64 { return float64(stats.Idle) })
+
+	registry.NewCounterCollector("db_wait_count_total", "Connections waited for.", nil,
+		func(ctx context.Context) ([]Sample, error) {
+			return []Sample{{Value: float64(db.Stats().WaitCount)}}, nil
+		})
+	registry.NewCounterCollector("db_wait_duration_seconds_total", "Time spent waiting for connections.", nil,
+		func(ctx context.Context) ([]Sample, error) {
+			return []Sample{{Value: db.Stats().WaitDuration.Seconds()}}, nil
+		})
+	registry.NewCounterCollector("db_closed_connections_total", "Connections closed by the pool, by reason.",
+		[]string{"reason"}, func(ctx context.Context) ([]Sample, error) {
+			stats := db.Stats()
+
+			return []Sample{
+				{LabelValues: []string{"max_idle"}, Value: float64(stats.MaxIdleClosed)},
+				{LabelValues: []string{"max_idle_time"}, Value: float64(stats.MaxIdleTimeClosed)},
+				{LabelValues: []string{"max_lifetime"}, Value: float64(stats.MaxLifetimeClosed)},
+			}, nil
+		})
+}
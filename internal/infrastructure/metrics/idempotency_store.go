@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+// CacheMetrics counts the lookups of the caches, from which their hit ratio is computed, e.g.
+// with rate(cache_requests_total{result="hit"}[5m]) / rate(cache_requests_total[5m]).
+type CacheMetrics struct {
+	requests *Counter
+}
+
+func NewCacheMetrics(registry *Registry) *CacheMetrics {
+	return &CacheMetrics{
+		requests: registry.NewCounter("cache_requests_total",
+			"Lookups of the caches, by whether a stored value was found.", "cache", "result"),
+	}
+}
+
+type idempotencyStore struct {
+	store   ports.IdempotencyStore
+	metrics *CacheMetrics
+}
+
+// NewIdempotencyStore counts the lookups of an IdempotencyStore, which caches the responses of
+// the requests sent with an Idempotency-Key.
+func NewIdempotencyStore(store ports.IdempotencyStore, metrics *CacheMetrics) (ports.IdempotencyStore, error) {
+	if store == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+
+	if metrics == nil {
+		return nil, fmt.Errorf("metrics cannot be nil")
+	}
+
+	return &idempotencyStore{
+		store:   store,
+		metrics: metrics,
+	}, nil
+}
+
+// Reserve counts a hit when a response is replayed and a miss when the key is claimed. The
+// waits for a request still in progress and the keys reused for other requests are neither.
+func (store *idempotencyStore) Reserve(ctx context.Context, key, fingerprint string,
+	ttl, lease time.Duration) (string, *domain.IdempotencyRecord, error) {
+	owner, record, err := store.store.Reserve(ctx, key, fingerprint, ttl, lease)
+
+	switch {
+	case err != nil:
+	case record == nil:
+		store.metrics.requests.Inc("idempotency", "miss")
+	case record.Completed && record.Fingerprint == fingerprint:
+		store.metrics.requests.Inc("idempotency", "hit")
+	}
+
+	return owner, record, err
+}
+
+func (store *idempotencyStore) Renew(ctx context.Context, key, owner string, lease time.Duration) error {
+	return store.store.Renew(ctx, key, owner, lease)
+}
+
+func (store *idempotencyStore) Complete(ctx context.Context, record domain.IdempotencyRecord) error {
+	return store.store.Complete(ctx, record)
+}
+
+func (store *idempotencyStore) Release(ctx context.Context, key, owner string) error {
+	return store.store.Release(ctx, key, owner)
+}
+
+func (store *idempotencyStore) PurgeExpired(ctx context.Context, limit int) (int, error) {
+	return store.store.PurgeExpired(ctx, limit)
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+// RepositoryMetrics measures the operations of the repositories by backend, e.g. mysql or kvs.
+type RepositoryMetrics struct {
+	duration *Histogram
+	errors   *Counter
+}
+
+func NewRepositoryMetrics(registry *Registry) *RepositoryMetrics {
+	return &RepositoryMetrics{
+		duration: registry.NewHistogram("repository_operation_duration_seconds",
+			"Duration of the operations of the repositories.", StorageBuckets,
+			"backend", "repository", "operation"),
+		errors: registry.NewCounter("repository_operation_errors_total",
+			"Operations of the repositories that failed, leaving out the not found, conflict and "+
+				"validation errors of the domain.", "backend", "repository", "operation"),
+	}
+}
+
+// observe records an operation started at start. The errors of the domain are answers of the
+// repository, so they are not counted as failures.
+func (m *RepositoryMetrics) observe(backend, repository, operation string, start time.Time, err error) {
+	m.duration.Observe(time.Since(start).Seconds(), backend, repository, operation)
+
+	var (
+		notFound  domain.ResourceNotFoundError
+		conflict  domain.ConflictError
+		itemError domain.ItemError
+	)
+
+	if err != nil && !errors.As(err, &notFound) && !errors.As(err, &conflict) && !errors.As(err, &itemError) {
+		m.errors.Inc(backend, repository, operation)
+	}
+}
+
+type itemRepository struct {
+	repository ports.ItemRepository
+	metrics    *RepositoryMetrics
+	backend    string
+}
+
+// NewItemRepository measures the operations of an ItemRepository of the given backend.
+func NewItemRepository(repository ports.ItemRepository, metrics *RepositoryMetrics,
+	backend string) (ports.ItemRepository, error) {
+	if repository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	if metrics == nil {
+		return nil, fmt.Errorf("metrics cannot be nil")
+	}
+
+	return &itemRepository{
+		repository: repository,
+		metrics:    metrics,
+		backend:    backend,
+	}, nil
+}
+
+func (repo *itemRepository) observe(operation string, start time.Time, err error) {
+	repo.metrics.observe(repo.backend, "items", operation, start, err)
+}
+
+func (repo *itemRepository) SaveItem(ctx context.Context, item *domain.Item) error {
+	start := time.Now()
+	err := repo.repository.SaveItem(ctx, item)
+	repo.observe("SaveItem", start, err)
+
+	return err
+}
+
+func (repo *itemRepository) SaveItems(ctx context.Context, items []*domain.Item) error {
+	start := time.Now()
+	err := repo.repository.SaveItems(ctx, items)
+	repo.observe("SaveItems", start, err)
+
+	return err
+}
+
+func (repo *itemRepository) GetItemByID(ctx context.Context, id uint) (*domain.Item, error) {
+	start := time.Now()
+	item, err := repo.repository.GetItemByID(ctx, id)
+	repo.observe("GetItemByID", start, err)
+
+	return item, err
+}
+
+func (repo *itemRepository) GetItemsByIDs(ctx context.Context, ids []uint) ([]*domain.Item, error) {
+	start := time.Now()
+	items, err := repo.repository.GetItemsByIDs(ctx, ids)
+	repo.observe("GetItemsByIDs", start, err)
+
+	return items, err
+}
+
+func (repo *itemRepository) GetItemByCode(ctx context.Context, code string) (*domain.Item, error) {
+	start := time.Now()
+	item, err := repo.repository.GetItemByCode(ctx, code)
+	repo.observe("GetItemByCode", start, err)
+
+	return item, err
+}
+
+// StreamItems measures the whole stream, the time spent in fn and its errors included.
+func (repo *itemRepository) StreamItems(ctx context.Context, filter domain.ItemFilter,
+	fn func(*domain.Item) error) error {
+	start := time.Now()
+	err := repo.repository.StreamItems(ctx, filter, fn)
+	repo.observe("StreamItems", start, err)
+
+	return err
+}
+
+func (repo *itemRepository) UpdateItem(ctx context.Context, item *domain.Item) error {
+	start := time.Now()
+	err := repo.repository.UpdateItem(ctx, item)
+	repo.observe("UpdateItem", start, err)
+
+	return err
+}
+
+func (repo *itemRepository) DeleteItem(ctx context.Context, id uint, version uint) error {
+	start := time.Now()
+	err := repo.repository.DeleteItem(ctx, id, version)
+	repo.observe("DeleteItem", start, err)
+
+	return err
+}
+
+func (repo *itemRepository) CountItemsByStatus(ctx context.Context) (map[string]int, error) {
+	start := time.Now()
+	counts, err := repo.repository.CountItemsByStatus(ctx)
+	repo.observe("CountItemsByStatus", start, err)
+
+	return counts, err
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+// RegisterItemStatus registers a gauge of the items by status. Counting them scans the items
+// table, so the counts are reused by the scrapes for refreshInterval, and the scrapes that
+// arrive while they are counted wait for that count instead of starting another one.
+func RegisterItemStatus(registry *Registry, repository ports.ItemRepository, refreshInterval time.Duration) {
+	var (
+		mu        sync.Mutex
+		samples   []Sample
+		countedAt time.Time
+	)
+
+	registry.NewGaugeCollector("items", "Items by status.", []string{"status"},
+		func(ctx context.Context) ([]Sample, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if samples != nil && time.Since(countedAt) < refreshInterval {
+				return samples, nil
+			}
+
+			counts, err := repository.CountItemsByStatus(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			samples, countedAt = statusSamples(counts), time.Now()
+
+			return samples, nil
+		})
+}
+
+func statusSamples(counts map[string]int) []Sample {
+	// Both statuses are always written so a status left without items reads 0.
+	for _, status := range []string{domain.StatusActive, domain.StatusInactive} {
+		if _, ok := counts[status]; !ok {
+			counts[status] = 0
+		}
+	}
+
+	samples := make([]Sample, 0, len(counts))
+	for status, count := range counts {
+		samples = append(samples, Sample{LabelValues: []string{status}, Value: float64(count)})
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].LabelValues[0] < samples[j].LabelValues[0]
+	})
+
+	return samples
+}
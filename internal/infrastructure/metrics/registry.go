@@ -0,0 +1,219 @@
+// Package metrics keeps the metrics of the service and writes them in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// Sample is a value read by a CollectFunc, with the values of the labels of its metric.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// CollectFunc reads the samples of a metric kept elsewhere, e.g. by the database driver, when
+// the metrics are written.
+type CollectFunc func(ctx context.Context) ([]Sample, error)
+
+type family interface {
+	write(ctx context.Context, w *bufio.Writer) error
+}
+
+// Registry holds the metrics, which are written in the order they were created.
+type Registry struct {
+	mu       sync.Mutex
+	names    map[string]bool
+	families []family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// NewCounter creates a counter with the given labels. It panics when the name is taken.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	counter := &Counter{vector: newVector(name, help, kindCounter, labels)}
+	r.register(name, counter)
+
+	return counter
+}
+
+// NewGauge creates a gauge with the given labels. It panics when the name is taken.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	gauge := &Gauge{vector: newVector(name, help, kindGauge, labels)}
+	r.register(name, gauge)
+
+	return gauge
+}
+
+// NewHistogram creates a histogram with the given upper bounds, in increasing order, and
+// labels. It panics when the name is taken.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic("metrics: buckets of " + name + " are not sorted")
+	}
+
+	histogram := &Histogram{vector: newVector(name, help, kindHistogram, labels), buckets: buckets}
+	r.register(name, histogram)
+
+	return histogram
+}
+
+// NewCounterCollector creates a counter whose samples are read by collect every time the
+// metrics are written. It panics when the name is taken.
+func (r *Registry) NewCounterCollector(name, help string, labels []string, collect CollectFunc) {
+	r.register(name, &collector{
+		header:  header{name: name, help: help, kind: kindCounter, labels: labels},
+		collect: collect,
+	})
+}
+
+// NewGaugeCollector creates a gauge whose samples are read by collect every time the metrics
+// are written. It panics when the name is taken.
+func (r *Registry) NewGaugeCollector(name, help string, labels []string, collect CollectFunc) {
+	r.register(name, &collector{
+		header:  header{name: name, help: help, kind: kindGauge, labels: labels},
+		collect: collect,
+	})
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[name] {
+		panic("metrics: " + name + " is already registered")
+	}
+
+	r.names[name] = true
+	r.families = append(r.families, f)
+}
+
+// Write writes every metric. A collector that fails leaves out its metric, and the first
+// of those errors is returned once the others have been written.
+func (r *Registry) Write(ctx context.Context, w io.Writer) error {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+
+	var collectErr error
+
+	for _, f := range families {
+		if err := f.write(ctx, buf); err != nil && collectErr == nil {
+			collectErr = err
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	return collectErr
+}
+
+type header struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (h header) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", h.name, escapeHelp(h.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", h.name, h.kind)
+}
+
+// writeSample writes a line of the metric, suffixed e.g. with _bucket, with the labels of the
+// metric and an extra label when extraName is not empty.
+func (h header) writeSample(w *bufio.Writer, suffix string, labelValues []string, extraName string,
+	extraValue string, value float64) {
+	w.WriteString(h.name + suffix)
+
+	if len(h.labels) > 0 || extraName != "" {
+		pairs := make([]string, 0, len(h.labels)+1)
+		for i, label := range h.labels {
+			pairs = append(pairs, label+`="`+escapeLabel(labelValues[i])+`"`)
+		}
+
+		if extraName != "" {
+			pairs = append(pairs, extraName+`="`+extraValue+`"`)
+		}
+
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+type collector struct {
+	header
+	collect CollectFunc
+}
+
+func (c *collector) write(ctx context.Context, w *bufio.Writer) error {
+	samples, err := c.collect(ctx)
+	if err != nil {
+		return fmt.Errorf("error collecting %s: %w", c.name, err)
+	}
+
+	for _, sample := range samples {
+		if len(sample.LabelValues) != len(c.labels) {
+			return fmt.Errorf("error collecting %s: got %d label values, want %d", c.name,
+				len(sample.LabelValues), len(c.labels))
+		}
+	}
+
+	c.header.write(w)
+
+	for _, sample := range samples {
+		c.writeSample(w, "", sample.LabelValues, "", "", sample.Value)
+	}
+
+	return nil
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
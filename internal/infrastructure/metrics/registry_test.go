@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.NewCounter("http_requests_total", "Requests served.", "route", "status")
+	registry.NewGauge("jobs_running", "Jobs running.\nIn this instance, see C:\\jobs.")
+	duration := registry.NewHistogram("http_request_duration_seconds", "Request durations.",
+		[]float64{0.25, 1, 2}, "route")
+	registry.NewGaugeCollector("items", "Items by status.", []string{"status"},
+		func(ctx context.Context) ([]Sample, error) {
+			return []Sample{{LabelValues: []string{"ACTIVE"}, Value: 3}, {LabelValues: []string{"INACTIVE"}}}, nil
+		})
+
+	requests.Inc("/v1/items/{id}", "200")
+	requests.Add(2, "/v1/items/{id}", "200")
+	requests.Inc("/v1/items", "201")
+	requests.Inc(`a\b"c`+"\nd", "500")
+
+	for _, value := range []float64{0.25, 0.5, 4} {
+		duration.Observe(value, "/v1/items")
+	}
+
+	want := `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{route="/v1/items",status="201"} 1
+http_requests_total{route="/v1/items/{id}",status="200"} 3
+http_requests_total{route="a\\b\"c\nd",status="500"} 1
+# HELP jobs_running Jobs running.\nIn this instance, see C:\\jobs.
+# TYPE jobs_running gauge
+jobs_running 0
+# HELP http_request_duration_seconds Request durations.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/v1/items",le="0.25"} 1
+http_request_duration_seconds_bucket{route="/v1/items",le="1"} 2
+http_request_duration_seconds_bucket{route="/v1/items",le="2"} 2
+http_request_duration_seconds_bucket{route="/v1/items",le="+Inf"} 3
+http_request_duration_seconds_sum{route="/v1/items"} 4.75
+http_request_duration_seconds_count{route="/v1/items"} 3
+# HELP items Items by status.
+# TYPE items gauge
+items{status="ACTIVE"} 3
+items{status="INACTIVE"} 0
+`
+
+	var buf bytes.Buffer
+	if err := registry.Write(context.Background(), &buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistryWriteLeavesOutTheFailingCollectors(t *testing.T) {
+	errCollect := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		collect CollectFunc
+		wantErr string
+	}{
+		{
+			name: "collector error",
+			collect: func(ctx context.Context) ([]Sample, error) {
+				return nil, errCollect
+			},
+			wantErr: "error collecting broken: connection refused",
+		},
+		{
+			name: "samples without their labels",
+			collect: func(ctx context.Context) ([]Sample, error) {
+				return []Sample{{LabelValues: []string{"ACTIVE"}, Value: 1}, {Value: 2}}, nil
+			},
+			wantErr: "error collecting broken: got 0 label values, want 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := NewRegistry()
+			registry.NewGaugeCollector("broken", "Fails.", []string{"status"}, test.collect)
+			registry.NewCounter("after_total", "Written after the failing collector.").Inc()
+
+			var buf bytes.Buffer
+
+			err := registry.Write(context.Background(), &buf)
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("Write() error = %v, want %s", err, test.wantErr)
+			}
+
+			if strings.Contains(buf.String(), "broken") {
+				t.Errorf("Write() = %q, want the failing collector left out", buf.String())
+			}
+
+			if !strings.Contains(buf.String(), "after_total 1\n") {
+				t.Errorf("Write() = %q, want the metrics after the failing collector", buf.String())
+			}
+		})
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(registry *Registry)
+	}{
+		{
+			name: "name taken",
+			fn: func(registry *Registry) {
+				registry.NewCounter("requests_total", "Requests.")
+				registry.NewGauge("requests_total", "Requests.")
+			},
+		},
+		{
+			name: "buckets not sorted",
+			fn: func(registry *Registry) {
+				registry.NewHistogram("duration_seconds", "Durations.", []float64{1, 0.5})
+			},
+		},
+		{
+			name: "label values missing",
+			fn: func(registry *Registry) {
+				registry.NewCounter("requests_total", "Requests.", "route", "status").Inc("/v1/items")
+			},
+		},
+		{
+			name: "counter decreased",
+			fn: func(registry *Registry) {
+				registry.NewCounter("requests_total", "Requests.").Add(-1)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("want a panic")
+				}
+			}()
+
+			test.fn(NewRegistry())
+		})
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{value: 0, want: "0"},
+		{value: 3, want: "3"},
+		{value: 0.005, want: "0.005"},
+		{value: 1e21, want: "1e+21"},
+	}
+
+	for _, test := range tests {
+		if got := formatFloat(test.value); got != test.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
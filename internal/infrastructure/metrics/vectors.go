@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// HTTPBuckets bound the durations of the requests, in seconds.
+	HTTPBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// StorageBuckets bound the durations of the operations of a repository, in seconds.
+	StorageBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+)
+
+// labelSeparator joins the values of the labels of a series into its key. It can not be
+// part of a valid UTF-8 label value.
+const labelSeparator = "\xff"
+
+// vector keeps a series per combination of label values, written in the order of their values.
+type vector struct {
+	header
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	// counts has the count of every bucket of a histogram, not cumulative, plus +Inf.
+	counts []uint64
+	sum    float64
+}
+
+// newVector starts a metric without labels at 0, so it is written before its first change.
+func newVector(name, help, kind string, labels []string) *vector {
+	v := &vector{
+		header: header{name: name, help: help, kind: kind, labels: labels},
+		series: map[string]*series{},
+	}
+
+	if len(labels) == 0 && kind != kindHistogram {
+		v.series[""] = &series{}
+	}
+
+	return v
+}
+
+// with calls fn with the series of the label values, holding the lock of the vector.
+func (v *vector) with(labelValues []string, fn func(s *series)) {
+	if len(labelValues) != len(v.labels) {
+		panic("metrics: " + v.name + " takes " + strings.Join(v.labels, ", "))
+	}
+
+	key := strings.Join(labelValues, labelSeparator)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+
+	fn(s)
+}
+
+// snapshot copies the series in the order of their label values, so they are written without
+// holding the lock.
+func (v *vector) snapshot() []series {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	snapshot := make([]series, 0, len(v.series))
+	for _, s := range v.series {
+		s := *s
+		s.counts = append([]uint64(nil), s.counts...)
+		snapshot = append(snapshot, s)
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		return lessLabelValues(snapshot[i].labelValues, snapshot[j].labelValues)
+	})
+
+	return snapshot
+}
+
+// lessLabelValues orders the label values by the first label, then the next one, and so on.
+func lessLabelValues(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return false
+}
+
+func (v *vector) write(ctx context.Context, w *bufio.Writer) error {
+	v.header.write(w)
+
+	for _, s := range v.snapshot() {
+		v.writeSample(w, "", s.labelValues, "", "", s.value)
+	}
+
+	return nil
+}
+
+// Counter is a value that only goes up, e.g. the requests served.
+type Counter struct {
+	*vector
+}
+
+// Inc adds one to the series of the label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a value, which must not be negative, to the series of the label values.
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		panic("metrics: counter " + c.name + " can not decrease")
+	}
+
+	c.with(labelValues, func(s *series) {
+		s.value += value
+	})
+}
+
+// Gauge is a value that goes up and down, e.g. the requests in flight.
+type Gauge struct {
+	*vector
+}
+
+// Set sets the series of the label values.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.with(labelValues, func(s *series) {
+		s.value = value
+	})
+}
+
+// Add adds a value, which may be negative, to the series of the label values.
+func (g *Gauge) Add(value float64, labelValues ...string) {
+	g.with(labelValues, func(s *series) {
+		s.value += value
+	})
+}
+
+// Histogram counts observations, e.g. durations, in buckets by their upper bound.
+type Histogram struct {
+	*vector
+	buckets []float64
+}
+
+// Observe adds a value to the series of the label values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	bucket := sort.SearchFloat64s(h.buckets, value)
+
+	h.with(labelValues, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(h.buckets)+1)
+		}
+
+		s.counts[bucket]++
+		s.sum += value
+	})
+}
+
+func (h *Histogram) write(ctx context.Context, w *bufio.Writer) error {
+	h.header.write(w)
+
+	for _, s := range h.snapshot() {
+		var count uint64
+
+		for i, upperBound := range h.buckets {
+			count += s.counts[i]
+			h.writeSample(w, "_bucket", s.labelValues, "le", formatFloat(upperBound), float64(count))
+		}
+
+		count += s.counts[len(h.buckets)]
+		h.writeSample(w, "_bucket", s.labelValues, "le", formatFloat(math.Inf(1)), float64(count))
+		h.writeSample(w, "_sum", s.labelValues, "", "", s.sum)
+		h.writeSample(w, "_count", s.labelValues, "", "", float64(count))
+	}
+
+	return nil
+}
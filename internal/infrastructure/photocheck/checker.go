@@ -0,0 +1,171 @@
+// Package photocheck requests the URLs of the photos given by URL to find the broken ones.
+package photocheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+// maxDrainBytes bounds what is read of a response body so the connection can be reused.
+const maxDrainBytes = 4 << 10
+
+// requestFailed is the error of every check whose request got no response: the error of the
+// client, e.g. a refused connection, would tell which ports of a host are open.
+const requestFailed = "request failed"
+
+// HTTPClient sends the requests of the checker. The client of NewHTTPClient fits, as does any
+// client that wraps it, e.g. to add retries or credentials.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type urlChecker struct {
+	client      HTTPClient
+	hosts       []string
+	concurrency int
+}
+
+// NewURLChecker only requests the URLs of the hosts, see Config.Hosts.
+func NewURLChecker(client HTTPClient, hosts []string, concurrency int) (ports.PhotoURLChecker, error) {
+	if client == nil {
+		return nil, fmt.Errorf("http client cannot be nil")
+	}
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("allowed hosts cannot be empty")
+	}
+
+	if concurrency <= 0 {
+		return nil, fmt.Errorf("concurrency must be positive")
+	}
+
+	return &urlChecker{
+		client:      client,
+		hosts:       hosts,
+		concurrency: concurrency,
+	}, nil
+}
+
+// CheckURLs requests every URL once, with at most concurrency requests at the same time.
+func (c *urlChecker) CheckURLs(ctx context.Context, urls []string) map[string]domain.PhotoCheck {
+	checks := make(map[string]domain.PhotoCheck, len(urls))
+	pending := make(chan string)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for photoURL := range pending {
+				check := c.checkURL(ctx, photoURL)
+
+				mu.Lock()
+				checks[photoURL] = check
+				mu.Unlock()
+			}
+		}()
+	}
+
+	seen := make(map[string]bool, len(urls))
+	for _, photoURL := range urls {
+		if !seen[photoURL] {
+			seen[photoURL] = true
+			pending <- photoURL
+		}
+	}
+
+	close(pending)
+	wg.Wait()
+
+	return checks
+}
+
+// checkURL sends a HEAD request, or a GET of the first byte when the server does not allow
+// HEAD. A response below 400, after the redirects, is a working photo. A URL the checker
+// refuses to request is broken, while a request without a response is unreachable.
+func (c *urlChecker) checkURL(ctx context.Context, photoURL string) domain.PhotoCheck {
+	check := domain.PhotoCheck{CheckedAt: time.Now()}
+
+	parsed, err := url.Parse(photoURL)
+	if err == nil {
+		err = allowedURL(c.hosts, parsed)
+	}
+
+	var statusCode int
+	if err == nil {
+		statusCode, err = c.request(ctx, http.MethodHead, photoURL)
+	}
+
+	if err == nil && (statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented) {
+		statusCode, err = c.request(ctx, http.MethodGet, photoURL)
+	}
+
+	check.StatusCode = statusCode
+
+	switch {
+	case refused(err) != nil:
+		check.Status = domain.PhotoCheckBroken
+		check.Error = refused(err).Error()
+	case err != nil:
+		check.Status = domain.PhotoCheckUnreachable
+		check.Error = requestFailed
+	case statusCode >= http.StatusBadRequest:
+		check.Status = domain.PhotoCheckBroken
+		check.Error = http.StatusText(statusCode)
+	default:
+		check.Status = domain.PhotoCheckOK
+	}
+
+	return check
+}
+
+// refused returns the reason why the checker or its client refused to request a URL, or nil.
+func refused(err error) error {
+	for _, reason := range []error{errHostNotAllowed, errAddressNotAllowed, errRedirectNotAllowed} {
+		if errors.Is(err, reason) {
+			return reason
+		}
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Op == "parse" {
+		return errHostNotAllowed
+	}
+
+	return nil
+}
+
+func (c *urlChecker) request(ctx context.Context, method string, photoURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, photoURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxDrainBytes))
+
+	return res.StatusCode, nil
+}
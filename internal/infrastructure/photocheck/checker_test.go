@@ -0,0 +1,135 @@
+package photocheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+func newServer(t *testing.T) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests++
+
+		switch req.URL.Path {
+		case "/missing.jpg":
+			res.WriteHeader(http.StatusNotFound)
+		case "/moved.jpg":
+			http.Redirect(res, req, "http://internal.example.com/photo.jpg", http.StatusFound)
+		default:
+			res.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func check(t *testing.T, client HTTPClient, hosts []string, photoURL string) domain.PhotoCheck {
+	t.Helper()
+
+	checker, err := NewURLChecker(client, hosts, 1)
+	if err != nil {
+		t.Fatalf("NewURLChecker() error = %v", err)
+	}
+
+	return checker.CheckURLs(context.Background(), []string{photoURL})[photoURL]
+}
+
+func TestCheckURLs(t *testing.T) {
+	server, _ := newServer(t)
+	hosts := []string{"127.0.0.1"}
+
+	// The client of NewHTTPClient refuses the loopback address of the test server.
+	client := NewHTTPClient(Config{Timeout: time.Second, Concurrency: 1, Hosts: hosts})
+	client.Transport = http.DefaultTransport
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name   string
+		url    string
+		status string
+		error  string
+	}{
+		{name: "ok", url: server.URL + "/photo.jpg", status: domain.PhotoCheckOK},
+		{name: "missing", url: server.URL + "/missing.jpg", status: domain.PhotoCheckBroken, error: "Not Found"},
+		{name: "redirect to another host", url: server.URL + "/moved.jpg", status: domain.PhotoCheckBroken,
+			error: "redirect not allowed"},
+		{name: "no response", url: closed.URL + "/photo.jpg", status: domain.PhotoCheckUnreachable,
+			error: "request failed"},
+		{name: "scheme", url: "file:///etc/passwd", status: domain.PhotoCheckBroken, error: "host not allowed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := check(t, client, hosts, test.url)
+			if got.Status != test.status || got.Error != test.error {
+				t.Errorf("check = %s %q, want %s %q", got.Status, got.Error, test.status, test.error)
+			}
+		})
+	}
+}
+
+func TestCheckURLsOnlyRequestsTheAllowedHosts(t *testing.T) {
+	server, requests := newServer(t)
+
+	got := check(t, http.DefaultClient, []string{"images.example.com"}, server.URL+"/photo.jpg")
+	if got.Status != domain.PhotoCheckBroken || got.Error != "host not allowed" || *requests != 0 {
+		t.Errorf("check = %s %q after %d requests, want broken %q without any request",
+			got.Status, got.Error, *requests, "host not allowed")
+	}
+
+	if _, err := NewURLChecker(http.DefaultClient, nil, 1); err == nil {
+		t.Error("NewURLChecker() without allowed hosts did not fail")
+	}
+}
+
+func TestNewHTTPClientRefusesInternalAddresses(t *testing.T) {
+	server, requests := newServer(t)
+	client := NewHTTPClient(Config{Timeout: time.Second, Concurrency: 1, Hosts: []string{"127.0.0.1"}})
+
+	got := check(t, client, []string{"127.0.0.1"}, server.URL+"/photo.jpg")
+	if got.Status != domain.PhotoCheckBroken || got.Error != "address not allowed" || *requests != 0 {
+		t.Errorf("check = %s %q after %d requests, want broken %q without any request",
+			got.Status, got.Error, *requests, "address not allowed")
+	}
+
+	for _, address := range []string{"10.1.2.3:80", "169.254.169.254:80", "[::1]:443", "0.0.0.0:80", "192.168.0.1:80"} {
+		if publicAddress("tcp", address, nil) == nil {
+			t.Errorf("publicAddress(%s) accepted an internal address", address)
+		}
+	}
+
+	if err := publicAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("publicAddress() of a public address error = %v", err)
+	}
+}
+
+func TestAllowedURL(t *testing.T) {
+	hosts := []string{"images.example.com", "*.cdn.example.com"}
+
+	for photoURL, want := range map[string]bool{
+		"https://images.example.com/a.jpg":    true,
+		"https://eu.cdn.example.com/a.jpg":    true,
+		"https://cdn.example.com/a.jpg":       false,
+		"https://images.example.com.evil/a":   false,
+		"gopher://images.example.com/a.jpg":   false,
+		"https://user@images.example.com/a.j": true,
+	} {
+		parsed, err := url.Parse(photoURL)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) error = %v", photoURL, err)
+		}
+
+		if got := allowedURL(hosts, parsed) == nil; got != want {
+			t.Errorf("allowedURL(%q) = %v, want %v", photoURL, got, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package photocheck
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+// maxRedirects is the limit of the default client of net/http.
+const maxRedirects = 10
+
+var (
+	errHostNotAllowed     = errors.New("host not allowed")
+	errAddressNotAllowed  = errors.New("address not allowed")
+	errRedirectNotAllowed = errors.New("redirect not allowed")
+)
+
+// NewHTTPClient returns the client the checker needs to request URLs given by users: it only
+// connects to public addresses, checked after the name is resolved so a name can not point
+// at the internal network, and only follows redirects to the allowed hosts. Proxies are not
+// used, as the address of the proxy is the only one the client would check.
+func NewHTTPClient(config Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: config.Timeout,
+		Control: publicAddress,
+	}
+
+	return &http.Client{
+		Timeout: config.Timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConnsPerHost:   config.Concurrency,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   config.Timeout,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects || allowedURL(config.Hosts, req.URL) != nil {
+				return errRedirectNotAllowed
+			}
+
+			return nil
+		},
+	}
+}
+
+// publicAddress refuses the connections to the loopback, private, link-local, multicast and
+// unspecified addresses.
+func publicAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errAddressNotAllowed
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s %s", errAddressNotAllowed, network, address)
+	}
+
+	return nil
+}
+
+// allowedURL checks the scheme and the host of a URL before it is requested.
+func allowedURL(hosts []string, photoURL *url.URL) error {
+	scheme := strings.ToLower(photoURL.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return errHostNotAllowed
+	}
+
+	if !domain.PhotoHostAllowed(hosts, strings.ToLower(photoURL.Hostname())) {
+		return errHostNotAllowed
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package photocheck
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	photoCheckInterval        = "PHOTO_CHECK_INTERVAL"
+	photoCheckTimeout         = "PHOTO_CHECK_TIMEOUT"
+	photoCheckConcurrency     = "PHOTO_CHECK_CONCURRENCY"
+	photoCheckDeactivate      = "PHOTO_CHECK_DEACTIVATE"
+	photoCheckDeactivateAfter = "PHOTO_CHECK_DEACTIVATE_AFTER"
+	photoURLHosts             = "PHOTO_URL_HOSTS"
+)
+
+type Config struct {
+	// Interval is the time between the start of a check of every photo URL and the next one.
+	Interval time.Duration
+	// Timeout bounds every request, redirects included.
+	Timeout time.Duration
+	// Concurrency is how many URLs are requested at the same time.
+	Concurrency int
+	// Deactivate makes the items whose primary photo is broken inactive.
+	Deactivate bool
+	// DeactivateAfter is how many checks in a row must find a primary photo broken before its
+	// item is made inactive.
+	DeactivateAfter int
+	// Hosts are the hosts the checker requests, the same setting as the allowlist of the photo
+	// URLs, see validation.Config.PhotoURLHosts. Without them the photos are not checked: a URL
+	// stored before the allowlist was set could point anywhere.
+	Hosts []string
+}
+
+func LoadConfig() Config {
+	deactivate, _ := strconv.ParseBool(os.Getenv(photoCheckDeactivate))
+
+	return Config{
+		Interval:        getEnvDuration(photoCheckInterval, 24*time.Hour),
+		Timeout:         getEnvDuration(photoCheckTimeout, 10*time.Second),
+		Concurrency:     getEnvInt(photoCheckConcurrency, 8),
+		Deactivate:      deactivate,
+		DeactivateAfter: getEnvInt(photoCheckDeactivateAfter, 3),
+		Hosts:           splitList(os.Getenv(photoURLHosts)),
+	}
+}
+
+// splitList returns the lower-cased non-empty values of a comma separated list.
+func splitList(value string) []string {
+	values := make([]string, 0)
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			values = append(values, field)
+		}
+	}
+
+	return values
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
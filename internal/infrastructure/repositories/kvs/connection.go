@@ -7,6 +7,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/mercadolibre/fury_go-toolkit-config/pkg/config"
 	"github.com/mercadolibre/go-meli-toolkit/gokvsclient"
+	"github.com/osalomon89/test-crud-api/pkg/log"
 )
 
 const productionEnv string = "production"
@@ -46,7 +47,7 @@ func GetKVSConnection() (gokvsclient.Client, error) {
 
 func loadENVConfigs() {
 	if err := godotenv.Load("../../.env"); err != nil {
-		panic(err.Error())
+		panic(log.Redact(err.Error()))
 	}
 
 	environment = map[string]string{
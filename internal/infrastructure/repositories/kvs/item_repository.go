@@ -20,6 +20,7 @@ type Item struct {
 	Leader      bool
 	LeaderLevel string
 	Status      string
+	Version     uint
 	Photos      []string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
@@ -45,6 +46,7 @@ func (repo *itemRepository) SaveItem(ctx context.Context, item *domain.Item) err
 
 	if itemExist != nil {
 		return domain.ItemError{
+			Code:    domain.ErrCodeItemCodeDuplicated,
 			Message: "The item code must be unique",
 		}
 	}
@@ -61,6 +63,7 @@ func (repo *itemRepository) SaveItem(ctx context.Context, item *domain.Item) err
 	itemDTO.Leader = item.Leader
 	itemDTO.LeaderLevel = item.LeaderLevel
 	itemDTO.Status = item.Status
+	itemDTO.Version = 1
 	itemDTO.CreatedAt = createdAt
 	itemDTO.UpdatedAt = createdAt
 
@@ -73,12 +76,86 @@ func (repo *itemRepository) SaveItem(ctx context.Context, item *domain.Item) err
 		return fmt.Errorf("error saving item in KVS: %w", err)
 	}
 
+	item.Version = itemDTO.Version
 	item.CreatedAt = createdAt
 	item.UpdatedAt = createdAt
 
 	return nil
 }
 
+// SaveItems is not supported because KVS has no transactions to save a batch all-or-nothing.
+func (repo *itemRepository) SaveItems(ctx context.Context, items []*domain.Item) error {
+	return fmt.Errorf("saving items in bulk is not supported by the KVS repository")
+}
+
+// StreamItems is not supported because KVS can not be scanned by attributes.
+func (repo *itemRepository) StreamItems(ctx context.Context, filter domain.ItemFilter,
+	fn func(*domain.Item) error) error {
+	return fmt.Errorf("streaming items is not supported by the KVS repository")
+}
+
+// UpdateItem looks the item up by code, since that is the key items are saved with in KVS.
+func (repo *itemRepository) UpdateItem(ctx context.Context, item *domain.Item) error {
+	kvsItem, err := repo.itemExist(item.Code)
+	if err != nil {
+		return err
+	}
+
+	if kvsItem == nil {
+		return domain.ResourceNotFoundError{
+			Message: "Item not found",
+		}
+	}
+
+	itemDTO := new(Item)
+	if err := kvsItem.GetValue(itemDTO); err != nil {
+		return fmt.Errorf("error unmarshaling item: %w", err)
+	}
+
+	if item.Version != 0 && itemDTO.Version != item.Version {
+		return domain.ConflictError{
+			Message: fmt.Sprintf("Item version mismatch: expected %d, current %d", item.Version, itemDTO.Version),
+		}
+	}
+
+	updatedAt := time.Now()
+
+	itemDTO.Title = item.Title
+	itemDTO.Description = item.Description
+	itemDTO.Price = item.Price
+	itemDTO.Stock = item.Stock
+	itemDTO.ItemType = item.ItemType
+	itemDTO.Leader = item.Leader
+	itemDTO.LeaderLevel = item.LeaderLevel
+	itemDTO.Status = item.Status
+	itemDTO.Version++
+	itemDTO.UpdatedAt = updatedAt
+	itemDTO.Photos = nil
+
+	for _, photo := range item.Photos {
+		itemDTO.Photos = append(itemDTO.Photos, photo.Path)
+	}
+
+	if err := repo.client.Save(gokvsclient.MakeItem(itemDTO.Code, itemDTO)); err != nil {
+		return fmt.Errorf("error saving item in KVS: %w", err)
+	}
+
+	item.Version = itemDTO.Version
+	item.UpdatedAt = updatedAt
+
+	return nil
+}
+
+// DeleteItem is not supported because KVS items are keyed by code and have no ID.
+func (repo *itemRepository) DeleteItem(ctx context.Context, id uint, version uint) error {
+	return fmt.Errorf("deleting items by ID is not supported by the KVS repository")
+}
+
+// CountItemsByStatus is not supported because KVS can not be scanned by attributes.
+func (repo *itemRepository) CountItemsByStatus(ctx context.Context) (map[string]int, error) {
+	return nil, fmt.Errorf("counting items is not supported by the KVS repository")
+}
+
 func (repo *itemRepository) itemExist(code string) (gokvsclient.Item, error) {
 	kvsItem, err := repo.client.Get(code)
 	if err != nil {
@@ -102,6 +179,31 @@ func (repo *itemRepository) GetItemByID(ctx context.Context, id uint) (*domain.I
 	return repo.createItem(item), nil
 }
 
+// GetItemsByIDs is not supported because KVS items are keyed by code and have no ID.
+func (repo *itemRepository) GetItemsByIDs(ctx context.Context, ids []uint) ([]*domain.Item, error) {
+	return nil, fmt.Errorf("getting items by ID is not supported by the KVS repository")
+}
+
+func (repo *itemRepository) GetItemByCode(ctx context.Context, code string) (*domain.Item, error) {
+	kvsItem, err := repo.itemExist(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if kvsItem == nil {
+		return nil, domain.ResourceNotFoundError{
+			Message: "Item not found",
+		}
+	}
+
+	item := new(Item)
+	if err := kvsItem.GetValue(item); err != nil {
+		return nil, fmt.Errorf("error unmarshaling item: %w", err)
+	}
+
+	return repo.createItem(item), nil
+}
+
 func (repo *itemRepository) createItem(itemDTO *Item) *domain.Item {
 	item := new(domain.Item)
 
@@ -114,6 +216,7 @@ func (repo *itemRepository) createItem(itemDTO *Item) *domain.Item {
 	item.Leader = itemDTO.Leader
 	item.LeaderLevel = itemDTO.LeaderLevel
 	item.Status = itemDTO.Status
+	item.Version = itemDTO.Version
 	item.CreatedAt = itemDTO.CreatedAt
 	item.UpdatedAt = itemDTO.UpdatedAt
 
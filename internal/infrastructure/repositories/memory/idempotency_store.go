@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+type idempotencyStore struct {
+	mutex     sync.Mutex
+	records   map[string]domain.IdempotencyRecord
+	lastSweep time.Time
+}
+
+func NewIdempotencyStore() ports.IdempotencyStore {
+	return &idempotencyStore{
+		records:   map[string]domain.IdempotencyRecord{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (store *idempotencyStore) Reserve(ctx context.Context, key, fingerprint string,
+	ttl, lease time.Duration) (string, *domain.IdempotencyRecord, error) {
+	owner, err := newOwnerToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("error generating idempotency owner token: %w", err)
+	}
+
+	now := time.Now()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.sweep(now)
+
+	record, ok := store.records[key]
+	if ok && now.Before(record.ExpiresAt) && (record.Completed || now.Before(record.LeaseExpiresAt)) {
+		return "", &record, nil
+	}
+
+	store.records[key] = domain.IdempotencyRecord{
+		Key:            key,
+		Owner:          owner,
+		Fingerprint:    fingerprint,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+		LeaseExpiresAt: now.Add(lease),
+	}
+
+	return owner, nil, nil
+}
+
+func (store *idempotencyStore) Renew(ctx context.Context, key, owner string, lease time.Duration) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	record, ok := store.records[key]
+	if !ok || record.Owner != owner || record.Completed {
+		return fmt.Errorf("idempotency key %s is not reserved", key)
+	}
+
+	record.LeaseExpiresAt = time.Now().Add(lease)
+	store.records[key] = record
+
+	return nil
+}
+
+func (store *idempotencyStore) Complete(ctx context.Context, record domain.IdempotencyRecord) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	stored, ok := store.records[record.Key]
+	if !ok || stored.Owner != record.Owner || stored.Completed {
+		return fmt.Errorf("idempotency key %s is not reserved", record.Key)
+	}
+
+	record.Completed = true
+	record.CreatedAt = stored.CreatedAt
+	record.ExpiresAt = stored.ExpiresAt
+	record.LeaseExpiresAt = time.Time{}
+	store.records[record.Key] = record
+
+	return nil
+}
+
+func (store *idempotencyStore) Release(ctx context.Context, key, owner string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if record, ok := store.records[key]; ok && record.Owner == owner && !record.Completed {
+		delete(store.records, key)
+	}
+
+	return nil
+}
+
+func (store *idempotencyStore) PurgeExpired(ctx context.Context, limit int) (int, error) {
+	now := time.Now()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	purged := 0
+
+	for key, record := range store.records {
+		if purged == limit {
+			break
+		}
+
+		if !now.Before(record.ExpiresAt) {
+			delete(store.records, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (store *idempotencyStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, record := range store.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(store.records, key)
+		}
+	}
+
+	store.lastSweep = now
+}
+
+func newOwnerToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/search"
+)
+
+// BM25 parameters, with the usual values.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// fieldWeights count a term of the code or the title as that many occurrences, so they rank
+// above a mention in the description.
+var fieldWeights = map[string]int{ //nolint:gochecknoglobals
+	"code":        3,
+	"title":       2,
+	"description": 1,
+}
+
+type searchDocument struct {
+	item   domain.Item
+	fields map[string]string
+	terms  map[string]int
+	length int
+}
+
+// itemSearchIndex is an inverted index of the items kept in process and ranked with BM25.
+// It is lost on restart, so it has to be filled with a reindex at startup, and it is only
+// updated by the writes of its own process, so it is not shared by the replicas of the API.
+type itemSearchIndex struct {
+	mutex       sync.RWMutex
+	documents   map[uint]*searchDocument
+	postings    map[string]map[uint]int
+	totalLength int
+}
+
+func NewItemSearchIndex() ports.ItemSearchIndex {
+	return &itemSearchIndex{
+		documents: map[uint]*searchDocument{},
+		postings:  map[string]map[uint]int{},
+	}
+}
+
+func (index *itemSearchIndex) IndexItem(ctx context.Context, item *domain.Item) error {
+	document := &searchDocument{
+		item: *item,
+		fields: map[string]string{
+			"code":        item.Code,
+			"title":       item.Title,
+			"description": item.Description,
+		},
+		terms: map[string]int{},
+	}
+
+	for field, text := range document.fields {
+		for _, token := range search.Tokenize(text) {
+			document.terms[token.Term] += fieldWeights[field]
+			document.length += fieldWeights[field]
+		}
+	}
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.remove(item.ID)
+
+	index.documents[item.ID] = document
+	index.totalLength += document.length
+
+	for term, frequency := range document.terms {
+		if index.postings[term] == nil {
+			index.postings[term] = map[uint]int{}
+		}
+		index.postings[term][item.ID] = frequency
+	}
+
+	return nil
+}
+
+func (index *itemSearchIndex) RemoveItem(ctx context.Context, id uint) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.remove(id)
+
+	return nil
+}
+
+// Search ranks every item with at least one of the terms of the query, or lists by ID every
+// item when the query has no text, and counts the facets of the matching items. A text made
+// only of stop words has no terms and matches nothing, as in the MySQL index.
+func (index *itemSearchIndex) Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error) {
+	terms := search.Terms(query.Text)
+
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	candidates := index.score(terms)
+	if strings.TrimSpace(query.Text) == "" {
+		for id := range index.documents {
+			candidates[id] = 0
+		}
+	}
+
+	hits := make([]domain.SearchHit, 0, len(candidates))
+	for id, score := range candidates {
+		if query.Filter.Matches(&index.documents[id].item) {
+			hits = append(hits, domain.SearchHit{ItemID: id, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ItemID < hits[j].ItemID
+	})
+
+	result := &domain.SearchResult{
+		Total:  len(hits),
+		Hits:   searchPage(hits, query.Offset, query.Limit),
+		Facets: index.facets(candidates, query),
+	}
+
+	for i := range result.Hits {
+		result.Hits[i].Highlights = search.Highlights(index.documents[result.Hits[i].ItemID].fields, terms)
+	}
+
+	return result, nil
+}
+
+// facets counts, for every facet, the values of the candidates matching the filter without that facet.
+func (index *itemSearchIndex) facets(candidates map[uint]float64,
+	query domain.SearchQuery) map[string][]domain.FacetCount {
+	counts := make(map[string]map[string]int, len(domain.Facets))
+	filters := make(map[string]domain.ItemFilter, len(domain.Facets))
+
+	for _, facet := range domain.Facets {
+		counts[facet] = map[string]int{}
+		filters[facet] = query.Filter.Without(facet)
+	}
+
+	for id := range candidates {
+		item := &index.documents[id].item
+		for _, facet := range domain.Facets {
+			if filters[facet].Matches(item) {
+				counts[facet][domain.FacetValue(item, facet, query.PriceRanges)]++
+			}
+		}
+	}
+
+	return domain.NewFacetCounts(counts, query.Filter, query.PriceRanges)
+}
+
+func (index *itemSearchIndex) score(terms []string) map[uint]float64 {
+	scores := map[uint]float64{}
+	if len(index.documents) == 0 {
+		return scores
+	}
+
+	documents := float64(len(index.documents))
+	averageLength := float64(index.totalLength) / documents
+
+	for _, term := range terms {
+		postings := index.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		frequency := float64(len(postings))
+		idf := math.Log(1 + (documents-frequency+0.5)/(frequency+0.5))
+
+		for id, termFrequency := range postings {
+			tf := float64(termFrequency)
+			norm := 1 - bm25B + bm25B*float64(index.documents[id].length)/averageLength
+			scores[id] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	return scores
+}
+
+// remove drops a document from the index, the caller holds the write lock.
+func (index *itemSearchIndex) remove(id uint) {
+	document, ok := index.documents[id]
+	if !ok {
+		return
+	}
+
+	for term := range document.terms {
+		delete(index.postings[term], id)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+
+	index.totalLength -= document.length
+	delete(index.documents, id)
+}
+
+func searchPage(hits []domain.SearchHit, offset, limit int) []domain.SearchHit {
+	if offset >= len(hits) {
+		return []domain.SearchHit{}
+	}
+
+	end := len(hits)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	return hits[offset:end]
+}
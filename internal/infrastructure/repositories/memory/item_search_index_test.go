@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+func TestItemSearchIndexSearch(t *testing.T) {
+	ctx := context.Background()
+	index := NewItemSearchIndex()
+
+	for _, item := range []domain.Item{
+		{ID: 1, Code: "L-1", Title: "Desk lamp", Description: "A lamp for the desk"},
+		{ID: 2, Code: "C-1", Title: "Office chair", Description: "A chair with wheels"},
+		{ID: 3, Code: "L-2", Title: "Floor lamp", Description: "Lamps for the living room"},
+	} {
+		item := item
+		if err := index.IndexItem(ctx, &item); err != nil {
+			t.Fatalf("IndexItem() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		text string
+		want []uint
+	}{
+		{text: "desk lamp", want: []uint{1, 3}},
+		{text: "lamps", want: []uint{1, 3}},
+		{text: "wheels", want: []uint{2}},
+		{text: "the of with", want: []uint{}},
+		{text: "  ", want: []uint{1, 2, 3}},
+	}
+
+	for _, test := range tests {
+		result, err := index.Search(ctx, domain.SearchQuery{Text: test.text})
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", test.text, err)
+		}
+
+		got := make([]uint, 0, len(result.Hits))
+		for _, hit := range result.Hits {
+			got = append(got, hit.ItemID)
+		}
+
+		if len(got) != len(test.want) || result.Total != len(test.want) {
+			t.Errorf("Search(%q) = %v, want %v", test.text, got, test.want)
+			continue
+		}
+
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", test.text, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+// TestItemSearchIndexFacets checks that the counts of a facet ignore its own selection, so the
+// other values of a selected facet keep their counts, and follow the selection of the others.
+func TestItemSearchIndexFacets(t *testing.T) {
+	ctx := context.Background()
+	index := NewItemSearchIndex()
+
+	for _, item := range []domain.Item{
+		{ID: 1, Title: "Desk lamp", Status: domain.StatusActive, ItemType: domain.ItemTypeOwn, Price: 500},
+		{ID: 2, Title: "Floor lamp", Status: domain.StatusActive, ItemType: domain.ItemTypeSeller, Price: 1500},
+		{ID: 3, Title: "Wall lamp", Status: domain.StatusInactive, ItemType: domain.ItemTypeSeller, Price: 2500},
+		{ID: 4, Title: "Office chair", Status: domain.StatusActive, ItemType: domain.ItemTypeSeller, Price: 2500},
+	} {
+		item := item
+		if err := index.IndexItem(ctx, &item); err != nil {
+			t.Fatalf("IndexItem() error = %v", err)
+		}
+	}
+
+	priceRanges := domain.PriceRanges([]int{1000, 2000})
+
+	tests := []struct {
+		name      string
+		text      string
+		filter    domain.ItemFilter
+		wantTotal int
+		want      map[string]map[string]int
+	}{
+		{
+			name:      "without a filter",
+			text:      "lamp",
+			wantTotal: 3,
+			want: map[string]map[string]int{
+				domain.FacetStatus:   {domain.StatusActive: 2, domain.StatusInactive: 1},
+				domain.FacetItemType: {domain.ItemTypeOwn: 1, domain.ItemTypeSeller: 2},
+				domain.FacetPrice:    {"*-1000": 1, "1000-2000": 1, "2000-*": 1},
+			},
+		},
+		{
+			name:      "status selected",
+			text:      "lamp",
+			filter:    domain.ItemFilter{Status: domain.StatusActive},
+			wantTotal: 2,
+			want: map[string]map[string]int{
+				domain.FacetStatus:   {domain.StatusActive: 2, domain.StatusInactive: 1},
+				domain.FacetItemType: {domain.ItemTypeOwn: 1, domain.ItemTypeSeller: 1},
+				domain.FacetPrice:    {"*-1000": 1, "1000-2000": 1, "2000-*": 0},
+			},
+		},
+		{
+			name:      "status and type selected",
+			filter:    domain.ItemFilter{Status: domain.StatusActive, ItemType: domain.ItemTypeSeller},
+			wantTotal: 2,
+			want: map[string]map[string]int{
+				domain.FacetStatus:   {domain.StatusActive: 2, domain.StatusInactive: 1},
+				domain.FacetItemType: {domain.ItemTypeOwn: 1, domain.ItemTypeSeller: 2},
+				domain.FacetPrice:    {"*-1000": 0, "1000-2000": 1, "2000-*": 1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := index.Search(ctx, domain.SearchQuery{Text: test.text, Filter: test.filter,
+				PriceRanges: priceRanges})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			if result.Total != test.wantTotal {
+				t.Errorf("Search() total = %d, want %d", result.Total, test.wantTotal)
+			}
+
+			for facet, want := range test.want {
+				for _, count := range result.Facets[facet] {
+					if count.Count != want[count.Value] {
+						t.Errorf("facet %s value %s count = %d, want %d", facet, count.Value, count.Count, want[count.Value])
+					}
+
+					if count.Selected != domain.FacetSelected(test.filter, facet, count.Value) {
+						t.Errorf("facet %s value %s selected = %v", facet, count.Value, count.Selected)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,473 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/search"
+)
+
+const (
+	// maxSuggestions is how many entries every node of the trie keeps, the largest page of suggestions.
+	maxSuggestions = 20
+	// fuzzyMinPrefix is the shortest prefix also matched with one typo, shorter ones match too much.
+	fuzzyMinPrefix = 3
+	// fuzzyPenalty scales the score of the suggestions matched with a typo.
+	fuzzyPenalty = 0.5
+	// maxKeyLength bounds the keys of a title, so the trie grows with the words of the titles and
+	// not with their length. A longer prefix is looked up by its start, checked against the text
+	// and never matched with a typo.
+	maxKeyLength = 24
+)
+
+// suggestEntry is the title or the code of an item. Keys are the folded texts it is found by:
+// the code, or up to maxKeyLength runes of the title from the start of each of its words.
+type suggestEntry struct {
+	itemID uint
+	kind   string
+	text   string
+	folded string
+	weight float64
+	keys   []string
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// entries are the entries with a key ending at this node.
+	entries []*suggestEntry
+	// best are the heaviest entries of the subtree, so a prefix is answered without walking it.
+	best []*suggestEntry
+}
+
+type suggestTrie struct {
+	root    *trieNode
+	entries map[uint][]*suggestEntry
+}
+
+// itemSuggester completes prefixes with a trie of the titles and codes of the items, weighted
+// by the stock of the item as its popularity.
+type itemSuggester struct {
+	mutex sync.RWMutex
+	trie  *suggestTrie
+	// rebuilding serializes the rebuilds, and pending holds the writes made while one runs, replayed
+	// on the new trie before it is swapped in. Both pending and the trie are guarded by mutex.
+	rebuilding sync.Mutex
+	pending    []func(*suggestTrie)
+	recording  bool
+}
+
+func NewItemSuggester() ports.ItemSuggester {
+	return &itemSuggester{trie: newSuggestTrie()}
+}
+
+func (suggester *itemSuggester) IndexItem(ctx context.Context, item *domain.Item) error {
+	suggester.mutex.Lock()
+	defer suggester.mutex.Unlock()
+
+	indexed := *item
+	suggester.apply(func(trie *suggestTrie) { trie.add(&indexed) })
+
+	return nil
+}
+
+func (suggester *itemSuggester) RemoveItem(ctx context.Context, id uint) error {
+	suggester.mutex.Lock()
+	defer suggester.mutex.Unlock()
+
+	suggester.apply(func(trie *suggestTrie) { trie.remove(id) })
+
+	return nil
+}
+
+// apply makes a write on the current trie, and records it while a rebuild runs. The caller holds
+// the write lock.
+func (suggester *itemSuggester) apply(write func(*suggestTrie)) {
+	write(suggester.trie)
+
+	if suggester.recording {
+		suggester.pending = append(suggester.pending, write)
+	}
+}
+
+// Rebuild fills a new trie and swaps it in once complete. The writes made while it runs are
+// replayed on the new trie first, so none is lost when the stream read an item before it.
+func (suggester *itemSuggester) Rebuild(ctx context.Context, stream func(fn func(*domain.Item) error) error) error {
+	suggester.rebuilding.Lock()
+	defer suggester.rebuilding.Unlock()
+
+	suggester.mutex.Lock()
+	suggester.recording = true
+	suggester.mutex.Unlock()
+
+	trie := newSuggestTrie()
+
+	err := stream(func(item *domain.Item) error {
+		trie.add(item)
+		return ctx.Err()
+	})
+
+	suggester.mutex.Lock()
+	defer suggester.mutex.Unlock()
+
+	pending := suggester.pending
+	suggester.pending = nil
+	suggester.recording = false
+
+	if err != nil {
+		return err
+	}
+
+	for _, write := range pending {
+		write(trie)
+	}
+
+	suggester.trie = trie
+
+	return nil
+}
+
+func (suggester *itemSuggester) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Suggestion, error) {
+	query := []rune(search.Fold(strings.TrimSpace(prefix)))
+	if len(query) == 0 {
+		return []domain.Suggestion{}, nil
+	}
+
+	if limit <= 0 || limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+
+	suggester.mutex.RLock()
+	defer suggester.mutex.RUnlock()
+
+	scores := map[*suggestEntry]float64{}
+	fuzzy := map[*suggestEntry]bool{}
+
+	if len(query) >= fuzzyMinPrefix {
+		for _, node := range suggester.trie.fuzzyNodes(query) {
+			for _, entry := range node.best {
+				if score := entry.weight * fuzzyPenalty; score > scores[entry] {
+					scores[entry] = score
+					fuzzy[entry] = true
+				}
+			}
+		}
+	}
+
+	if node := suggester.trie.find(keyOf(query)); node != nil {
+		for _, entry := range node.best {
+			if len(query) > maxKeyLength && !entry.matches(string(query)) {
+				continue
+			}
+
+			scores[entry] = entry.weight
+			fuzzy[entry] = false
+		}
+	}
+
+	suggestions := make([]domain.Suggestion, 0, len(scores))
+	for entry, score := range scores {
+		suggestions = append(suggestions, domain.Suggestion{
+			Text:   entry.text,
+			Kind:   entry.kind,
+			ItemID: entry.itemID,
+			Score:  score,
+			Fuzzy:  fuzzy[entry],
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if suggestions[i].ItemID != suggestions[j].ItemID {
+			return suggestions[i].ItemID < suggestions[j].ItemID
+		}
+		return suggestions[i].Kind < suggestions[j].Kind
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
+
+func newSuggestTrie() *suggestTrie {
+	return &suggestTrie{
+		root:    &trieNode{children: map[rune]*trieNode{}},
+		entries: map[uint][]*suggestEntry{},
+	}
+}
+
+// add indexes the title and the code of an item, replacing its previous version.
+func (trie *suggestTrie) add(item *domain.Item) {
+	trie.remove(item.ID)
+
+	stock := item.Stock
+	if stock < 0 {
+		stock = 0
+	}
+	weight := 1 + math.Log1p(float64(stock))
+
+	var entries []*suggestEntry
+	if title := strings.TrimSpace(item.Title); title != "" {
+		folded := search.Fold(title)
+
+		var keys []string
+		for _, start := range wordStarts(folded) {
+			keys = append(keys, string(keyOf([]rune(folded[start:]))))
+		}
+
+		entries = append(entries, &suggestEntry{
+			itemID: item.ID,
+			kind:   domain.SuggestionKindTitle,
+			text:   title,
+			folded: folded,
+			weight: weight,
+			keys:   keys,
+		})
+	}
+
+	if code := strings.TrimSpace(item.Code); code != "" {
+		entries = append(entries, &suggestEntry{
+			itemID: item.ID,
+			kind:   domain.SuggestionKindCode,
+			text:   code,
+			folded: search.Fold(code),
+			weight: weight,
+			keys:   []string{string(keyOf([]rune(search.Fold(code))))},
+		})
+	}
+
+	for _, entry := range entries {
+		for _, key := range entry.keys {
+			trie.insert([]rune(key), entry)
+		}
+	}
+
+	if len(entries) > 0 {
+		trie.entries[item.ID] = entries
+	}
+}
+
+func (trie *suggestTrie) remove(itemID uint) {
+	for _, entry := range trie.entries[itemID] {
+		for _, key := range entry.keys {
+			trie.delete([]rune(key), entry)
+		}
+	}
+
+	delete(trie.entries, itemID)
+}
+
+func (trie *suggestTrie) insert(key []rune, entry *suggestEntry) {
+	node := trie.root
+	node.best = withEntry(node.best, entry)
+
+	for _, r := range key {
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{children: map[rune]*trieNode{}}
+			node.children[r] = child
+		}
+
+		node = child
+		node.best = withEntry(node.best, entry)
+	}
+
+	node.entries = append(node.entries, entry)
+}
+
+// delete removes an entry from the node of a key, then recomputes the best entries of the
+// nodes on its path from the bottom up, pruning the nodes left empty.
+func (trie *suggestTrie) delete(key []rune, entry *suggestEntry) {
+	path := []*trieNode{trie.root}
+	for _, r := range key {
+		child, ok := path[len(path)-1].children[r]
+		if !ok {
+			return
+		}
+		path = append(path, child)
+	}
+
+	last := path[len(path)-1]
+	for i, candidate := range last.entries {
+		if candidate == entry {
+			last.entries = append(last.entries[:i], last.entries[i+1:]...)
+			break
+		}
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		node := path[i]
+		if i > 0 && len(node.entries) == 0 && len(node.children) == 0 {
+			delete(path[i-1].children, key[i-1])
+			continue
+		}
+
+		node.best = bestOf(node)
+	}
+}
+
+func (trie *suggestTrie) find(prefix []rune) *trieNode {
+	node := trie.root
+	for _, r := range prefix {
+		child, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	return node
+}
+
+// fuzzyNodes returns the shallowest nodes whose path is at edit distance 1 at most of the
+// prefix, walking the trie with a row of the Levenshtein matrix per node and pruning the
+// subtrees where every cell of the row is above 1.
+func (trie *suggestTrie) fuzzyNodes(prefix []rune) []*trieNode {
+	row := make([]int, len(prefix)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	var nodes []*trieNode
+	for r, child := range trie.root.children {
+		nodes = fuzzyWalk(child, r, prefix, row, nodes)
+	}
+
+	return nodes
+}
+
+func fuzzyWalk(node *trieNode, r rune, prefix []rune, previous []int, nodes []*trieNode) []*trieNode {
+	row := make([]int, len(previous))
+	row[0] = previous[0] + 1
+	minimum := row[0]
+
+	for j := 1; j < len(row); j++ {
+		substitution := previous[j-1]
+		if prefix[j-1] != r {
+			substitution++
+		}
+
+		row[j] = minInt(minInt(row[j-1]+1, previous[j]+1), substitution)
+		minimum = minInt(minimum, row[j])
+	}
+
+	if row[len(row)-1] <= 1 {
+		return append(nodes, node)
+	}
+
+	if minimum > 1 {
+		return nodes
+	}
+
+	for next, child := range node.children {
+		nodes = fuzzyWalk(child, next, prefix, row, nodes)
+	}
+
+	return nodes
+}
+
+// bestOf recomputes the best entries of a node from its own entries and the best of its children.
+func bestOf(node *trieNode) []*suggestEntry {
+	var best []*suggestEntry
+	for _, entry := range node.entries {
+		best = withEntry(best, entry)
+	}
+
+	for _, child := range node.children {
+		for _, entry := range child.best {
+			best = withEntry(best, entry)
+		}
+	}
+
+	return best
+}
+
+// withEntry adds an entry to a list of best entries, kept sorted and bounded to maxSuggestions.
+func withEntry(best []*suggestEntry, entry *suggestEntry) []*suggestEntry {
+	for _, candidate := range best {
+		if candidate == entry {
+			return best
+		}
+	}
+
+	position := sort.Search(len(best), func(i int) bool {
+		return best[i].weight < entry.weight ||
+			best[i].weight == entry.weight && best[i].itemID > entry.itemID
+	})
+	if position >= maxSuggestions {
+		return best
+	}
+
+	best = append(best, nil)
+	copy(best[position+1:], best[position:])
+	best[position] = entry
+
+	if len(best) > maxSuggestions {
+		best = best[:maxSuggestions]
+	}
+
+	return best
+}
+
+// matches reports whether the entry is found by a prefix longer than its keys.
+func (entry *suggestEntry) matches(prefix string) bool {
+	if entry.kind == domain.SuggestionKindCode {
+		return strings.HasPrefix(entry.folded, prefix)
+	}
+
+	return hasWordPrefix(entry.folded, prefix)
+}
+
+// wordStarts returns the byte offsets where the words of a text start, so a title is suggested
+// for the start of any of its words.
+func wordStarts(text string) []int {
+	var starts []int
+
+	inWord := false
+	for i, r := range text {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && !inWord {
+			starts = append(starts, i)
+		}
+		inWord = isWordRune
+	}
+
+	return starts
+}
+
+// keyOf bounds a key, or a prefix looked up, to maxKeyLength runes.
+func keyOf(text []rune) []rune {
+	if len(text) > maxKeyLength {
+		return text[:maxKeyLength]
+	}
+
+	return text
+}
+
+// hasWordPrefix reports whether the text has the prefix from the start of one of its words.
+func hasWordPrefix(text, prefix string) bool {
+	for _, start := range wordStarts(text) {
+		if strings.HasPrefix(text[start:], prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+func suggestedIDs(t *testing.T, suggester ports.ItemSuggester, prefix string) map[uint]bool {
+	t.Helper()
+
+	suggestions, err := suggester.Suggest(context.Background(), prefix, 0)
+	if err != nil {
+		t.Fatalf("Suggest(%q) error = %v", prefix, err)
+	}
+
+	ids := map[uint]bool{}
+	for _, suggestion := range suggestions {
+		ids[suggestion.ItemID] = true
+	}
+
+	return ids
+}
+
+func TestItemSuggesterSuggest(t *testing.T) {
+	ctx := context.Background()
+	suggester := NewItemSuggester()
+
+	for _, item := range []domain.Item{
+		{ID: 1, Code: "LMP-1", Title: "Lámpara de escritorio con brazo articulado y base de metal", Stock: 3},
+		{ID: 2, Code: "CHR-1", Title: "Silla de escritorio", Stock: 10},
+	} {
+		item := item
+		if err := suggester.IndexItem(ctx, &item); err != nil {
+			t.Fatalf("IndexItem() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		want   []uint
+	}{
+		{prefix: "lampara", want: []uint{1}},
+		{prefix: "escri", want: []uint{1, 2}},
+		{prefix: "de escritorio", want: []uint{1, 2}},
+		{prefix: "escritorio con brazo articulado", want: []uint{1}},
+		{prefix: "escritorio con brazo articulada", want: []uint{}},
+		{prefix: "chr", want: []uint{2}},
+		{prefix: "sila", want: []uint{2}},
+	}
+
+	for _, test := range tests {
+		got := suggestedIDs(t, suggester, test.prefix)
+		if len(got) != len(test.want) {
+			t.Errorf("Suggest(%q) = %v, want %v", test.prefix, got, test.want)
+			continue
+		}
+
+		for _, id := range test.want {
+			if !got[id] {
+				t.Errorf("Suggest(%q) = %v, want %v", test.prefix, got, test.want)
+			}
+		}
+	}
+}
+
+func TestItemSuggesterRebuildKeepsTheWritesMadeWhileItRuns(t *testing.T) {
+	ctx := context.Background()
+	suggester := NewItemSuggester()
+
+	stale := domain.Item{ID: 1, Code: "A-1", Title: "Old lamp"}
+	removed := domain.Item{ID: 2, Code: "B-1", Title: "Chair"}
+
+	err := suggester.Rebuild(ctx, func(fn func(*domain.Item) error) error {
+		// The stream read both items before they were written again.
+		if err := fn(&stale); err != nil {
+			return err
+		}
+
+		if err := fn(&removed); err != nil {
+			return err
+		}
+
+		if err := suggester.IndexItem(ctx, &domain.Item{ID: 1, Code: "A-1", Title: "New lamp"}); err != nil {
+			return err
+		}
+
+		return suggester.RemoveItem(ctx, removed.ID)
+	})
+	if err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+
+	if !suggestedIDs(t, suggester, "new")[1] || suggestedIDs(t, suggester, "old")[1] {
+		t.Error("the item written during the rebuild was suggested by its old title")
+	}
+
+	if suggestedIDs(t, suggester, "chair")[2] {
+		t.Error("the item removed during the rebuild was suggested")
+	}
+}
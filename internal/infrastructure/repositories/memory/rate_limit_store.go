@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+	fullAt   time.Time
+}
+
+type rateLimitStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewRateLimitStore() ports.RateLimitStore {
+	return &rateLimitStore{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (store *rateLimitStore) Take(ctx context.Context, key string,
+	limit domain.RateLimit) (domain.RateLimitResult, error) {
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return domain.RateLimitResult{}, fmt.Errorf("invalid rate limit: rate %v, burst %d", limit.Rate, limit.Burst)
+	}
+
+	now := time.Now()
+	capacity := float64(limit.Burst)
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.sweep(now)
+
+	b, ok := store.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, lastSeen: now}
+		store.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.lastSeen).Seconds()*limit.Rate)
+	b.lastSeen = now
+
+	result := domain.RateLimitResult{Limit: limit.Burst}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.ResetAfter = secondsToDuration((capacity - b.tokens) / limit.Rate)
+	b.fullAt = now.Add(result.ResetAfter)
+
+	return result, nil
+}
+
+// sweep drops the buckets that have refilled completely, so keys seen once do not
+// stay in memory forever. A dropped bucket is recreated full, which is the same state.
+func (store *rateLimitStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, b := range store.buckets {
+		if now.After(b.fullAt) {
+			delete(store.buckets, key)
+		}
+	}
+
+	store.lastSweep = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,159 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const defaultAuditLimit = 100
+
+type AuditEntry struct {
+	ID         uint
+	Actor      string         `db:"actor"`
+	RequestID  string         `db:"request_id"`
+	ClientIP   string         `db:"client_ip"`
+	Operation  string         `db:"operation"`
+	EntityType string         `db:"entity_type"`
+	EntityID   uint           `db:"entity_id"`
+	Before     sql.NullString `db:"before_state"`
+	After      sql.NullString `db:"after_state"`
+	CreatedAt  time.Time      `db:"created_at"`
+	PrevHash   string         `db:"prev_hash"`
+	Hash       string         `db:"hash"`
+}
+
+type auditRepository struct {
+	conn *sqlx.DB
+}
+
+func NewAuditRepository(conn *sqlx.DB) (ports.AuditRepository, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("mysql connection cannot be nil")
+	}
+
+	return &auditRepository{conn: conn}, nil
+}
+
+// appendAuditEntry links the entry to the head of the chain and stores it inside tx, so the
+// entry of a write is committed with the write itself.
+func appendAuditEntry(tx *sqlx.Tx, entry *domain.AuditEntry) error {
+	// The head row serializes appends, so two entries can never be linked to the same predecessor.
+	var prevHash string
+	if err := tx.Get(&prevHash, "SELECT hash FROM audit_log_head WHERE id=1 FOR UPDATE"); err != nil {
+		return fmt.Errorf("error getting audit chain head: %w", err)
+	}
+
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	entry.PrevHash = prevHash
+	entry.Hash = entry.ComputeHash()
+
+	result, err := tx.Exec(`INSERT INTO audit_log
+		(actor, request_id, client_ip, operation, entity_type, entity_id, before_state, after_state, created_at, prev_hash, hash)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?)`, entry.Actor, entry.RequestID, entry.ClientIP, entry.Operation,
+		entry.EntityType, entry.EntityID, nullString(entry.Before), nullString(entry.After), entry.CreatedAt,
+		entry.PrevHash, entry.Hash)
+	if err != nil {
+		return fmt.Errorf("error saving audit entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error saving audit entry: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE audit_log_head SET hash=? WHERE id=1", entry.Hash); err != nil {
+		return fmt.Errorf("error updating audit chain head: %w", err)
+	}
+
+	entry.ID = uint(id)
+
+	return nil
+}
+
+func (repo *auditRepository) FindAuditEntries(ctx context.Context,
+	filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering AuditRepository. FindAuditEntries()")
+
+	conditions := []string{"id>?"}
+	args := []interface{}{filter.AfterID}
+
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type=?")
+		args = append(args, filter.EntityType)
+	}
+
+	if filter.EntityID != 0 {
+		conditions = append(conditions, "entity_id=?")
+		args = append(args, filter.EntityID)
+	}
+
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor=?")
+		args = append(args, filter.Actor)
+	}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at>=?")
+		args = append(args, filter.From.UTC())
+	}
+
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at<=?")
+		args = append(args, filter.To.UTC())
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	args = append(args, limit)
+
+	var entries []AuditEntry
+	query := fmt.Sprintf("SELECT * FROM audit_log WHERE %s ORDER BY id LIMIT ?", strings.Join(conditions, " AND "))
+	if err := repo.conn.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
+	}
+
+	entryModels := make([]domain.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		entryModels = append(entryModels, domain.AuditEntry{
+			ID:         entry.ID,
+			Actor:      entry.Actor,
+			RequestID:  entry.RequestID,
+			ClientIP:   entry.ClientIP,
+			Operation:  entry.Operation,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Before:     rawMessage(entry.Before),
+			After:      rawMessage(entry.After),
+			CreatedAt:  entry.CreatedAt,
+			PrevHash:   entry.PrevHash,
+			Hash:       entry.Hash,
+		})
+	}
+
+	return entryModels, nil
+}
+
+func nullString(raw json.RawMessage) sql.NullString {
+	return sql.NullString{String: string(raw), Valid: len(raw) > 0}
+}
+
+func rawMessage(value sql.NullString) json.RawMessage {
+	if !value.Valid {
+		return nil
+	}
+
+	return json.RawMessage(value.String)
+}
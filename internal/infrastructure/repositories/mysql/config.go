@@ -8,6 +8,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/mercadolibre/fury_go-toolkit-config/pkg/config"
 	"github.com/mercadolibre/go-meli-toolkit/gomelipass"
+	"github.com/osalomon89/test-crud-api/pkg/log"
 )
 
 const productionEnv string = "production"
@@ -48,7 +49,7 @@ func loadENVConfigs() {
 
 	if scope == "" {
 		if err := godotenv.Load("../../.env"); err != nil {
-			panic(err.Error())
+			panic(log.Redact(err.Error()))
 		}
 
 		environment = map[string]string{
@@ -63,7 +64,14 @@ func loadENVConfigs() {
 	}
 }
 
-// dbConnectionURL returns db connecction url
+// redactSecrets redacts the password from every log line from now on, wherever it ends up,
+// e.g. in an error with the connection url.
+func redactSecrets() {
+	log.RedactValue(environment[dbPass])
+}
+
+// dbConnectionURL returns db connecction url. It has the password, so it must only be
+// written through log.Redact.
 func dbConnectionURL() string {
 	return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True", environment[dbUser], environment[dbPass], environment[dbHost], environment[dbName])
 }
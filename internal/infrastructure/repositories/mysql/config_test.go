@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+type captureBackend struct {
+	lines []string
+}
+
+func (backend *captureBackend) Write(entry log.Entry) {
+	line := entry.Message
+	if entry.Err != nil {
+		line += " err:" + entry.Err.Error()
+	}
+
+	for _, field := range entry.Fields {
+		line += " " + field.String()
+	}
+
+	backend.lines = append(backend.lines, line)
+}
+
+// withEnvironment sets the config of the database and captures the log lines for the test.
+func withEnvironment(t *testing.T, pass string) *captureBackend {
+	t.Helper()
+
+	previous := environment
+	environment = map[string]string{
+		dbHost: "localhost:3306",
+		dbUser: "market",
+		dbPass: pass,
+		dbName: "testmarket",
+	}
+
+	backend := &captureBackend{}
+	log.SetBackend(backend)
+
+	t.Cleanup(func() {
+		environment = previous
+		log.SetBackend(log.ToolkitBackend())
+	})
+
+	return backend
+}
+
+// logConnectionURL writes the connection url in every part of a log line, the way the error
+// paths of the connection can.
+func logConnectionURL(t *testing.T) []string {
+	t.Helper()
+
+	url := dbConnectionURL()
+	logger := log.NewLogger("request-id").With(log.String("dsn", url), log.Any("url", []string{url}))
+
+	logger.Info(nil, map[string]string{"connection": url, dbPass: environment[dbPass]},
+		"connecting to %s", url)
+	logger.Error(nil, nil, fmt.Errorf("### DB ERROR: %w", errors.New("dial "+url+": refused")),
+		"error connecting to "+url)
+
+	return append(logger.GetTags(nil, map[string]string{"connection": url}),
+		log.Redact("error connecting to DB: "+url))
+}
+
+func assertNotLeaked(t *testing.T, pass string, lines []string) {
+	t.Helper()
+
+	for _, line := range lines {
+		if strings.Contains(line, pass) {
+			t.Errorf("the password leaked in %q", line)
+		}
+	}
+}
+
+func TestDBConnectionURLIsRedactedByPattern(t *testing.T) {
+	// Never given to log.RedactValue, so only the patterns can redact it.
+	pass := "Unregistered:pa55@word#!"
+	backend := withEnvironment(t, pass)
+
+	tags := logConnectionURL(t)
+
+	if len(backend.lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(backend.lines))
+	}
+
+	assertNotLeaked(t, pass, backend.lines)
+	assertNotLeaked(t, pass, tags)
+
+	if !strings.Contains(backend.lines[0], "market:[REDACTED]@tcp(localhost:3306)/testmarket") {
+		t.Errorf("the connection url lost more than its password in %q", backend.lines[0])
+	}
+}
+
+func TestDBConnectionURLIsRedactedByValue(t *testing.T) {
+	// The spaces keep the patterns from finding the whole password.
+	pass := "s3cret with spaces@tcp("
+	backend := withEnvironment(t, pass)
+
+	redactSecrets()
+	t.Cleanup(func() { log.ForgetValue(pass) })
+
+	tags := logConnectionURL(t)
+
+	assertNotLeaked(t, pass, backend.lines)
+	assertNotLeaked(t, pass, tags)
+
+	for _, word := range strings.Fields(pass) {
+		assertNotLeaked(t, word, backend.lines)
+		assertNotLeaked(t, word, tags)
+	}
+}
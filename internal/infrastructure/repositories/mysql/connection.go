@@ -1,81 +1,70 @@
-package mysql
-
-import (
-	"fmt"
-	"os"
-
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/jmoiron/sqlx"
-)
-
-var db *sqlx.DB //nolint:gochecknoglobals
-
-func GetConnectionDB() (*sqlx.DB, error) {
-	var err error
-	env := os.Getenv("GO_ENVIRONMENT")
-
-	if db == nil {
-		if err := load(env); err != nil {
-			return nil, fmt.Errorf("### CONFIGS ERROR: %w", err)
-		}
-
-		db, err = sqlx.Connect("mysql", dbConnectionURL())
-		if err != nil {
-			fmt.Printf("########## DB ERROR: " + err.Error() + " #############")
-			return nil, fmt.Errorf("### DB ERROR: %w", err)
-		}
-	}
-
-	if env != productionEnv {
-		if err := migrate(db); err != nil {
-			return nil, err
-		}
-	}
-
-	return db, nil
-}
-
-func migrate(db *sqlx.DB) error {
-	var itemsSchema = `
-	CREATE TABLE IF NOT EXISTS items (
-		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
-		code varchar(191) DEFAULT NULL,
-		title longtext,
-		description longtext,
-		price bigint(20) DEFAULT NULL,
-		stock bigint(20) DEFAULT NULL,
-		item_type longtext,
-		leader tinyint(1) DEFAULT NULL,
-		leader_level longtext,
-		status longtext,
-		created_at datetime(3) DEFAULT NULL,
-		updated_at datetime(3) DEFAULT NULL,
-		PRIMARY KEY (id),
-		UNIQUE KEY code (code)
-	  );`
-
-	_, err := db.Exec(itemsSchema)
-	if err != nil {
-		fmt.Printf("########## DB ERROR: " + err.Error() + " #############")
-		return fmt.Errorf("### MIGRATION ERROR: %w", err)
-	}
-
-	var photosSchema = `CREATE TABLE IF NOT EXISTS photos (
-		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
-		path longtext,
-		item_id bigint(20) unsigned DEFAULT NULL,
-		created_at datetime(3) DEFAULT NULL,
-		updated_at datetime(3) DEFAULT NULL,
-		PRIMARY KEY (id),
-		KEY fk_items_photos (item_id),
-		CONSTRAINT fk_items_photos FOREIGN KEY (item_id) REFERENCES items (id)
-	  );`
-
-	_, err = db.Exec(photosSchema)
-	if err != nil {
-		fmt.Printf("########## DB ERROR: " + err.Error() + " #############")
-		return fmt.Errorf("### MIGRATION ERROR: %w", err)
-	}
-
-	return nil
-}
+package mysql
+
+import (
+	"fmt"
+	"os"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+var db *sqlx.DB //nolint:gochecknoglobals
+
+// GetConnectionDB connects to the database and migrates it, except in production, where the
+// migrations are applied with itemctl migrate before deploying and only checked here.
+func GetConnectionDB() (*sqlx.DB, error) {
+	env := os.Getenv("GO_ENVIRONMENT")
+
+	conn, err := connect(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if env == productionEnv {
+		if err := checkSchema(conn); err != nil {
+			return nil, err
+		}
+
+		return conn, nil
+	}
+
+	if _, err := Migrate(conn); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// MigrateDB connects to the database of the environment, production included, and applies the
+// migrations not applied yet.
+func MigrateDB() ([]Migration, error) {
+	conn, err := connect(os.Getenv("GO_ENVIRONMENT"))
+	if err != nil {
+		return nil, err
+	}
+
+	return Migrate(conn)
+}
+
+func connect(env string) (*sqlx.DB, error) {
+	if db != nil {
+		return db, nil
+	}
+
+	if err := load(env); err != nil {
+		return nil, fmt.Errorf("### CONFIGS ERROR: %w", err)
+	}
+
+	redactSecrets()
+
+	conn, err := sqlx.Connect("mysql", dbConnectionURL())
+	if err != nil {
+		fmt.Printf("########## DB ERROR: %s #############", log.Redact(err.Error()))
+		return nil, fmt.Errorf("### DB ERROR: %w", err)
+	}
+
+	db = conn
+
+	return db, nil
+}
@@ -0,0 +1,202 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type IdempotencyRecord struct {
+	Key            string         `db:"idempotency_key"`
+	Owner          sql.NullString `db:"owner"`
+	Fingerprint    string         `db:"fingerprint"`
+	Completed      bool           `db:"completed"`
+	StatusCode     sql.NullInt64  `db:"status_code"`
+	Header         sql.NullString `db:"header"`
+	Body           []byte         `db:"body"`
+	CreatedAt      time.Time      `db:"created_at"`
+	ExpiresAt      time.Time      `db:"expires_at"`
+	LeaseExpiresAt sql.NullTime   `db:"lease_expires_at"`
+}
+
+type idempotencyStore struct {
+	conn *sqlx.DB
+}
+
+func NewIdempotencyStore(conn *sqlx.DB) (ports.IdempotencyStore, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("mysql connection cannot be nil")
+	}
+
+	return &idempotencyStore{conn: conn}, nil
+}
+
+func (store *idempotencyStore) Reserve(ctx context.Context, key, fingerprint string,
+	ttl, lease time.Duration) (string, *domain.IdempotencyRecord, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering IdempotencyStore. Reserve()")
+
+	owner, err := newClaimToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("error generating idempotency owner token: %w", err)
+	}
+
+	now := time.Now()
+
+	_, err = store.conn.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE idempotency_key=? AND expires_at<=?",
+		key, now)
+	if err != nil {
+		return "", nil, fmt.Errorf("error deleting expired idempotency key: %w", err)
+	}
+
+	// The request holding the key died if it let its lease expire, the rows reserved before
+	// the leases existed included.
+	result, err := store.conn.ExecContext(ctx, `UPDATE idempotency_keys
+		SET owner=?, fingerprint=?, created_at=?, expires_at=?, lease_expires_at=?
+		WHERE idempotency_key=? AND completed=0 AND (lease_expires_at IS NULL OR lease_expires_at<=?)`,
+		owner, fingerprint, now, now.Add(ttl), now.Add(lease), key, now)
+	if err != nil {
+		return "", nil, fmt.Errorf("error taking over idempotency key: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+		logger.Warn(store, nil, "took over idempotency key whose lease expired")
+		return owner, nil, nil
+	}
+
+	_, err = store.conn.ExecContext(ctx, `INSERT INTO idempotency_keys
+		(idempotency_key, owner, fingerprint, completed, created_at, expires_at, lease_expires_at)
+		VALUES(?,?,?,?,?,?,?)`,
+		key, owner, fingerprint, false, now, now.Add(ttl), now.Add(lease))
+	if err == nil {
+		return owner, nil, nil
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 {
+		return "", nil, fmt.Errorf("error reserving idempotency key: %w", err)
+	}
+
+	record := new(IdempotencyRecord)
+	err = store.conn.GetContext(ctx, record, "SELECT * FROM idempotency_keys WHERE idempotency_key=?", key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The owner released the key in the meantime, let the caller try again.
+			return store.Reserve(ctx, key, fingerprint, ttl, lease)
+		}
+
+		return "", nil, fmt.Errorf("error getting idempotency key: %w", err)
+	}
+
+	recordModel, err := store.unmarshalRecord(record)
+
+	return "", recordModel, err
+}
+
+func (store *idempotencyStore) Renew(ctx context.Context, key, owner string, lease time.Duration) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering IdempotencyStore. Renew()")
+
+	result, err := store.conn.ExecContext(ctx, `UPDATE idempotency_keys SET lease_expires_at=?
+		WHERE idempotency_key=? AND owner=? AND completed=0`, time.Now().Add(lease), key, owner)
+	if err != nil {
+		return fmt.Errorf("error renewing idempotency key: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("idempotency key %s is not reserved", key)
+	}
+
+	return nil
+}
+
+func (store *idempotencyStore) Complete(ctx context.Context, record domain.IdempotencyRecord) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering IdempotencyStore. Complete()")
+
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("error marshaling response header: %w", err)
+	}
+
+	result, err := store.conn.ExecContext(ctx, `UPDATE idempotency_keys
+		SET completed=?, status_code=?, header=?, body=?, lease_expires_at=NULL
+		WHERE idempotency_key=? AND owner=? AND completed=0`,
+		true, record.StatusCode, string(header), record.Body, record.Key, record.Owner)
+	if err != nil {
+		return fmt.Errorf("error completing idempotency key: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("idempotency key %s is not reserved", record.Key)
+	}
+
+	return nil
+}
+
+func (store *idempotencyStore) Release(ctx context.Context, key, owner string) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering IdempotencyStore. Release()")
+
+	_, err := store.conn.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE idempotency_key=? AND owner=? AND completed=0", key, owner)
+	if err != nil {
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (store *idempotencyStore) PurgeExpired(ctx context.Context, limit int) (int, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(store, nil, "Entering IdempotencyStore. PurgeExpired()")
+
+	result, err := store.conn.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at<=? LIMIT ?",
+		time.Now(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("error purging expired idempotency keys: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error purging expired idempotency keys: %w", err)
+	}
+
+	return int(purged), nil
+}
+
+func (store *idempotencyStore) unmarshalRecord(record *IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	recordModel := domain.IdempotencyRecord{
+		Key:         record.Key,
+		Owner:       record.Owner.String,
+		Fingerprint: record.Fingerprint,
+		Completed:   record.Completed,
+		StatusCode:  int(record.StatusCode.Int64),
+		Body:        record.Body,
+		CreatedAt:   record.CreatedAt,
+		ExpiresAt:   record.ExpiresAt,
+	}
+
+	if record.LeaseExpiresAt.Valid {
+		recordModel.LeaseExpiresAt = record.LeaseExpiresAt.Time
+	}
+
+	if record.Header.Valid {
+		recordModel.Header = http.Header{}
+		if err := json.Unmarshal([]byte(record.Header.String), &recordModel.Header); err != nil {
+			return nil, fmt.Errorf("error unmarshaling response header: %w", err)
+		}
+	}
+
+	return &recordModel, nil
+}
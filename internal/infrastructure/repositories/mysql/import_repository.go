@@ -0,0 +1,345 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// importErrorRows bounds the rows of a multi-row insert of import errors.
+const importErrorRows = 500
+
+// importRowRows bounds the rows of a multi-row insert of import rows, which are much larger
+// than errors, keeping the statements below the default max_allowed_packet.
+const importRowRows = 100
+
+type Import struct {
+	ID            uint
+	Format        string         `db:"format"`
+	DryRun        bool           `db:"dry_run"`
+	Status        string         `db:"status"`
+	TotalRows     int            `db:"total_rows"`
+	ProcessedRows int            `db:"processed_rows"`
+	CreatedRows   int            `db:"created_rows"`
+	UpdatedRows   int            `db:"updated_rows"`
+	FailedRows    int            `db:"failed_rows"`
+	JobID         sql.NullInt64  `db:"job_id"`
+	Error         sql.NullString `db:"error"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+	CompletedAt   sql.NullTime   `db:"completed_at"`
+}
+
+type ImportError struct {
+	ID       uint
+	ImportID uint           `db:"import_id"`
+	Line     int            `db:"line"`
+	Field    string         `db:"field"`
+	Code     string         `db:"code"`
+	Message  string         `db:"message"`
+	Args     sql.NullString `db:"args"`
+}
+
+type ImportRow struct {
+	ID       uint
+	ImportID uint   `db:"import_id"`
+	Line     int    `db:"line"`
+	Content  string `db:"content"`
+}
+
+type importRepository struct {
+	conn *sqlx.DB
+}
+
+func NewImportRepository(conn *sqlx.DB) (ports.ImportRepository, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("mysql connection cannot be nil")
+	}
+
+	return &importRepository{conn: conn}, nil
+}
+
+func (repo *importRepository) SaveImport(ctx context.Context, itemImport *domain.Import) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. SaveImport()")
+
+	createdAt := time.Now()
+	result, err := repo.conn.ExecContext(ctx, `INSERT INTO imports
+		(format, dry_run, status, total_rows, created_at, updated_at)
+		VALUES(?,?,?,?,?,?)`, itemImport.Format, itemImport.DryRun, itemImport.Status, itemImport.TotalRows,
+		createdAt, createdAt)
+	if err != nil {
+		return fmt.Errorf("error saving import: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error saving import: %w", err)
+	}
+
+	itemImport.ID = uint(id)
+	itemImport.CreatedAt = createdAt
+	itemImport.UpdatedAt = createdAt
+
+	return nil
+}
+
+func (repo *importRepository) UpdateImport(ctx context.Context, itemImport *domain.Import) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. UpdateImport()")
+
+	updatedAt := time.Now()
+	_, err := repo.conn.ExecContext(ctx, `UPDATE imports SET status=?, total_rows=?, processed_rows=?,
+		created_rows=?, updated_rows=?, failed_rows=?, job_id=?, error=?, updated_at=?, completed_at=?
+		WHERE id=?`,
+		itemImport.Status, itemImport.TotalRows, itemImport.ProcessedRows, itemImport.CreatedRows,
+		itemImport.UpdatedRows, itemImport.FailedRows,
+		sql.NullInt64{Int64: int64(itemImport.JobID), Valid: itemImport.JobID != 0},
+		sql.NullString{String: itemImport.Error, Valid: itemImport.Error != ""}, updatedAt,
+		itemImport.CompletedAt, itemImport.ID)
+	if err != nil {
+		return fmt.Errorf("error updating import: %w", err)
+	}
+
+	itemImport.UpdatedAt = updatedAt
+
+	return nil
+}
+
+func (repo *importRepository) GetImport(ctx context.Context, id uint) (*domain.Import, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. GetImport()")
+
+	itemImport := new(Import)
+	err := repo.conn.GetContext(ctx, itemImport, "SELECT * FROM imports WHERE id=?", id)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, domain.ResourceNotFoundError{
+				Message: "Import not found",
+			}
+		default:
+			return nil, fmt.Errorf("error getting import: %w", err)
+		}
+	}
+
+	importModel := &domain.Import{
+		ID:            itemImport.ID,
+		Format:        itemImport.Format,
+		DryRun:        itemImport.DryRun,
+		Status:        itemImport.Status,
+		TotalRows:     itemImport.TotalRows,
+		ProcessedRows: itemImport.ProcessedRows,
+		CreatedRows:   itemImport.CreatedRows,
+		UpdatedRows:   itemImport.UpdatedRows,
+		FailedRows:    itemImport.FailedRows,
+		JobID:         uint(itemImport.JobID.Int64),
+		Error:         itemImport.Error.String,
+		CreatedAt:     itemImport.CreatedAt,
+		UpdatedAt:     itemImport.UpdatedAt,
+	}
+
+	if itemImport.CompletedAt.Valid {
+		importModel.CompletedAt = &itemImport.CompletedAt.Time
+	}
+
+	return importModel, nil
+}
+
+func (repo *importRepository) SaveImportRows(ctx context.Context, importID uint, rows []domain.ImportRow) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. SaveImportRows()")
+
+	tx, err := repo.conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("transaction initialization error: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for start := 0; start < len(rows); start += importRowRows {
+		end := start + importRowRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		valueStrings := make([]string, 0, end-start)
+		valueArgs := make([]interface{}, 0, (end-start)*3)
+
+		for _, row := range rows[start:end] {
+			content, err := json.Marshal(row)
+			if err != nil {
+				return fmt.Errorf("error marshaling import row: %w", err)
+			}
+
+			valueStrings = append(valueStrings, "(?,?,?)")
+			valueArgs = append(valueArgs, importID, row.Line, string(content))
+		}
+
+		stmt := fmt.Sprintf(`INSERT INTO import_rows (import_id, line, content) VALUES %s`,
+			strings.Join(valueStrings, ","))
+
+		if _, err := tx.ExecContext(ctx, stmt, valueArgs...); err != nil {
+			return fmt.Errorf("error saving import rows: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error saving import rows: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *importRepository) GetImportRows(ctx context.Context, importID uint) ([]domain.ImportRow, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. GetImportRows()")
+
+	var importRows []ImportRow
+	err := repo.conn.SelectContext(ctx, &importRows,
+		"SELECT * FROM import_rows WHERE import_id=? ORDER BY id", importID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting import rows: %w", err)
+	}
+
+	rowModels := make([]domain.ImportRow, 0, len(importRows))
+	for _, importRow := range importRows {
+		var row domain.ImportRow
+		if err := json.Unmarshal([]byte(importRow.Content), &row); err != nil {
+			return nil, fmt.Errorf("error unmarshaling import row: %w", err)
+		}
+
+		rowModels = append(rowModels, row)
+	}
+
+	return rowModels, nil
+}
+
+func (repo *importRepository) DeleteImportRows(ctx context.Context, importID uint) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. DeleteImportRows()")
+
+	if _, err := repo.conn.ExecContext(ctx, "DELETE FROM import_rows WHERE import_id=?", importID); err != nil {
+		return fmt.Errorf("error deleting import rows: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *importRepository) SaveImportErrors(ctx context.Context, importID uint,
+	importErrors []domain.ImportError) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. SaveImportErrors()")
+
+	for start := 0; start < len(importErrors); start += importErrorRows {
+		end := start + importErrorRows
+		if end > len(importErrors) {
+			end = len(importErrors)
+		}
+
+		valueStrings := make([]string, 0, end-start)
+		valueArgs := make([]interface{}, 0, (end-start)*6)
+
+		for _, importError := range importErrors[start:end] {
+			args, err := encodeErrorArgs(importError.Args)
+			if err != nil {
+				return fmt.Errorf("error encoding import error args: %w", err)
+			}
+
+			valueStrings = append(valueStrings, "(?,?,?,?,?,?)")
+			valueArgs = append(valueArgs, importID, importError.Line, importError.Field, importError.Code,
+				importError.Message, args)
+		}
+
+		stmt := fmt.Sprintf(`INSERT INTO import_errors (import_id, line, field, code, message, args) VALUES %s`,
+			strings.Join(valueStrings, ","))
+
+		if _, err := repo.conn.ExecContext(ctx, stmt, valueArgs...); err != nil {
+			return fmt.Errorf("error saving import errors: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (repo *importRepository) GetImportErrors(ctx context.Context, importID uint) ([]domain.ImportError, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ImportRepository. GetImportErrors()")
+
+	var importErrors []ImportError
+	err := repo.conn.SelectContext(ctx, &importErrors,
+		"SELECT * FROM import_errors WHERE import_id=? ORDER BY id", importID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting import errors: %w", err)
+	}
+
+	errorModels := make([]domain.ImportError, 0, len(importErrors))
+	for _, importError := range importErrors {
+		args, err := decodeErrorArgs(importError.Args)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding import error args: %w", err)
+		}
+
+		errorModels = append(errorModels, domain.ImportError{
+			Line:    importError.Line,
+			Field:   importError.Field,
+			Code:    importError.Code,
+			Message: importError.Message,
+			Args:    args,
+		})
+	}
+
+	return errorModels, nil
+}
+
+// encodeErrorArgs stores the arguments of an error as a JSON array, or NULL when it has none.
+func encodeErrorArgs(args []interface{}) (sql.NullString, error) {
+	if len(args) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+// decodeErrorArgs reads the arguments stored by encodeErrorArgs. Whole numbers are read as int64,
+// so they still format with the %d verbs of the templates of the error codes.
+func decodeErrorArgs(stored sql.NullString) ([]interface{}, error) {
+	if !stored.Valid {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(stored.String))
+	decoder.UseNumber()
+
+	var args []interface{}
+	if err := decoder.Decode(&args); err != nil {
+		return nil, err
+	}
+
+	for i, arg := range args {
+		number, ok := arg.(json.Number)
+		if !ok {
+			continue
+		}
+
+		if value, err := number.Int64(); err == nil {
+			args[i] = value
+		} else if value, err := number.Float64(); err == nil {
+			args[i] = value
+		}
+	}
+
+	return args, nil
+}
@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestErrorArgsRoundTrip(t *testing.T) {
+	tests := [][]interface{}{
+		nil,
+		{"DIAMOND"},
+		{20},
+		{"A-1", 10485760, 0.5},
+	}
+
+	want := [][]interface{}{
+		nil,
+		{"DIAMOND"},
+		{int64(20)},
+		{"A-1", int64(10485760), 0.5},
+	}
+
+	for i, args := range tests {
+		stored, err := encodeErrorArgs(args)
+		if err != nil {
+			t.Fatalf("encodeErrorArgs(%v) error = %v", args, err)
+		}
+
+		got, err := decodeErrorArgs(stored)
+		if err != nil {
+			t.Fatalf("decodeErrorArgs(%q) error = %v", stored.String, err)
+		}
+
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("round trip of %v = %#v, want %#v", args, got, want[i])
+		}
+	}
+}
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -26,19 +27,35 @@ type Item struct {
 	Leader      bool
 	LeaderLevel string `db:"leader_level"`
 	Status      string
+	Version     uint
 	Photos      []Photo
 	CreatedAt   time.Time `db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at"`
 }
 
 type Photo struct {
-	ID        uint
-	Path      string
-	ItemID    uint      `db:"item_id"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID               uint
+	Path             string
+	ItemID           uint           `db:"item_id"`
+	Position         int            `db:"position"`
+	IsPrimary        bool           `db:"is_primary"`
+	BlobKey          string         `db:"blob_key"`
+	Variants         sql.NullString `db:"variants"`
+	CheckStatus      sql.NullString `db:"check_status"`
+	CheckStatusCode  sql.NullInt64  `db:"check_status_code"`
+	CheckError       sql.NullString `db:"check_error"`
+	CheckedAt        sql.NullTime   `db:"checked_at"`
+	CheckDeactivates bool           `db:"check_deactivates"`
+	CheckFailures    int            `db:"check_failures"`
+	Hash             *uint64        `db:"phash"`
+	CreatedAt        time.Time      `db:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at"`
 }
 
+// bulkInsertRows bounds the items of a multi-row insert, keeping the statements well below
+// the 65535 placeholders MySQL accepts and the default max_allowed_packet.
+const bulkInsertRows = 100
+
 type itemRepository struct {
 	conn *sqlx.DB
 }
@@ -55,21 +72,23 @@ func (repo *itemRepository) SaveItem(ctx context.Context, item *domain.Item) err
 	logger := marketcontext.Logger(ctx)
 	logger.Debug(repo, nil, "Entering ItemRepository. CreateItem()")
 
-	tx, err := repo.conn.Begin()
+	tx, err := repo.conn.Beginx()
 	if err != nil {
 		return fmt.Errorf("transaction initialization error: %w", err)
 	}
+	defer tx.Rollback() //nolint:errcheck
 
 	createdAt := time.Now()
 	result, err := tx.Exec(`INSERT INTO items 
-		(code, title, description, price, stock, item_type, leader, leader_level, status, created_at, updated_at) 
-		VALUES(?,?,?,?,?,?,?,?,?,?,?)`, item.Code, item.Title, item.Description, item.Price, item.Stock,
-		item.ItemType, item.Leader, item.LeaderLevel, item.Status, createdAt, createdAt)
+		(code, title, description, price, stock, item_type, leader, leader_level, status, version, created_at, updated_at) 
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?)`, item.Code, item.Title, item.Description, item.Price, item.Stock,
+		item.ItemType, item.Leader, item.LeaderLevel, item.Status, 1, createdAt, createdAt)
 
 	if err != nil {
 		var mysqlErr *mysql.MySQLError
 		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
 			return domain.ItemError{
+				Code:    domain.ErrCodeItemCodeDuplicated,
 				Message: "The item code must be unique",
 			}
 		}
@@ -87,23 +106,207 @@ func (repo *itemRepository) SaveItem(ctx context.Context, item *domain.Item) err
 		return fmt.Errorf("error saving photos: %w", err)
 	}
 
+	stored, err := repo.getItem(tx, uint(id))
+	if err != nil {
+		return err
+	}
+
+	if err := saveRevision(tx, domain.RevisionOperationCreate, stored, createdAt); err != nil {
+		return err
+	}
+
+	if err := repo.saveAudit(ctx, tx, nil, stored); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("error saving item: %w", err)
 	}
 
 	item.ID = uint(id)
+	item.Version = 1
+	item.Photos = repo.unmarshalItem(stored).Photos
 	item.CreatedAt = createdAt
 	item.UpdatedAt = createdAt
 
 	return nil
 }
 
+func (repo *itemRepository) SaveItems(ctx context.Context, items []*domain.Item) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRepository. SaveItems()")
+
+	tx, err := repo.conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("transaction initialization error: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	createdAt := time.Now()
+	for start := 0; start < len(items); start += bulkInsertRows {
+		end := start + bulkInsertRows
+		if end > len(items) {
+			end = len(items)
+		}
+
+		if err := repo.saveItemsChunk(ctx, tx, items[start:end], createdAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error saving items: %w", err)
+	}
+
+	for _, item := range items {
+		item.Version = 1
+		item.CreatedAt = createdAt
+		item.UpdatedAt = createdAt
+	}
+
+	return nil
+}
+
+func (repo *itemRepository) saveItemsChunk(ctx context.Context, tx *sqlx.Tx, items []*domain.Item,
+	createdAt time.Time) error {
+	valueStrings := make([]string, 0, len(items))
+	valueArgs := make([]interface{}, 0, len(items)*12)
+	codes := make([]string, 0, len(items))
+
+	for _, item := range items {
+		valueStrings = append(valueStrings, "(?,?,?,?,?,?,?,?,?,?,?,?)")
+		valueArgs = append(valueArgs, item.Code, item.Title, item.Description, item.Price, item.Stock,
+			item.ItemType, item.Leader, item.LeaderLevel, item.Status, 1, createdAt, createdAt)
+		codes = append(codes, item.Code)
+	}
+
+	stmt := fmt.Sprintf(`INSERT INTO items 
+		(code, title, description, price, stock, item_type, leader, leader_level, status, version, created_at, updated_at) 
+		VALUES %s`, strings.Join(valueStrings, ","))
+
+	if _, err := tx.Exec(stmt, valueArgs...); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return domain.ItemError{
+				Code:    domain.ErrCodeItemCodeDuplicated,
+				Message: "The item code must be unique",
+			}
+		}
+
+		return fmt.Errorf("error saving items: %w", err)
+	}
+
+	// The IDs of a multi-row insert are not guaranteed to be consecutive, so they are read back by code.
+	query, args, err := sqlx.In("SELECT id, code FROM items WHERE code IN (?)", codes)
+	if err != nil {
+		return fmt.Errorf("error getting item IDs: %w", err)
+	}
+
+	var stored []Item
+	if err := tx.Select(&stored, query, args...); err != nil {
+		return fmt.Errorf("error getting item IDs: %w", err)
+	}
+
+	ids := make(map[string]uint, len(stored))
+	for _, item := range stored {
+		ids[item.Code] = item.ID
+	}
+
+	itemIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		item.ID = ids[item.Code]
+		itemIDs = append(itemIDs, item.ID)
+	}
+
+	if err := repo.saveItemsPhotos(tx, items); err != nil {
+		return fmt.Errorf("error saving photos: %w", err)
+	}
+
+	revisions, err := repo.getItems(tx, itemIDs)
+	if err != nil {
+		return err
+	}
+
+	// The photos are read back as well so the callers get their IDs.
+	photos := make(map[uint][]domain.Photo, len(revisions))
+	for _, revision := range revisions {
+		photos[revision.ID] = repo.unmarshalItem(revision).Photos
+	}
+
+	for _, item := range items {
+		item.Photos = photos[item.ID]
+	}
+
+	if err := saveRevisions(tx, domain.RevisionOperationCreate, revisions, createdAt); err != nil {
+		return err
+	}
+
+	for _, revision := range revisions {
+		if err := repo.saveAudit(ctx, tx, nil, revision); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (repo *itemRepository) GetItemByID(ctx context.Context, id uint) (*domain.Item, error) {
 	logger := marketcontext.Logger(ctx)
 	logger.Debug(repo, nil, "Entering ItemRepository. GetItemByID()")
 
+	item, err := repo.getItem(repo.conn, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.unmarshalItem(item), nil
+}
+
+func (repo *itemRepository) GetItemsByIDs(ctx context.Context, ids []uint) ([]*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRepository. GetItemsByIDs()")
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	items, err := repo.getItems(repo.conn, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	itemModels := make([]*domain.Item, 0, len(items))
+	for _, item := range items {
+		itemModels = append(itemModels, repo.unmarshalItem(item))
+	}
+
+	return itemModels, nil
+}
+
+func (repo *itemRepository) GetItemByCode(ctx context.Context, code string) (*domain.Item, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRepository. GetItemByCode()")
+
+	var id uint
+	err := repo.conn.GetContext(ctx, &id, "SELECT id FROM items WHERE code=?", code)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, domain.ResourceNotFoundError{
+				Message: "Item not found",
+			}
+		default:
+			return nil, fmt.Errorf("error getting items: %w", err)
+		}
+	}
+
+	return repo.GetItemByID(ctx, id)
+}
+
+// getItem reads an item with its photos, either from the connection or inside a transaction.
+func (repo *itemRepository) getItem(queryer sqlx.Queryer, id uint) (*Item, error) {
 	item := new(Item)
-	err := repo.conn.Get(item, "SELECT * FROM items WHERE id=?", id)
+	err := sqlx.Get(queryer, item, "SELECT * FROM items WHERE id=?", id)
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
@@ -115,7 +318,259 @@ func (repo *itemRepository) GetItemByID(ctx context.Context, id uint) (*domain.I
 		}
 	}
 
-	return repo.unmarshalItem(item), nil
+	err = sqlx.Select(queryer, &item.Photos, "SELECT * FROM photos WHERE item_id=? ORDER BY position, id", id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting photos: %w", err)
+	}
+
+	return item, nil
+}
+
+// getItems reads a batch of items with their photos using one query for each table.
+func (repo *itemRepository) getItems(queryer sqlx.Queryer, ids []uint) ([]*Item, error) {
+	query, args, err := sqlx.In("SELECT * FROM items WHERE id IN (?) ORDER BY id", ids)
+	if err != nil {
+		return nil, fmt.Errorf("error getting items: %w", err)
+	}
+
+	var items []*Item
+	if err := sqlx.Select(queryer, &items, query, args...); err != nil {
+		return nil, fmt.Errorf("error getting items: %w", err)
+	}
+
+	query, args, err = sqlx.In("SELECT * FROM photos WHERE item_id IN (?) ORDER BY position, id", ids)
+	if err != nil {
+		return nil, fmt.Errorf("error getting photos: %w", err)
+	}
+
+	var photos []Photo
+	if err := sqlx.Select(queryer, &photos, query, args...); err != nil {
+		return nil, fmt.Errorf("error getting photos: %w", err)
+	}
+
+	byID := make(map[uint]*Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	for _, photo := range photos {
+		if item, ok := byID[photo.ItemID]; ok {
+			item.Photos = append(item.Photos, photo)
+		}
+	}
+
+	return items, nil
+}
+
+func (repo *itemRepository) UpdateItem(ctx context.Context, item *domain.Item) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRepository. UpdateItem()")
+
+	tx, err := repo.conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("transaction initialization error: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	before, err := repo.auditedItem(ctx, tx, item.ID, item.Version)
+	if err != nil {
+		return err
+	}
+
+	updatedAt := time.Now()
+	result, err := tx.Exec(`UPDATE items SET code=?, title=?, description=?, price=?, stock=?, item_type=?,
+		leader=?, leader_level=?, status=?, version=version+1, updated_at=?
+		WHERE id=? AND (version=? OR ?=0)`, item.Code, item.Title, item.Description, item.Price, item.Stock,
+		item.ItemType, item.Leader, item.LeaderLevel, item.Status, updatedAt, item.ID, item.Version, item.Version)
+
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return domain.ItemError{
+				Code:    domain.ErrCodeItemCodeDuplicated,
+				Message: "The item code must be unique",
+			}
+		}
+
+		return fmt.Errorf("error updating item: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating item: %w", err)
+	}
+
+	if rows == 0 {
+		return repo.checkVersion(tx, item.ID, item.Version)
+	}
+
+	if err := repo.replacePhotos(tx, item.ID, item.Photos); err != nil {
+		return fmt.Errorf("error saving photos: %w", err)
+	}
+
+	stored, err := repo.getItem(tx, item.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := saveRevision(tx, domain.RevisionOperationUpdate, stored, updatedAt); err != nil {
+		return err
+	}
+
+	if err := repo.saveAudit(ctx, tx, before, stored); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error updating item: %w", err)
+	}
+
+	item.UpdatedAt = updatedAt
+
+	return nil
+}
+
+func (repo *itemRepository) DeleteItem(ctx context.Context, id uint, version uint) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRepository. DeleteItem()")
+
+	tx, err := repo.conn.Beginx()
+	if err != nil {
+		return fmt.Errorf("transaction initialization error: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := repo.checkVersion(tx, id, version); err != nil {
+		return err
+	}
+
+	stored, err := repo.getItem(tx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.saveAudit(ctx, tx, stored, nil); err != nil {
+		return err
+	}
+
+	// The deletion is recorded as one more version so revisions stay numbered by version.
+	stored.Version++
+	if err := saveRevision(tx, domain.RevisionOperationDelete, stored, time.Now()); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM photos WHERE item_id=?", id); err != nil {
+		return fmt.Errorf("error deleting photos: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM items WHERE id=?", id); err != nil {
+		return fmt.Errorf("error deleting item: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error deleting item: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *itemRepository) CountItemsByStatus(ctx context.Context) (map[string]int, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRepository. CountItemsByStatus()")
+
+	var rows []struct {
+		Status sql.NullString `db:"status"`
+		Count  int            `db:"count"`
+	}
+
+	err := repo.conn.SelectContext(ctx, &rows, "SELECT status, COUNT(*) AS count FROM items GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("error counting items: %w", err)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status.String] += row.Count
+	}
+
+	return counts, nil
+}
+
+// checkVersion locks the item row and fails with a domain.ConflictError when its version
+// is not the expected one. An expected version of 0 only checks that the item exists.
+func (repo *itemRepository) checkVersion(tx *sqlx.Tx, id uint, version uint) error {
+	var current uint
+	err := tx.QueryRow("SELECT version FROM items WHERE id=? FOR UPDATE", id).Scan(&current)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return domain.ResourceNotFoundError{
+				Message: "Item not found",
+			}
+		default:
+			return fmt.Errorf("error getting item version: %w", err)
+		}
+	}
+
+	if version != 0 && current != version {
+		return domain.ConflictError{
+			Message: fmt.Sprintf("Item version mismatch: expected %d, current %d", version, current),
+		}
+	}
+
+	return nil
+}
+
+// auditedItem locks the item and returns its state before a write, when the write is audited,
+// so the entry records the state the write replaces. It returns nil otherwise.
+func (repo *itemRepository) auditedItem(ctx context.Context, tx *sqlx.Tx, id uint, version uint) (*Item, error) {
+	if _, ok := ports.AuditFromContext(ctx); !ok {
+		return nil, nil
+	}
+
+	if err := repo.checkVersion(tx, id, version); err != nil {
+		return nil, err
+	}
+
+	return repo.getItem(tx, id)
+}
+
+// saveAudit appends the audit entry of the write, when ctx is audited, inside its transaction.
+func (repo *itemRepository) saveAudit(ctx context.Context, tx *sqlx.Tx, before, after *Item) error {
+	entry, ok := ports.AuditFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	if entry.Before, err = repo.auditState(before); err != nil {
+		return err
+	}
+
+	if entry.After, err = repo.auditState(after); err != nil {
+		return err
+	}
+
+	if after != nil {
+		entry.EntityID = after.ID
+	} else {
+		entry.EntityID = before.ID
+	}
+
+	return appendAuditEntry(tx, &entry)
+}
+
+func (repo *itemRepository) auditState(item *Item) (json.RawMessage, error) {
+	if item == nil {
+		return nil, nil
+	}
+
+	state, err := json.Marshal(repo.unmarshalItem(item))
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling audit state: %w", err)
+	}
+
+	return state, nil
 }
 
 func (r *itemRepository) unmarshalItem(item *Item) *domain.Item {
@@ -130,34 +585,211 @@ func (r *itemRepository) unmarshalItem(item *Item) *domain.Item {
 		Leader:      item.Leader,
 		LeaderLevel: item.LeaderLevel,
 		Status:      item.Status,
+		Version:     item.Version,
 		CreatedAt:   item.CreatedAt,
 		UpdatedAt:   item.UpdatedAt,
 	}
 
 	for _, photo := range item.Photos {
-		itemModel.Photos = append(itemModel.Photos, domain.Photo{Path: photo.Path})
+		itemModel.Photos = append(itemModel.Photos, photo.unmarshal())
 	}
 
 	return &itemModel
 }
 
-func (repo *itemRepository) savePhotos(tx *sql.Tx, id uint, photos []domain.Photo) error {
+func (photo Photo) unmarshal() domain.Photo {
+	return domain.Photo{
+		ID:        photo.ID,
+		Path:      photo.Path,
+		ItemID:    photo.ItemID,
+		Position:  photo.Position,
+		IsPrimary: photo.IsPrimary,
+		BlobKey:   photo.BlobKey,
+		Variants:  decodeVariants(photo.Variants),
+		Check:     photo.check(),
+		Hash:      photo.Hash,
+		CreatedAt: photo.CreatedAt,
+		UpdatedAt: photo.UpdatedAt,
+	}
+}
+
+// check returns the last check of the photo, or nil when it was not checked.
+func (photo Photo) check() *domain.PhotoCheck {
+	if !photo.CheckStatus.Valid {
+		return nil
+	}
+
+	return &domain.PhotoCheck{
+		Status:          photo.CheckStatus.String,
+		StatusCode:      int(photo.CheckStatusCode.Int64),
+		Error:           photo.CheckError.String,
+		CheckedAt:       photo.CheckedAt.Time,
+		Failures:        photo.CheckFailures,
+		DeactivatesItem: photo.CheckDeactivates,
+	}
+}
+
+func (repo *itemRepository) savePhotos(tx *sqlx.Tx, id uint, photos []domain.Photo) error {
 	createdAt := time.Now()
 	valueStrings := make([]string, 0, len(photos))
-	valueArgs := make([]interface{}, 0, len(photos)*4)
+	valueArgs := make([]interface{}, 0, len(photos)*8)
 
 	for _, photo := range photos {
-		valueStrings = append(valueStrings, "(?, ?, ?, ?)")
+		valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?)")
 		valueArgs = append(valueArgs, photo.Path)
 		valueArgs = append(valueArgs, id)
+		valueArgs = append(valueArgs, photo.Position)
+		valueArgs = append(valueArgs, photo.IsPrimary)
+		valueArgs = append(valueArgs, photo.BlobKey)
+		valueArgs = append(valueArgs, encodeVariants(photo.Variants))
 		valueArgs = append(valueArgs, createdAt)
 		valueArgs = append(valueArgs, createdAt)
 	}
 
-	stmt := fmt.Sprintf(`INSERT INTO photos (path, item_id, created_at, updated_at) VALUES %s`,
-		strings.Join(valueStrings, ","))
+	stmt := fmt.Sprintf(`INSERT INTO photos (path, item_id, position, is_primary, blob_key, variants, created_at, updated_at)
+		VALUES %s`, strings.Join(valueStrings, ","))
+
+	_, err := tx.Exec(stmt, valueArgs...)
+
+	return err
+}
+
+// saveItemsPhotos inserts the photos of a batch of already saved items in a single statement.
+func (repo *itemRepository) saveItemsPhotos(tx *sqlx.Tx, items []*domain.Item) error {
+	createdAt := time.Now()
+	valueStrings := make([]string, 0, len(items))
+	valueArgs := make([]interface{}, 0, len(items)*8)
+
+	for _, item := range items {
+		for _, photo := range item.Photos {
+			valueStrings = append(valueStrings, "(?, ?, ?, ?, ?, ?, ?, ?)")
+			valueArgs = append(valueArgs, photo.Path, item.ID, photo.Position, photo.IsPrimary, photo.BlobKey,
+				encodeVariants(photo.Variants), createdAt, createdAt)
+		}
+	}
+
+	if len(valueStrings) == 0 {
+		return nil
+	}
+
+	stmt := fmt.Sprintf(`INSERT INTO photos (path, item_id, position, is_primary, blob_key, variants, created_at, updated_at)
+		VALUES %s`, strings.Join(valueStrings, ","))
 
 	_, err := tx.Exec(stmt, valueArgs...)
 
 	return err
 }
+
+// replacePhotos makes the stored photos of an item match photos while keeping their IDs:
+// a photo keeps the row of its ID, or else of a stored photo with the same path, the rows
+// left unclaimed are deleted and the remaining photos are inserted. A row that keeps its path
+// keeps its blob, variants, check and hash, which the photos of a full update do not carry,
+// while a row with a new path loses its check and hash.
+func (repo *itemRepository) replacePhotos(tx *sqlx.Tx, id uint, photos []domain.Photo) error {
+	var stored []Photo
+	if err := tx.Select(&stored, "SELECT * FROM photos WHERE item_id=? ORDER BY position, id", id); err != nil {
+		return err
+	}
+
+	unclaimed := make(map[uint]Photo, len(stored))
+	for _, photo := range stored {
+		unclaimed[photo.ID] = photo
+	}
+
+	claim := func(photo domain.Photo) (Photo, bool) {
+		if current, ok := unclaimed[photo.ID]; ok && photo.ID != 0 {
+			return current, true
+		}
+
+		for _, current := range stored {
+			if _, ok := unclaimed[current.ID]; ok && current.Path == photo.Path {
+				return current, true
+			}
+		}
+
+		return Photo{}, false
+	}
+
+	updatedAt := time.Now()
+	inserted := make([]domain.Photo, 0)
+
+	for _, photo := range photos {
+		current, ok := claim(photo)
+		if !ok {
+			inserted = append(inserted, photo)
+			continue
+		}
+
+		delete(unclaimed, current.ID)
+
+		var err error
+		switch {
+		case current.Path != photo.Path:
+			_, err = tx.Exec(`UPDATE photos SET path=?, position=?, is_primary=?, blob_key=?, variants=?,
+				check_status=NULL, check_status_code=NULL, check_error=NULL, checked_at=NULL,
+				check_deactivates=0, check_failures=0, phash=NULL, updated_at=? WHERE id=?`, photo.Path, photo.Position,
+				photo.IsPrimary, photo.BlobKey, encodeVariants(photo.Variants), updatedAt, current.ID)
+			if err == nil {
+				_, err = tx.Exec("DELETE FROM photo_hash_chunks WHERE photo_id=?", current.ID)
+			}
+		case current.Position != photo.Position || current.IsPrimary != photo.IsPrimary:
+			_, err = tx.Exec("UPDATE photos SET position=?, is_primary=?, updated_at=? WHERE id=?",
+				photo.Position, photo.IsPrimary, updatedAt, current.ID)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(unclaimed) > 0 {
+		ids := make([]uint, 0, len(unclaimed))
+		for photoID := range unclaimed {
+			ids = append(ids, photoID)
+		}
+
+		query, args, err := sqlx.In("DELETE FROM photos WHERE id IN (?)", ids)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+
+	if len(inserted) == 0 {
+		return nil
+	}
+
+	return repo.savePhotos(tx, id, inserted)
+}
+
+// encodeVariants stores the variants as a JSON object, or NULL when there is none.
+func encodeVariants(variants map[string]string) sql.NullString {
+	if len(variants) == 0 {
+		return sql.NullString{}
+	}
+
+	encoded, err := json.Marshal(variants)
+	if err != nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(encoded), Valid: true}
+}
+
+// decodeVariants reads the variants stored by encodeVariants, ignoring a value it can not read
+// since the variants can always be generated again.
+func decodeVariants(value sql.NullString) map[string]string {
+	if !value.Valid {
+		return nil
+	}
+
+	var variants map[string]string
+	if err := json.Unmarshal([]byte(value.String), &variants); err != nil {
+		return nil
+	}
+
+	return variants
+}
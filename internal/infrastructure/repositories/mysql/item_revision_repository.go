@@ -0,0 +1,292 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type ItemRevision struct {
+	ID        uint
+	ItemID    uint      `db:"item_id"`
+	Version   uint      `db:"version"`
+	Operation string    `db:"operation"`
+	Snapshot  string    `db:"snapshot"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ItemSnapshot is the JSON representation of an item stored in item_revisions.
+type ItemSnapshot struct {
+	ID          uint            `json:"id"`
+	Code        string          `json:"code"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Price       int             `json:"price"`
+	Stock       int             `json:"stock"`
+	ItemType    string          `json:"itemType"`
+	Leader      bool            `json:"leader"`
+	LeaderLevel string          `json:"leaderLevel"`
+	Status      string          `json:"status"`
+	Version     uint            `json:"version"`
+	Photos      []PhotoSnapshot `json:"photos"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+type PhotoSnapshot struct {
+	ID        uint                `json:"id"`
+	Path      string              `json:"path"`
+	Position  int                 `json:"position"`
+	IsPrimary bool                `json:"isPrimary"`
+	BlobKey   string              `json:"blobKey,omitempty"`
+	Variants  map[string]string   `json:"variants,omitempty"`
+	Hash      *uint64             `json:"hash,omitempty"`
+	Check     *PhotoCheckSnapshot `json:"check,omitempty"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+type PhotoCheckSnapshot struct {
+	Status          string    `json:"status"`
+	StatusCode      int       `json:"statusCode,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	CheckedAt       time.Time `json:"checkedAt"`
+	Failures        int       `json:"failures"`
+	DeactivatesItem bool      `json:"deactivatesItem"`
+}
+
+type itemRevisionRepository struct {
+	conn *sqlx.DB
+}
+
+func NewItemRevisionRepository(conn *sqlx.DB) (ports.ItemRevisionRepository, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("mysql connection cannot be nil")
+	}
+
+	return &itemRevisionRepository{conn: conn}, nil
+}
+
+func (repo *itemRevisionRepository) GetItemRevisions(ctx context.Context, itemID uint) ([]domain.ItemRevision, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRevisionRepository. GetItemRevisions()")
+
+	var revisions []ItemRevision
+	err := repo.conn.SelectContext(ctx, &revisions,
+		"SELECT * FROM item_revisions WHERE item_id=? ORDER BY version", itemID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting item revisions: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, domain.ResourceNotFoundError{
+			Message: "Item not found",
+		}
+	}
+
+	revisionModels := make([]domain.ItemRevision, 0, len(revisions))
+	for i := range revisions {
+		revision, err := unmarshalRevision(&revisions[i])
+		if err != nil {
+			return nil, err
+		}
+
+		revisionModels = append(revisionModels, *revision)
+	}
+
+	return revisionModels, nil
+}
+
+func (repo *itemRevisionRepository) GetItemRevision(ctx context.Context, itemID uint,
+	version uint) (*domain.ItemRevision, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRevisionRepository. GetItemRevision()")
+
+	revision := new(ItemRevision)
+	err := repo.conn.GetContext(ctx, revision,
+		"SELECT * FROM item_revisions WHERE item_id=? AND version=?", itemID, version)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, domain.ResourceNotFoundError{
+				Message: "Item revision not found",
+			}
+		default:
+			return nil, fmt.Errorf("error getting item revision: %w", err)
+		}
+	}
+
+	return unmarshalRevision(revision)
+}
+
+func (repo *itemRevisionRepository) GetItemRevisionAt(ctx context.Context, itemID uint,
+	at time.Time) (*domain.ItemRevision, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRevisionRepository. GetItemRevisionAt()")
+
+	revision := new(ItemRevision)
+	err := repo.conn.GetContext(ctx, revision, `SELECT * FROM item_revisions
+		WHERE item_id=? AND created_at<=? ORDER BY version DESC LIMIT 1`, itemID, at)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, domain.ResourceNotFoundError{
+				Message: "Item not found",
+			}
+		default:
+			return nil, fmt.Errorf("error getting item revision: %w", err)
+		}
+	}
+
+	return unmarshalRevision(revision)
+}
+
+// saveRevision records the state of an item as part of the transaction that modified it.
+func saveRevision(tx *sqlx.Tx, operation string, item *Item, createdAt time.Time) error {
+	return saveRevisions(tx, operation, []*Item{item}, createdAt)
+}
+
+// saveRevisions records the state of a batch of items with a multi-row insert.
+func saveRevisions(tx *sqlx.Tx, operation string, items []*Item, createdAt time.Time) error {
+	valueStrings := make([]string, 0, len(items))
+	valueArgs := make([]interface{}, 0, len(items)*5)
+
+	for _, item := range items {
+		data, err := json.Marshal(snapshotOf(item))
+		if err != nil {
+			return fmt.Errorf("error marshaling item snapshot: %w", err)
+		}
+
+		valueStrings = append(valueStrings, "(?,?,?,?,?)")
+		valueArgs = append(valueArgs, item.ID, item.Version, operation, string(data), createdAt)
+	}
+
+	stmt := fmt.Sprintf(`INSERT INTO item_revisions (item_id, version, operation, snapshot, created_at)
+		VALUES %s`, strings.Join(valueStrings, ","))
+
+	if _, err := tx.Exec(stmt, valueArgs...); err != nil {
+		return fmt.Errorf("error saving item revision: %w", err)
+	}
+
+	return nil
+}
+
+func snapshotOf(item *Item) ItemSnapshot {
+	snapshot := ItemSnapshot{
+		ID:          item.ID,
+		Code:        item.Code,
+		Title:       item.Title,
+		Description: item.Description,
+		Price:       item.Price,
+		Stock:       item.Stock,
+		ItemType:    item.ItemType,
+		Leader:      item.Leader,
+		LeaderLevel: item.LeaderLevel,
+		Status:      item.Status,
+		Version:     item.Version,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+
+	for _, photo := range item.Photos {
+		snapshot.Photos = append(snapshot.Photos, PhotoSnapshot{
+			ID:        photo.ID,
+			Path:      photo.Path,
+			Position:  photo.Position,
+			IsPrimary: photo.IsPrimary,
+			BlobKey:   photo.BlobKey,
+			Variants:  decodeVariants(photo.Variants),
+			Hash:      photo.Hash,
+			Check:     checkSnapshotOf(photo.check()),
+			CreatedAt: photo.CreatedAt,
+			UpdatedAt: photo.UpdatedAt,
+		})
+	}
+
+	return snapshot
+}
+
+func checkSnapshotOf(check *domain.PhotoCheck) *PhotoCheckSnapshot {
+	if check == nil {
+		return nil
+	}
+
+	return &PhotoCheckSnapshot{
+		Status:          check.Status,
+		StatusCode:      check.StatusCode,
+		Error:           check.Error,
+		CheckedAt:       check.CheckedAt,
+		Failures:        check.Failures,
+		DeactivatesItem: check.DeactivatesItem,
+	}
+}
+
+func (check *PhotoCheckSnapshot) unmarshal() *domain.PhotoCheck {
+	if check == nil {
+		return nil
+	}
+
+	return &domain.PhotoCheck{
+		Status:          check.Status,
+		StatusCode:      check.StatusCode,
+		Error:           check.Error,
+		CheckedAt:       check.CheckedAt,
+		Failures:        check.Failures,
+		DeactivatesItem: check.DeactivatesItem,
+	}
+}
+
+func unmarshalRevision(revision *ItemRevision) (*domain.ItemRevision, error) {
+	snapshot := new(ItemSnapshot)
+	if err := json.Unmarshal([]byte(revision.Snapshot), snapshot); err != nil {
+		return nil, fmt.Errorf("error unmarshaling item snapshot: %w", err)
+	}
+
+	revisionModel := domain.ItemRevision{
+		ItemID:    revision.ItemID,
+		Version:   revision.Version,
+		Operation: revision.Operation,
+		CreatedAt: revision.CreatedAt,
+		Item: domain.Item{
+			ID:          snapshot.ID,
+			Code:        snapshot.Code,
+			Title:       snapshot.Title,
+			Description: snapshot.Description,
+			Price:       snapshot.Price,
+			Stock:       snapshot.Stock,
+			ItemType:    snapshot.ItemType,
+			Leader:      snapshot.Leader,
+			LeaderLevel: snapshot.LeaderLevel,
+			Status:      snapshot.Status,
+			Version:     snapshot.Version,
+			CreatedAt:   snapshot.CreatedAt,
+			UpdatedAt:   snapshot.UpdatedAt,
+		},
+	}
+
+	for _, photo := range snapshot.Photos {
+		revisionModel.Item.Photos = append(revisionModel.Item.Photos, domain.Photo{
+			ID:        photo.ID,
+			Path:      photo.Path,
+			ItemID:    snapshot.ID,
+			Position:  photo.Position,
+			IsPrimary: photo.IsPrimary,
+			BlobKey:   photo.BlobKey,
+			Variants:  photo.Variants,
+			Hash:      photo.Hash,
+			Check:     photo.Check.unmarshal(),
+			CreatedAt: photo.CreatedAt,
+			UpdatedAt: photo.UpdatedAt,
+		})
+	}
+
+	return &revisionModel, nil
+}
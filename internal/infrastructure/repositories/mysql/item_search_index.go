@@ -0,0 +1,198 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/search"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// codeMatchScore ranks an item whose code is the whole query above any FULLTEXT match.
+const codeMatchScore = 100
+
+type searchRow struct {
+	ID          uint
+	Code        string  `db:"code"`
+	Title       string  `db:"title"`
+	Description string  `db:"description"`
+	Score       float64 `db:"score"`
+}
+
+// itemSearchIndex searches the items table through its FULLTEXT index, which MySQL keeps in
+// sync by itself, so indexing and removing are no-ops. MySQL has no stemming, so every term is
+// searched as a prefix of the stem produced by the analyzer, and the accent folding comes from
+// the collation of the table.
+type itemSearchIndex struct {
+	conn *sqlx.DB
+}
+
+func NewItemSearchIndex(conn *sqlx.DB) (ports.ItemSearchIndex, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("mysql connection cannot be nil")
+	}
+
+	return &itemSearchIndex{conn: conn}, nil
+}
+
+func (index *itemSearchIndex) IndexItem(ctx context.Context, item *domain.Item) error {
+	return nil
+}
+
+func (index *itemSearchIndex) RemoveItem(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (index *itemSearchIndex) Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(index, nil, "Entering ItemSearchIndex. Search()")
+
+	terms := search.Terms(query.Text)
+	code := strings.TrimSpace(query.Text)
+
+	textCondition, score := "1=1", "0"
+	var textArgs, scoreArgs []interface{}
+
+	switch {
+	case code == "":
+	case len(terms) == 0:
+		// A text made only of stop words matches nothing, as in the memory index.
+		textCondition = "1=0"
+	default:
+		prefixes := make([]string, 0, len(terms))
+		for _, term := range terms {
+			prefixes = append(prefixes, term+"*")
+		}
+		against := strings.Join(prefixes, " ")
+
+		textCondition = "(MATCH(i.title, i.description) AGAINST(? IN BOOLEAN MODE) OR i.code=?)"
+		textArgs = []interface{}{against, code}
+		score = "MATCH(i.title, i.description) AGAINST(? IN BOOLEAN MODE) + IF(i.code=?, ?, 0)"
+		scoreArgs = []interface{}{against, code, codeMatchScore}
+	}
+
+	result, err := index.countFacets(ctx, query, textCondition, textArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Hits = []domain.SearchHit{}
+	if result.Total == 0 || query.Offset >= result.Total {
+		return result, nil
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = result.Total
+	}
+
+	conditions, filterArgs := itemFilterConditions(query.Filter)
+
+	args := append(append(append([]interface{}{}, scoreArgs...), textArgs...), filterArgs...)
+	args = append(args, limit, query.Offset)
+
+	var rows []searchRow
+	err = index.conn.SelectContext(ctx, &rows, fmt.Sprintf(`SELECT i.id, COALESCE(i.code, '') AS code,
+		COALESCE(i.title, '') AS title, COALESCE(i.description, '') AS description, %s AS score
+		FROM items i WHERE %s AND %s ORDER BY score DESC, i.id LIMIT ? OFFSET ?`,
+		score, textCondition, strings.Join(conditions, " AND ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching items: %w", err)
+	}
+
+	for _, row := range rows {
+		result.Hits = append(result.Hits, domain.SearchHit{
+			ItemID: row.ID,
+			Score:  row.Score,
+			Highlights: search.Highlights(map[string]string{
+				"code":        row.Code,
+				"title":       row.Title,
+				"description": row.Description,
+			}, terms),
+		})
+	}
+
+	return result, nil
+}
+
+// countFacets counts the items matching the search and the values of every facet in a single
+// query, with one conditional sum per count over the items matching the text.
+func (index *itemSearchIndex) countFacets(ctx context.Context, query domain.SearchQuery, textCondition string,
+	textArgs []interface{}) (*domain.SearchResult, error) {
+	conditions, args := itemFilterConditions(query.Filter)
+	sums := []string{fmt.Sprintf("SUM(%s)", strings.Join(conditions, " AND "))}
+
+	type facetValue struct {
+		facet string
+		value string
+	}
+	var counted []facetValue
+
+	for _, facet := range domain.Facets {
+		facetConditions, facetArgs := itemFilterConditions(query.Filter.Without(facet))
+
+		for _, value := range domain.FacetValues(facet, query.PriceRanges) {
+			valueCondition, valueArgs := facetCondition(facet, value, query.PriceRanges)
+
+			sums = append(sums, fmt.Sprintf("SUM(%s AND %s)", strings.Join(facetConditions, " AND "), valueCondition))
+			args = append(append(args, facetArgs...), valueArgs...)
+			counted = append(counted, facetValue{facet: facet, value: value})
+		}
+	}
+
+	args = append(args, textArgs...)
+
+	sumValues := make([]sql.NullInt64, len(sums))
+	destinations := make([]interface{}, len(sums))
+	for i := range sumValues {
+		destinations[i] = &sumValues[i]
+	}
+
+	err := index.conn.QueryRowxContext(ctx, fmt.Sprintf("SELECT %s FROM items i WHERE %s",
+		strings.Join(sums, ", "), textCondition), args...).Scan(destinations...)
+	if err != nil {
+		return nil, fmt.Errorf("error counting search facets: %w", err)
+	}
+
+	counts := make(map[string]map[string]int, len(domain.Facets))
+	for _, facet := range domain.Facets {
+		counts[facet] = map[string]int{}
+	}
+
+	for i, facetValue := range counted {
+		counts[facetValue.facet][facetValue.value] = int(sumValues[i+1].Int64)
+	}
+
+	return &domain.SearchResult{
+		Total:  int(sumValues[0].Int64),
+		Facets: domain.NewFacetCounts(counts, query.Filter, query.PriceRanges),
+	}, nil
+}
+
+// facetCondition selects the items with a value of a facet.
+func facetCondition(facet, value string, priceRanges []domain.PriceRange) (string, []interface{}) {
+	switch facet {
+	case domain.FacetStatus:
+		return "i.status=?", []interface{}{value}
+	case domain.FacetItemType:
+		return "i.item_type=?", []interface{}{value}
+	case domain.FacetLeader:
+		return "i.leader=?", []interface{}{value == "true"}
+	case domain.FacetLeaderLevel:
+		return "i.leader_level=?", []interface{}{value}
+	case domain.FacetPrice:
+		for _, priceRange := range priceRanges {
+			if priceRange.Key() == value {
+				conditions, args := itemFilterConditions(domain.ItemFilter{PriceMin: priceRange.Min, PriceMax: priceRange.Max})
+				return "(" + strings.Join(conditions, " AND ") + ")", args
+			}
+		}
+	}
+
+	return "0=1", nil
+}
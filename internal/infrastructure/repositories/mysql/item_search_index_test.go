@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+func TestFacetCondition(t *testing.T) {
+	priceRanges := domain.PriceRanges([]int{1000, 5000})
+
+	tests := []struct {
+		facet    string
+		value    string
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			facet:    domain.FacetStatus,
+			value:    domain.StatusActive,
+			want:     "i.status=?",
+			wantArgs: []interface{}{domain.StatusActive},
+		},
+		{
+			facet:    domain.FacetItemType,
+			value:    domain.ItemTypeOwn,
+			want:     "i.item_type=?",
+			wantArgs: []interface{}{domain.ItemTypeOwn},
+		},
+		{
+			facet:    domain.FacetLeader,
+			value:    "false",
+			want:     "i.leader=?",
+			wantArgs: []interface{}{false},
+		},
+		{
+			facet:    domain.FacetLeaderLevel,
+			value:    domain.LeaderLevelGold,
+			want:     "i.leader_level=?",
+			wantArgs: []interface{}{domain.LeaderLevelGold},
+		},
+		{
+			facet:    domain.FacetPrice,
+			value:    "*-1000",
+			want:     "(1=1 AND i.price<?)",
+			wantArgs: []interface{}{1000},
+		},
+		{
+			facet:    domain.FacetPrice,
+			value:    "1000-5000",
+			want:     "(1=1 AND i.price>=? AND i.price<?)",
+			wantArgs: []interface{}{1000, 5000},
+		},
+		{
+			facet:    domain.FacetPrice,
+			value:    "5000-*",
+			want:     "(1=1 AND i.price>=?)",
+			wantArgs: []interface{}{5000},
+		},
+		{
+			facet: domain.FacetPrice,
+			value: "5-10",
+			want:  "0=1",
+		},
+	}
+
+	for _, test := range tests {
+		got, args := facetCondition(test.facet, test.value, priceRanges)
+		if got != test.want || !reflect.DeepEqual(args, test.wantArgs) {
+			t.Errorf("facetCondition(%s, %s) = %q %v, want %q %v", test.facet, test.value, got, args,
+				test.want, test.wantArgs)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// streamBatchItems is how many items of a stream get their photos with a single query.
+const streamBatchItems = 500
+
+// StreamItems reads the items through a single cursor, so the driver fetches the rows as they
+// are consumed, and reads the photos of every batch of items with a single query. The items and
+// their photos are scanned with SELECT *, as GetItemByID does, so they have every column.
+func (repo *itemRepository) StreamItems(ctx context.Context, filter domain.ItemFilter,
+	fn func(*domain.Item) error) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering ItemRepository. StreamItems()")
+
+	conditions, args := itemFilterConditions(filter)
+	query := fmt.Sprintf(`SELECT i.* FROM items i WHERE %s ORDER BY i.id`, strings.Join(conditions, " AND "))
+
+	rows, err := repo.conn.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error getting items: %w", err)
+	}
+	defer rows.Close()
+
+	batch := make([]*Item, 0, streamBatchItems)
+	for rows.Next() {
+		var item Item
+		if err := rows.StructScan(&item); err != nil {
+			return fmt.Errorf("error getting items: %w", err)
+		}
+
+		batch = append(batch, &item)
+		if len(batch) < streamBatchItems {
+			continue
+		}
+
+		if err := repo.streamBatch(ctx, batch, fn); err != nil {
+			return err
+		}
+
+		batch = batch[:0]
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error getting items: %w", err)
+	}
+
+	return repo.streamBatch(ctx, batch, fn)
+}
+
+// streamBatch reads the photos of a batch of items and calls fn with every item, in order.
+func (repo *itemRepository) streamBatch(ctx context.Context, batch []*Item,
+	fn func(*domain.Item) error) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(batch))
+	byID := make(map[uint]*Item, len(batch))
+
+	for i, item := range batch {
+		ids[i] = item.ID
+		byID[item.ID] = item
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM photos WHERE item_id IN (?) ORDER BY position, id", ids)
+	if err != nil {
+		return fmt.Errorf("error getting photos: %w", err)
+	}
+
+	var photos []Photo
+	if err := repo.conn.SelectContext(ctx, &photos, query, args...); err != nil {
+		return fmt.Errorf("error getting photos: %w", err)
+	}
+
+	for _, photo := range photos {
+		byID[photo.ItemID].Photos = append(byID[photo.ItemID].Photos, photo)
+	}
+
+	for _, item := range batch {
+		if err := fn(repo.unmarshalItem(item)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func itemFilterConditions(filter domain.ItemFilter) ([]string, []interface{}) {
+	conditions := []string{"1=1"}
+	var args []interface{}
+
+	if filter.ItemType != "" {
+		conditions = append(conditions, "i.item_type=?")
+		args = append(args, filter.ItemType)
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "i.status=?")
+		args = append(args, filter.Status)
+	}
+
+	if filter.Leader != nil {
+		conditions = append(conditions, "i.leader=?")
+		args = append(args, *filter.Leader)
+	}
+
+	if filter.LeaderLevel != "" {
+		conditions = append(conditions, "i.leader_level=?")
+		args = append(args, filter.LeaderLevel)
+	}
+
+	if filter.PriceMin != nil {
+		conditions = append(conditions, "i.price>=?")
+		args = append(args, *filter.PriceMin)
+	}
+
+	if filter.PriceMax != nil {
+		conditions = append(conditions, "i.price<?")
+		args = append(args, *filter.PriceMax)
+	}
+
+	if !filter.UpdatedFrom.IsZero() {
+		conditions = append(conditions, "i.updated_at>=?")
+		args = append(args, filter.UpdatedFrom)
+	}
+
+	if !filter.UpdatedTo.IsZero() {
+		conditions = append(conditions, "i.updated_at<=?")
+		args = append(args, filter.UpdatedTo)
+	}
+
+	return conditions, args
+}
@@ -0,0 +1,294 @@
+package mysql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type Job struct {
+	ID              uint
+	Type            string         `db:"type"`
+	Payload         []byte         `db:"payload"`
+	Status          string         `db:"status"`
+	Progress        int            `db:"progress"`
+	ProgressMessage string         `db:"progress_message"`
+	Attempts        int            `db:"attempts"`
+	MaxAttempts     int            `db:"max_attempts"`
+	Error           sql.NullString `db:"error"`
+	Actor           string         `db:"actor"`
+	CancelRequested bool           `db:"cancel_requested"`
+	ClaimToken      string         `db:"claim_token"`
+	RunAt           time.Time      `db:"run_at"`
+	HeartbeatAt     sql.NullTime   `db:"heartbeat_at"`
+	CreatedAt       time.Time      `db:"created_at"`
+	UpdatedAt       time.Time      `db:"updated_at"`
+	StartedAt       sql.NullTime   `db:"started_at"`
+	CompletedAt     sql.NullTime   `db:"completed_at"`
+}
+
+type jobRepository struct {
+	conn *sqlx.DB
+}
+
+func NewJobRepository(conn *sqlx.DB) (ports.JobRepository, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("mysql connection cannot be nil")
+	}
+
+	return &jobRepository{conn: conn}, nil
+}
+
+func (repo *jobRepository) SaveJob(ctx context.Context, job *domain.Job) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering JobRepository. SaveJob()")
+
+	createdAt := time.Now()
+	if job.RunAt.IsZero() {
+		job.RunAt = createdAt
+	}
+
+	result, err := repo.conn.ExecContext(ctx, `INSERT INTO jobs
+		(type, payload, status, max_attempts, actor, run_at, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?)`, job.Type, []byte(job.Payload), job.Status, job.MaxAttempts, job.Actor,
+		job.RunAt, createdAt, createdAt)
+	if err != nil {
+		return fmt.Errorf("error saving job: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error saving job: %w", err)
+	}
+
+	job.ID = uint(id)
+	job.CreatedAt = createdAt
+	job.UpdatedAt = createdAt
+
+	return nil
+}
+
+func (repo *jobRepository) GetJob(ctx context.Context, id uint) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering JobRepository. GetJob()")
+
+	return getJob(ctx, repo.conn, "SELECT * FROM jobs WHERE id=?", id)
+}
+
+func (repo *jobRepository) CountJobs(ctx context.Context, jobType string, statuses []string) (int, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering JobRepository. CountJobs()")
+
+	query, args, err := sqlx.In("SELECT COUNT(*) FROM jobs WHERE type=? AND status IN (?)", jobType, statuses)
+	if err != nil {
+		return 0, fmt.Errorf("error counting jobs: %w", err)
+	}
+
+	var count int
+	if err := repo.conn.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("error counting jobs: %w", err)
+	}
+
+	return count, nil
+}
+
+// ClaimJob claims with a single conditional update instead of SELECT ... FOR UPDATE SKIP LOCKED,
+// which MySQL 5.7 does not support, and reads the claimed job back by its new claim token.
+func (repo *jobRepository) ClaimJob(ctx context.Context, now, staleBefore time.Time) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering JobRepository. ClaimJob()")
+
+	token, err := newClaimToken()
+	if err != nil {
+		return nil, fmt.Errorf("error claiming job: %w", err)
+	}
+
+	result, err := repo.conn.ExecContext(ctx, `UPDATE jobs SET status=?, claim_token=?, attempts=attempts+1,
+		started_at=COALESCE(started_at, ?), heartbeat_at=?, updated_at=?
+		WHERE (status=? AND run_at<=?) OR (status=? AND heartbeat_at<?)
+		ORDER BY run_at, id LIMIT 1`,
+		domain.JobStatusRunning, token, now, now, now,
+		domain.JobStatusQueued, now, domain.JobStatusRunning, staleBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error claiming job: %w", err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error claiming job: %w", err)
+	}
+
+	if claimed == 0 {
+		return nil, nil
+	}
+
+	return getJob(ctx, repo.conn, "SELECT * FROM jobs WHERE claim_token=?", token)
+}
+
+func (repo *jobRepository) UpdateJob(ctx context.Context, job *domain.Job) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering JobRepository. UpdateJob()")
+
+	updatedAt := time.Now()
+	result, err := repo.conn.ExecContext(ctx, `UPDATE jobs SET status=?, progress=?, progress_message=?,
+		attempts=?, error=?, run_at=?, updated_at=?, completed_at=? WHERE id=? AND claim_token=?`,
+		job.Status, job.Progress, job.ProgressMessage, job.Attempts,
+		sql.NullString{String: job.Error, Valid: job.Error != ""}, job.RunAt, updatedAt, job.CompletedAt,
+		job.ID, job.ClaimToken)
+	if err != nil {
+		return fmt.Errorf("error updating job: %w", err)
+	}
+
+	if err := checkClaim(result); err != nil {
+		return err
+	}
+
+	job.UpdatedAt = updatedAt
+
+	return nil
+}
+
+func (repo *jobRepository) HeartbeatJob(ctx context.Context, job *domain.Job, at time.Time) (bool, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering JobRepository. HeartbeatJob()")
+
+	result, err := repo.conn.ExecContext(ctx, `UPDATE jobs SET progress=?, progress_message=?, heartbeat_at=?
+		WHERE id=? AND claim_token=?`, job.Progress, job.ProgressMessage, at, job.ID, job.ClaimToken)
+	if err != nil {
+		return false, fmt.Errorf("error updating job heartbeat: %w", err)
+	}
+
+	if err := checkClaim(result); err != nil {
+		return false, err
+	}
+
+	var cancelRequested bool
+	err = repo.conn.GetContext(ctx, &cancelRequested, "SELECT cancel_requested FROM jobs WHERE id=?", job.ID)
+	if err != nil {
+		return false, fmt.Errorf("error updating job heartbeat: %w", err)
+	}
+
+	return cancelRequested, nil
+}
+
+func (repo *jobRepository) CancelJob(ctx context.Context, id uint) (*domain.Job, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering JobRepository. CancelJob()")
+
+	tx, err := repo.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling job: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	job, err := getJob(ctx, tx, "SELECT * FROM jobs WHERE id=? FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Finished() {
+		return job, nil
+	}
+
+	now := time.Now()
+	switch job.Status {
+	case domain.JobStatusQueued:
+		job.Status = domain.JobStatusCancelled
+		job.CompletedAt = &now
+	default:
+		job.CancelRequested = true
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE jobs SET status=?, cancel_requested=?, updated_at=?, completed_at=?
+		WHERE id=?`, job.Status, job.CancelRequested, now, job.CompletedAt, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error cancelling job: %w", err)
+	}
+
+	job.UpdatedAt = now
+
+	return job, nil
+}
+
+func getJob(ctx context.Context, queryer sqlx.QueryerContext, query string, args ...interface{}) (*domain.Job, error) {
+	job := new(Job)
+	err := sqlx.GetContext(ctx, queryer, job, query, args...)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, domain.ResourceNotFoundError{
+				Message: "Job not found",
+			}
+		default:
+			return nil, fmt.Errorf("error getting job: %w", err)
+		}
+	}
+
+	jobModel := &domain.Job{
+		ID:              job.ID,
+		Type:            job.Type,
+		Payload:         job.Payload,
+		Status:          job.Status,
+		Progress:        job.Progress,
+		ProgressMessage: job.ProgressMessage,
+		Attempts:        job.Attempts,
+		MaxAttempts:     job.MaxAttempts,
+		Error:           job.Error.String,
+		Actor:           job.Actor,
+		CancelRequested: job.CancelRequested,
+		ClaimToken:      job.ClaimToken,
+		RunAt:           job.RunAt,
+		CreatedAt:       job.CreatedAt,
+		UpdatedAt:       job.UpdatedAt,
+	}
+
+	if job.HeartbeatAt.Valid {
+		jobModel.HeartbeatAt = &job.HeartbeatAt.Time
+	}
+
+	if job.StartedAt.Valid {
+		jobModel.StartedAt = &job.StartedAt.Time
+	}
+
+	if job.CompletedAt.Valid {
+		jobModel.CompletedAt = &job.CompletedAt.Time
+	}
+
+	return jobModel, nil
+}
+
+// checkClaim fails when an update by claim token matched no job, because another runner
+// claimed it again after its heartbeat went stale.
+func checkClaim(result sql.Result) error {
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating job: %w", err)
+	}
+
+	if updated == 0 {
+		return domain.ErrJobClaimLost
+	}
+
+	return nil
+}
+
+func newClaimToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
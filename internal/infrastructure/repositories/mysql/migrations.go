@@ -0,0 +1,487 @@
+package mysql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+// migration is a versioned change of the schema. Its steps are idempotent, so the databases
+// migrated before the versions were recorded are migrated again without errors.
+type migration struct {
+	version int
+	name    string
+	steps   []migrationStep
+}
+
+type migrationStep func(db *sqlx.DB) error
+
+// Migration is a migration applied by Migrate.
+type Migration struct {
+	Version int
+	Name    string
+}
+
+var schemaMigrationsSchema = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version int NOT NULL,
+		name varchar(255) NOT NULL,
+		applied_at datetime(3) NOT NULL,
+		PRIMARY KEY (version)
+	  );`
+
+// migrations are the changes of the schema in the order they are applied. A change of the schema
+// is a new migration appended to the list, as the applied ones are never run again.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "items and photos",
+		steps: []migrationStep{
+			execStatement(`CREATE TABLE IF NOT EXISTS items (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		code varchar(191) DEFAULT NULL,
+		title longtext,
+		description longtext,
+		price bigint(20) DEFAULT NULL,
+		stock bigint(20) DEFAULT NULL,
+		item_type longtext,
+		leader tinyint(1) DEFAULT NULL,
+		leader_level longtext,
+		status longtext,
+		created_at datetime(3) DEFAULT NULL,
+		updated_at datetime(3) DEFAULT NULL,
+		PRIMARY KEY (id),
+		UNIQUE KEY code (code)
+	  );`),
+			execStatement(`CREATE TABLE IF NOT EXISTS photos (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		path longtext,
+		item_id bigint(20) unsigned DEFAULT NULL,
+		created_at datetime(3) DEFAULT NULL,
+		updated_at datetime(3) DEFAULT NULL,
+		PRIMARY KEY (id),
+		KEY fk_items_photos (item_id),
+		CONSTRAINT fk_items_photos FOREIGN KEY (item_id) REFERENCES items (id)
+	  );`),
+		},
+	},
+	{
+		version: 2,
+		name:    "idempotency keys",
+		steps: []migrationStep{
+			execStatement(`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		idempotency_key varchar(255) NOT NULL,
+		fingerprint char(64) NOT NULL,
+		completed tinyint(1) NOT NULL DEFAULT 0,
+		status_code int DEFAULT NULL,
+		header text,
+		body longblob,
+		created_at datetime(3) NOT NULL,
+		expires_at datetime(3) NOT NULL,
+		PRIMARY KEY (idempotency_key),
+		KEY idx_idempotency_keys_expires_at (expires_at)
+	  );`),
+			addColumn("idempotency_keys", "lease_expires_at", "datetime(3) DEFAULT NULL"),
+			addColumn("idempotency_keys", "owner", "char(32) DEFAULT NULL"),
+		},
+	},
+	{
+		version: 3,
+		name:    "item versions",
+		steps: []migrationStep{
+			addColumn("items", "version", "bigint(20) unsigned NOT NULL DEFAULT 1"),
+		},
+	},
+	{
+		version: 4,
+		name:    "item revisions",
+		steps: []migrationStep{
+			execStatement(`CREATE TABLE IF NOT EXISTS item_revisions (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		item_id bigint(20) unsigned NOT NULL,
+		version bigint(20) unsigned NOT NULL,
+		operation varchar(16) NOT NULL,
+		snapshot longtext NOT NULL,
+		created_at datetime(3) NOT NULL,
+		PRIMARY KEY (id),
+		UNIQUE KEY item_version (item_id, version),
+		KEY idx_item_revisions_created_at (item_id, created_at)
+	  );`),
+		},
+	},
+	{
+		version: 5,
+		name:    "audit log",
+		steps: []migrationStep{
+			execStatement(`CREATE TABLE IF NOT EXISTS audit_log (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		actor varchar(191) NOT NULL,
+		request_id varchar(191) NOT NULL,
+		client_ip varchar(64) NOT NULL,
+		operation varchar(32) NOT NULL,
+		entity_type varchar(32) NOT NULL,
+		entity_id bigint(20) unsigned NOT NULL,
+		before_state longtext,
+		after_state longtext,
+		created_at datetime(3) NOT NULL,
+		prev_hash char(64) NOT NULL,
+		hash char(64) NOT NULL,
+		PRIMARY KEY (id),
+		KEY idx_audit_log_entity (entity_type, entity_id),
+		KEY idx_audit_log_actor (actor),
+		KEY idx_audit_log_created_at (created_at)
+	  );`),
+			execStatement(`CREATE TABLE IF NOT EXISTS audit_log_head (
+		id tinyint unsigned NOT NULL,
+		hash char(64) NOT NULL,
+		PRIMARY KEY (id)
+	  );`),
+			execStatement("INSERT IGNORE INTO audit_log_head (id, hash) VALUES (1, '')"),
+		},
+	},
+	{
+		version: 6,
+		name:    "imports",
+		steps: []migrationStep{
+			execStatement(`CREATE TABLE IF NOT EXISTS imports (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		format varchar(16) NOT NULL,
+		dry_run tinyint(1) NOT NULL DEFAULT 0,
+		status varchar(16) NOT NULL,
+		total_rows int NOT NULL DEFAULT 0,
+		processed_rows int NOT NULL DEFAULT 0,
+		created_rows int NOT NULL DEFAULT 0,
+		updated_rows int NOT NULL DEFAULT 0,
+		failed_rows int NOT NULL DEFAULT 0,
+		job_id bigint(20) unsigned DEFAULT NULL,
+		error text,
+		created_at datetime(3) NOT NULL,
+		updated_at datetime(3) NOT NULL,
+		completed_at datetime(3) DEFAULT NULL,
+		PRIMARY KEY (id)
+	  );`),
+			execStatement(`CREATE TABLE IF NOT EXISTS import_errors (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		import_id bigint(20) unsigned NOT NULL,
+		line int NOT NULL,
+		field varchar(255) NOT NULL DEFAULT '',
+		code varchar(64) NOT NULL,
+		message text NOT NULL,
+		PRIMARY KEY (id),
+		KEY idx_import_errors_import_id (import_id, id)
+	  );`),
+			// import_rows keeps the parsed rows of an import until its job has run.
+			execStatement(`CREATE TABLE IF NOT EXISTS import_rows (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		import_id bigint(20) unsigned NOT NULL,
+		line int NOT NULL,
+		content longtext NOT NULL,
+		PRIMARY KEY (id),
+		KEY idx_import_rows_import_id (import_id, id)
+	  );`),
+			addColumn("imports", "job_id", "bigint(20) unsigned DEFAULT NULL"),
+		},
+	},
+	{
+		version: 7,
+		name:    "jobs",
+		steps: []migrationStep{
+			execStatement(`CREATE TABLE IF NOT EXISTS jobs (
+		id bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+		type varchar(64) NOT NULL,
+		payload longblob,
+		status varchar(16) NOT NULL,
+		progress int NOT NULL DEFAULT 0,
+		progress_message varchar(255) NOT NULL DEFAULT '',
+		attempts int NOT NULL DEFAULT 0,
+		max_attempts int NOT NULL DEFAULT 1,
+		error text,
+		actor varchar(191) NOT NULL DEFAULT '',
+		cancel_requested tinyint(1) NOT NULL DEFAULT 0,
+		claim_token varchar(32) NOT NULL DEFAULT '',
+		run_at datetime(3) NOT NULL,
+		heartbeat_at datetime(3) DEFAULT NULL,
+		created_at datetime(3) NOT NULL,
+		updated_at datetime(3) NOT NULL,
+		started_at datetime(3) DEFAULT NULL,
+		completed_at datetime(3) DEFAULT NULL,
+		PRIMARY KEY (id),
+		KEY idx_jobs_status_run_at (status, run_at),
+		KEY idx_jobs_claim_token (claim_token)
+	  );`),
+		},
+	},
+	{
+		version: 8,
+		name:    "item search",
+		steps: []migrationStep{
+			addIndex("items", "ft_items_search", "FULLTEXT", "title, description"),
+		},
+	},
+	{
+		version: 9,
+		name:    "photo positions",
+		steps: []migrationStep{
+			addColumn("photos", "position", "int NOT NULL DEFAULT 0"),
+			addColumn("photos", "is_primary", "tinyint(1) NOT NULL DEFAULT 0"),
+			// Photos stored before positions existed keep their insertion order, and the first one
+			// of every item without a primary photo becomes the primary one.
+			execStatement(`UPDATE photos p JOIN (SELECT item_id, MIN(id) AS id FROM photos
+		GROUP BY item_id HAVING MAX(is_primary)=0) f ON p.id=f.id SET p.is_primary=1`),
+		},
+	},
+	{
+		version: 10,
+		name:    "photo blobs",
+		steps: []migrationStep{
+			addColumn("photos", "blob_key", "varchar(255) NOT NULL DEFAULT ''"),
+			addIndex("photos", "idx_photos_blob_key", "", "blob_key"),
+		},
+	},
+	{
+		version: 11,
+		name:    "photo variants",
+		steps: []migrationStep{
+			addColumn("photos", "variants", "text DEFAULT NULL"),
+		},
+	},
+	{
+		version: 12,
+		name:    "photo checks",
+		steps: []migrationStep{
+			addColumn("photos", "check_status", "varchar(16) DEFAULT NULL"),
+			addColumn("photos", "check_status_code", "int DEFAULT NULL"),
+			addColumn("photos", "check_error", "varchar(255) DEFAULT NULL"),
+			addColumn("photos", "checked_at", "datetime(3) DEFAULT NULL"),
+			addColumn("photos", "check_deactivates", "tinyint(1) NOT NULL DEFAULT 0"),
+			addColumn("photos", "check_failures", "int NOT NULL DEFAULT 0"),
+			addIndex("photos", "idx_photos_check_status", "", "check_status"),
+		},
+	},
+	{
+		version: 13,
+		name:    "photo hashes",
+		steps: []migrationStep{
+			addColumn("photos", "phash", "bigint(20) unsigned DEFAULT NULL"),
+			// photo_hash_chunks indexes every part of the photo hashes, so the photos with a similar
+			// hash are found from the parts of a hash without comparing it to every stored hash.
+			execStatement(`CREATE TABLE IF NOT EXISTS photo_hash_chunks (
+		chunk tinyint unsigned NOT NULL,
+		value smallint unsigned NOT NULL,
+		photo_id bigint(20) unsigned NOT NULL,
+		PRIMARY KEY (chunk, value, photo_id),
+		KEY idx_photo_hash_chunks_photo (photo_id),
+		CONSTRAINT fk_photos_photo_hash_chunks FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE
+	  );`),
+		},
+	},
+	{
+		version: 14,
+		name:    "item revision baselines",
+		steps: []migrationStep{
+			backfillItemRevisions,
+		},
+	},
+	{
+		version: 15,
+		name:    "import error arguments",
+		steps: []migrationStep{
+			// args keeps the arguments of the errors of a whole row, which the error report
+			// translates by their code.
+			addColumn("import_errors", "args", "text"),
+		},
+	},
+}
+
+// revisionBackfillBatch is how many items without revisions get their baseline at a time.
+const revisionBackfillBatch = 500
+
+// Migrate applies the migrations not applied yet, in order, and returns them.
+func Migrate(db *sqlx.DB) ([]Migration, error) {
+	if _, err := db.Exec(schemaMigrationsSchema); err != nil {
+		return nil, migrationError(err)
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, migrationError(err)
+	}
+
+	var migrated []Migration
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		for _, step := range m.steps {
+			if err := step(db); err != nil {
+				return migrated, migrationError(fmt.Errorf("migration %d %s: %w", m.version, m.name, err))
+			}
+		}
+
+		_, err := db.Exec("INSERT IGNORE INTO schema_migrations (version, name, applied_at) VALUES (?,?,?)",
+			m.version, m.name, time.Now())
+		if err != nil {
+			return migrated, migrationError(err)
+		}
+
+		migrated = append(migrated, Migration{Version: m.version, Name: m.name})
+	}
+
+	return migrated, nil
+}
+
+// checkSchema fails unless every migration has been applied, so a database that was not
+// migrated fails at startup rather than on the first query that needs the missing schema.
+func checkSchema(db *sqlx.DB) error {
+	var count int
+	err := db.Get(&count, `SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema=DATABASE() AND table_name='schema_migrations'`)
+	if err != nil {
+		return fmt.Errorf("### DB ERROR: %w", err)
+	}
+
+	applied := map[int]bool{}
+	if count > 0 {
+		if applied, err = appliedVersions(db); err != nil {
+			return fmt.Errorf("### DB ERROR: %w", err)
+		}
+	}
+
+	for _, m := range migrations {
+		if !applied[m.version] {
+			return fmt.Errorf("### MIGRATION ERROR: migration %d %s is not applied, run itemctl migrate",
+				m.version, m.name)
+		}
+	}
+
+	return nil
+}
+
+func appliedVersions(db *sqlx.DB) (map[int]bool, error) {
+	var versions []int
+	if err := db.Select(&versions, "SELECT version FROM schema_migrations"); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(versions))
+	for _, version := range versions {
+		applied[version] = true
+	}
+
+	return applied, nil
+}
+
+// backfillItemRevisions records a baseline revision of every item without revisions, so the
+// history and the point-in-time reads of the items created before the revisions were recorded
+// start at their state when migrated.
+func backfillItemRevisions(db *sqlx.DB) error {
+	repo := &itemRepository{conn: db}
+
+	var afterID uint
+
+	for {
+		var ids []uint
+		err := db.Select(&ids, `SELECT i.id FROM items i WHERE i.id>? AND NOT EXISTS
+			(SELECT 1 FROM item_revisions r WHERE r.item_id=i.id) ORDER BY i.id LIMIT ?`,
+			afterID, revisionBackfillBatch)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := saveBaselines(db, repo, ids); err != nil {
+			return err
+		}
+
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// saveBaselines records the baseline revisions of a batch of items in one transaction.
+func saveBaselines(db *sqlx.DB, repo *itemRepository, ids []uint) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	items, err := repo.getItems(tx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := saveRevision(tx, domain.RevisionOperationBaseline, item, item.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func migrationError(err error) error {
+	return fmt.Errorf("### MIGRATION ERROR: %w", err)
+}
+
+func execStatement(query string) migrationStep {
+	return func(db *sqlx.DB) error {
+		_, err := db.Exec(query)
+
+		return err
+	}
+}
+
+func addColumn(table, column, definition string) migrationStep {
+	return func(db *sqlx.DB) error {
+		return addColumnIfNotExists(db, table, column, definition)
+	}
+}
+
+func addIndex(table, index, kind, columns string) migrationStep {
+	return func(db *sqlx.DB) error {
+		return addIndexIfNotExists(db, table, index, kind, columns)
+	}
+}
+
+// addColumnIfNotExists adds a column to a table created by a previous version of the schema.
+func addColumnIfNotExists(db *sqlx.DB, table, column, definition string) error {
+	var count int
+	err := db.Get(&count, `SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema=DATABASE() AND table_name=? AND column_name=?`, table, column)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+
+	return err
+}
+
+// addIndexIfNotExists adds an index of the given kind, e.g. UNIQUE or FULLTEXT, to a table
+// created by a previous version of the schema.
+func addIndexIfNotExists(db *sqlx.DB, table, index, kind, columns string) error {
+	var count int
+	err := db.Get(&count, `SELECT COUNT(*) FROM information_schema.statistics
+		WHERE table_schema=DATABASE() AND table_name=? AND index_name=?`, table, index)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD %s INDEX %s (%s)", table, kind, index, columns))
+
+	return err
+}
@@ -0,0 +1,15 @@
+package mysql
+
+import "testing"
+
+func TestMigrationsAreOrderedByVersion(t *testing.T) {
+	for n, m := range migrations {
+		if m.version != n+1 {
+			t.Errorf("migration %q has version %d, want %d", m.name, m.version, n+1)
+		}
+
+		if m.name == "" || len(m.steps) == 0 {
+			t.Errorf("migration %d has no name or no steps", m.version)
+		}
+	}
+}
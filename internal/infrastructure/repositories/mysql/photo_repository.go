@@ -0,0 +1,357 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type photoRepository struct {
+	conn  *sqlx.DB
+	items *itemRepository
+}
+
+func NewPhotoRepository(conn *sqlx.DB) (ports.PhotoRepository, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("mysql connection cannot be nil")
+	}
+
+	return &photoRepository{conn: conn, items: &itemRepository{conn: conn}}, nil
+}
+
+// SetPhotoVariants gives a new version to the items of the photos whose variants change, see
+// saveItemVersions. Setting the same variants again, e.g. when the job is retried, changes nothing.
+func (repo *photoRepository) SetPhotoVariants(ctx context.Context, blobKey string,
+	variants map[string]string) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering PhotoRepository. SetPhotoVariants()")
+
+	tx, err := repo.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("transaction initialization error: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	encoded := encodeVariants(variants)
+
+	itemIDs, err := lockPhotoItems(tx, "SELECT item_id FROM photos WHERE blob_key=? AND NOT variants<=>?",
+		blobKey, encoded)
+	if err != nil {
+		return fmt.Errorf("error updating photo variants: %w", err)
+	}
+
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	updatedAt := time.Now()
+	_, err = tx.Exec("UPDATE photos SET variants=?, updated_at=? WHERE blob_key=? AND NOT variants<=>?",
+		encoded, updatedAt, blobKey, encoded)
+	if err != nil {
+		return fmt.Errorf("error updating photo variants: %w", err)
+	}
+
+	if err := repo.saveItemVersions(tx, itemIDs, updatedAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error updating photo variants: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *photoRepository) FindPhotosToCheck(ctx context.Context, afterID uint, limit int) ([]domain.Photo, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering PhotoRepository. FindPhotosToCheck()")
+
+	var photos []Photo
+	err := repo.conn.SelectContext(ctx, &photos, "SELECT * FROM photos WHERE blob_key='' AND id>? ORDER BY id LIMIT ?",
+		afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting photos: %w", err)
+	}
+
+	return unmarshalPhotos(photos), nil
+}
+
+// SetPhotoCheck gives a new version to the items of the photos, see saveItemVersions, as every
+// check is shown by the photos of their items, at least by the time it was made.
+func (repo *photoRepository) SetPhotoCheck(ctx context.Context, photoIDs []uint, check domain.PhotoCheck) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering PhotoRepository. SetPhotoCheck()")
+
+	if len(photoIDs) == 0 {
+		return nil
+	}
+
+	tx, err := repo.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("transaction initialization error: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	query, args, err := sqlx.In("SELECT item_id FROM photos WHERE id IN (?)", photoIDs)
+	if err != nil {
+		return fmt.Errorf("error updating photo check: %w", err)
+	}
+
+	itemIDs, err := lockPhotoItems(tx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error updating photo check: %w", err)
+	}
+
+	query, args, err = sqlx.In(`UPDATE photos SET check_status=?, check_status_code=?, check_error=?,
+		checked_at=?, check_failures=?, check_deactivates=? WHERE id IN (?)`, check.Status,
+		sql.NullInt64{Int64: int64(check.StatusCode), Valid: check.StatusCode != 0},
+		sql.NullString{String: truncate(check.Error, checkErrorLength), Valid: check.Error != ""},
+		check.CheckedAt, check.Failures, check.DeactivatesItem, photoIDs)
+	if err != nil {
+		return fmt.Errorf("error updating photo check: %w", err)
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("error updating photo check: %w", err)
+	}
+
+	if err := repo.saveItemVersions(tx, itemIDs, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error updating photo check: %w", err)
+	}
+
+	return nil
+}
+
+// GetPhotoCheckReport counts the photos given by URL with one query, and lists the photos of
+// the filter with another.
+func (repo *photoRepository) GetPhotoCheckReport(ctx context.Context,
+	filter domain.PhotoCheckFilter) (*domain.PhotoCheckReport, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering PhotoRepository. GetPhotoCheckReport()")
+
+	var counts struct {
+		Total       int           `db:"total"`
+		Unchecked   sql.NullInt64 `db:"unchecked"`
+		OK          sql.NullInt64 `db:"ok"`
+		Broken      sql.NullInt64 `db:"broken"`
+		Unreachable sql.NullInt64 `db:"unreachable"`
+	}
+
+	err := repo.conn.GetContext(ctx, &counts, `SELECT COUNT(*) AS total,
+		SUM(check_status IS NULL) AS unchecked, SUM(check_status=?) AS ok, SUM(check_status=?) AS broken,
+		SUM(check_status=?) AS unreachable FROM photos WHERE blob_key=''`, domain.PhotoCheckOK,
+		domain.PhotoCheckBroken, domain.PhotoCheckUnreachable)
+	if err != nil {
+		return nil, fmt.Errorf("error counting photo checks: %w", err)
+	}
+
+	report := &domain.PhotoCheckReport{
+		Total:       counts.Total,
+		Unchecked:   int(counts.Unchecked.Int64),
+		OK:          int(counts.OK.Int64),
+		Broken:      int(counts.Broken.Int64),
+		Unreachable: int(counts.Unreachable.Int64),
+	}
+
+	err = repo.conn.GetContext(ctx, &report.DeactivatedItems, `SELECT COUNT(*) FROM photos p
+		JOIN items i ON i.id=p.item_id WHERE p.is_primary=1 AND p.check_deactivates=1 AND i.status=?`,
+		domain.StatusInactive)
+	if err != nil {
+		return nil, fmt.Errorf("error counting deactivated items: %w", err)
+	}
+
+	condition, args := "check_status=?", []interface{}{filter.Status}
+	if filter.Status == domain.PhotoCheckUnchecked {
+		condition, args = "check_status IS NULL", nil
+	}
+
+	var photos []Photo
+	err = repo.conn.SelectContext(ctx, &photos, "SELECT * FROM photos WHERE blob_key='' AND "+condition+
+		" AND id>? ORDER BY id LIMIT ?", append(args, filter.AfterID, filter.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting photos: %w", err)
+	}
+
+	report.Photos = unmarshalPhotos(photos)
+
+	return report, nil
+}
+
+// SetPhotoHash also replaces the hash parts of the photos, see FindSimilarPhotos, and gives a new
+// version to the items of the photos whose hash changes, see saveItemVersions.
+func (repo *photoRepository) SetPhotoHash(ctx context.Context, blobKey string, hash uint64) error {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering PhotoRepository. SetPhotoHash()")
+
+	tx, err := repo.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("transaction initialization error: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	itemIDs, err := lockPhotoItems(tx, "SELECT item_id FROM photos WHERE blob_key=? AND NOT phash<=>?",
+		blobKey, hash)
+	if err != nil {
+		return fmt.Errorf("error updating photo hash: %w", err)
+	}
+
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	var photoIDs []uint
+	err = tx.Select(&photoIDs, "SELECT id FROM photos WHERE blob_key=? AND NOT phash<=>? FOR UPDATE", blobKey, hash)
+	if err != nil {
+		return fmt.Errorf("error getting photos: %w", err)
+	}
+
+	updatedAt := time.Now()
+	query, args, err := sqlx.In("UPDATE photos SET phash=?, updated_at=? WHERE id IN (?)", hash, updatedAt, photoIDs)
+	if err != nil {
+		return fmt.Errorf("error updating photo hash: %w", err)
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("error updating photo hash: %w", err)
+	}
+
+	query, args, err = sqlx.In("DELETE FROM photo_hash_chunks WHERE photo_id IN (?)", photoIDs)
+	if err != nil {
+		return fmt.Errorf("error updating photo hash: %w", err)
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("error updating photo hash: %w", err)
+	}
+
+	valueStrings := make([]string, 0, len(photoIDs)*domain.PhotoHashChunks)
+	valueArgs := make([]interface{}, 0, len(photoIDs)*domain.PhotoHashChunks*3)
+
+	for _, photoID := range photoIDs {
+		for i := 0; i < domain.PhotoHashChunks; i++ {
+			valueStrings = append(valueStrings, "(?,?,?)")
+			valueArgs = append(valueArgs, i, domain.PhotoHashChunk(hash, i), photoID)
+		}
+	}
+
+	stmt := "INSERT INTO photo_hash_chunks (chunk, value, photo_id) VALUES " + strings.Join(valueStrings, ",")
+	if _, err := tx.Exec(stmt, valueArgs...); err != nil {
+		return fmt.Errorf("error updating photo hash: %w", err)
+	}
+
+	if err := repo.saveItemVersions(tx, itemIDs, updatedAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error updating photo hash: %w", err)
+	}
+
+	return nil
+}
+
+// FindSimilarPhotos is a multi-index hashing search: a hash at most maxDistance bits from hash
+// has a part at most maxDistance/PhotoHashChunks bits from the same part of hash, so the
+// candidates are the photos with such a part, found in photo_hash_chunks, and their whole hash
+// is then compared.
+func (repo *photoRepository) FindSimilarPhotos(ctx context.Context, itemID uint, hash uint64,
+	maxDistance int) ([]domain.Photo, error) {
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(repo, nil, "Entering PhotoRepository. FindSimilarPhotos()")
+
+	radius := maxDistance / domain.PhotoHashChunks
+	conditions := make([]string, 0, domain.PhotoHashChunks)
+	args := make([]interface{}, 0, domain.PhotoHashChunks*2+3)
+
+	for i := 0; i < domain.PhotoHashChunks; i++ {
+		conditions = append(conditions, "(chunk=? AND value IN (?))")
+		args = append(args, i, domain.PhotoHashChunkNeighbours(domain.PhotoHashChunk(hash, i), radius))
+	}
+
+	args = append(args, itemID, hash, maxDistance)
+
+	query, args, err := sqlx.In(`SELECT * FROM photos WHERE id IN (SELECT photo_id FROM photo_hash_chunks
+		WHERE `+strings.Join(conditions, " OR ")+`) AND item_id<>? AND BIT_COUNT(phash ^ ?)<=? ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting similar photos: %w", err)
+	}
+
+	var photos []Photo
+	if err := repo.conn.SelectContext(ctx, &photos, query, args...); err != nil {
+		return nil, fmt.Errorf("error getting similar photos: %w", err)
+	}
+
+	return unmarshalPhotos(photos), nil
+}
+
+// checkErrorLength is the size of the check_error column.
+const checkErrorLength = 255
+
+// truncate cuts value to at most length bytes without splitting a character.
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	for length > 0 && !utf8.RuneStart(value[length]) {
+		length--
+	}
+
+	return value[:length]
+}
+
+// lockPhotoItems locks the items with the IDs selected by photoItems, a query of the item_id of
+// some photos, before the photos are changed, as UpdateItem locks an item before its photos.
+func lockPhotoItems(tx *sqlx.Tx, photoItems string, args ...interface{}) ([]uint, error) {
+	var itemIDs []uint
+	err := tx.Select(&itemIDs, "SELECT id FROM items WHERE id IN ("+photoItems+") ORDER BY id FOR UPDATE", args...)
+
+	return itemIDs, err
+}
+
+// saveItemVersions gives the items a new version, recorded as a revision, in the transaction
+// that changed their photos: the photos are part of the responses of the items, whose ETag is
+// their version, so the version changes along with any field of their photos.
+func (repo *photoRepository) saveItemVersions(tx *sqlx.Tx, itemIDs []uint, updatedAt time.Time) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In("UPDATE items SET version=version+1, updated_at=? WHERE id IN (?)",
+		updatedAt, itemIDs)
+	if err != nil {
+		return fmt.Errorf("error updating item versions: %w", err)
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("error updating item versions: %w", err)
+	}
+
+	items, err := repo.items.getItems(tx, itemIDs)
+	if err != nil {
+		return err
+	}
+
+	return saveRevisions(tx, domain.RevisionOperationUpdate, items, updatedAt)
+}
+
+func unmarshalPhotos(photos []Photo) []domain.Photo {
+	result := make([]domain.Photo, 0, len(photos))
+	for _, photo := range photos {
+		result = append(result, photo.unmarshal())
+	}
+
+	return result
+}
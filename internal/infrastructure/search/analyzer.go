@@ -0,0 +1,149 @@
+// Package search holds the text analysis shared by the item search indexes, so every index
+// and the highlighting agree on what a term is.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minStemLength is the shortest token the stemmer shortens, so short words keep their meaning.
+const minStemLength = 4
+
+// foldings maps the accented letters of Spanish and Portuguese to their plain letter.
+var foldings = map[rune]rune{ //nolint:gochecknoglobals
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// stopWords are frequent Spanish, Portuguese and English words left out of the index.
+var stopWords = map[string]bool{ //nolint:gochecknoglobals
+	"a": true, "an": true, "and": true, "as": true, "com": true, "con": true, "da": true, "das": true,
+	"de": true, "del": true, "do": true, "dos": true, "e": true, "el": true, "em": true, "en": true,
+	"for": true, "la": true, "las": true, "los": true, "na": true, "no": true, "o": true, "of": true,
+	"os": true, "para": true, "por": true, "the": true, "um": true, "uma": true, "un": true,
+	"una": true, "with": true, "y": true,
+}
+
+// Token is a term of a text and the byte offsets of the word it comes from.
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize splits text into words of letters and digits and turns each into its term,
+// skipping stop words.
+func Tokenize(text string) []Token {
+	var tokens []Token
+
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start < 0 {
+			continue
+		}
+
+		if term := Term(text[start:i]); term != "" {
+			tokens = append(tokens, Token{Term: term, Start: start, End: i})
+		}
+
+		start = -1
+	}
+
+	return tokens
+}
+
+// Terms returns the distinct terms of text in order of appearance.
+func Terms(text string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+
+	for _, token := range Tokenize(text) {
+		if !seen[token.Term] {
+			seen[token.Term] = true
+			terms = append(terms, token.Term)
+		}
+	}
+
+	return terms
+}
+
+// Term lower-cases, folds and stems a single word, or returns "" for a stop word.
+func Term(word string) string {
+	folded := Fold(word)
+	if stopWords[folded] {
+		return ""
+	}
+
+	return Stem(folded)
+}
+
+// Fold lower-cases text and removes the accents of its letters.
+func Fold(text string) string {
+	var builder strings.Builder
+	builder.Grow(len(text))
+
+	for _, r := range strings.ToLower(text) {
+		if folded, ok := foldings[r]; ok {
+			r = folded
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+// Stem is a light stemmer for folded Spanish and Portuguese words, which also fits most
+// English plurals. It removes the plural and the final gender vowel, so "rojas", "rojo" and
+// "roja" share the term "roj", and "cables" and "cable" share "cabl".
+func Stem(word string) string {
+	runes := []rune(word)
+	n := len(runes)
+	if n < minStemLength {
+		return word
+	}
+
+	switch {
+	case hasSuffix(runes, "oes"), hasSuffix(runes, "aes"):
+		// Portuguese -ões and -ães plurals of -ão, e.g. "botoes" and "botao".
+		return string(runes[:n-3]) + "a"
+	case hasSuffix(runes, "ns") && isVowel(runes[n-3]):
+		// Portuguese -ns plurals of -m, e.g. "itens" and "item".
+		return string(runes[:n-2]) + "m"
+	case hasSuffix(runes, "ces"):
+		// Spanish -ces plurals of -z, e.g. "luces" and "luz".
+		return string(runes[:n-3]) + "z"
+	case runes[n-1] == 's' && isGenderVowel(runes[n-2]):
+		return string(runes[:n-2])
+	case runes[n-1] == 's' && !isVowel(runes[n-2]) && runes[n-2] != 's':
+		// English plurals of words ending in a consonant, e.g. "patterns".
+		return string(runes[:n-1])
+	case isGenderVowel(runes[n-1]):
+		return string(runes[:n-1])
+	}
+
+	return word
+}
+
+func hasSuffix(runes []rune, suffix string) bool {
+	return strings.HasSuffix(string(runes), suffix)
+}
+
+func isVowel(r rune) bool {
+	return isGenderVowel(r) || r == 'i' || r == 'u'
+}
+
+func isGenderVowel(r rune) bool {
+	return r == 'a' || r == 'e' || r == 'o'
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTerm(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "Lámpara", want: "lampar"},
+		{word: "lamparas", want: "lampar"},
+		{word: "ROJAS", want: "roj"},
+		{word: "roja", want: "roj"},
+		{word: "cables", want: "cabl"},
+		{word: "luces", want: "luz"},
+		{word: "luz", want: "luz"},
+		{word: "botões", want: "bota"},
+		{word: "botão", want: "bota"},
+		{word: "itens", want: "item"},
+		{word: "item", want: "item"},
+		{word: "patterns", want: "pattern"},
+		{word: "glass", want: "glass"},
+		{word: "Niño", want: "nin"},
+		{word: "sol", want: "sol"},
+		{word: "De", want: ""},
+		{word: "the", want: ""},
+		{word: "para", want: ""},
+	}
+
+	for _, test := range tests {
+		if got := Term(test.word); got != test.want {
+			t.Errorf("Term(%q) = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	text := "Lámpara de pie, 2 luces"
+
+	want := []Token{
+		{Term: "lampar", Start: 0, End: 8},
+		{Term: "pie", Start: 12, End: 15},
+		{Term: "2", Start: 17, End: 18},
+		{Term: "luz", Start: 19, End: 24},
+	}
+
+	if got := Tokenize(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize(%q) = %v, want %v", text, got, want)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "lámparas rojas y lámpara roja", want: []string{"lampar", "roj"}},
+		{text: "the lamp of the desk", want: []string{"lamp", "desk"}},
+		{text: "de la y", want: nil},
+		{text: "  ", want: nil},
+	}
+
+	for _, test := range tests {
+		if got := Terms(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Terms(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		query     string
+		want      string
+		wantMatch bool
+	}{
+		{
+			name:      "every form of the term",
+			text:      "Lámpara roja con 2 lámparas",
+			query:     "lampara",
+			want:      "<em>Lámpara</em> roja con 2 <em>lámparas</em>",
+			wantMatch: true,
+		},
+		{
+			name:      "text escaped",
+			text:      "Cable <USB> & cargador",
+			query:     "cables",
+			want:      "<em>Cable</em> &lt;USB&gt; &amp; cargador",
+			wantMatch: true,
+		},
+		{
+			name:      "no match",
+			text:      "Silla de escritorio",
+			query:     "lampara",
+			want:      "Silla de escritorio",
+			wantMatch: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, matched := Highlight(test.text, Terms(test.query))
+			if got != test.want || matched != test.wantMatch {
+				t.Errorf("Highlight() = %q, %v, want %q, %v", got, matched, test.want, test.wantMatch)
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("silla de escritorio ", 20) + "con lámpara " + strings.Repeat("y base de metal ", 20)
+
+	got, matched := Snippet(text, Terms("lampara"))
+	if !matched {
+		t.Fatalf("Snippet() matched = false, want true")
+	}
+
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("Snippet() = %q, want the text cut on both sides", got)
+	}
+
+	if !strings.Contains(got, "<em>lámpara</em>") {
+		t.Errorf("Snippet() = %q, want the match highlighted", got)
+	}
+
+	if len(got) > snippetLength+len(highlightOpen+highlightClose)+2*len("…") {
+		t.Errorf("Snippet() is %d bytes long, want at most about %d", len(got), snippetLength)
+	}
+}
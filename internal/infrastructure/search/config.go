@@ -0,0 +1,44 @@
+package search
+
+import (
+	"os"
+	"time"
+)
+
+var (
+	searchIndex               = "SEARCH_INDEX"
+	suggestionRefreshInterval = "SUGGESTION_REFRESH_INTERVAL"
+)
+
+const (
+	IndexMySQL  = "mysql"
+	IndexMemory = "memory"
+)
+
+type Config struct {
+	// Index is the item search index: mysql searches the items table through its FULLTEXT
+	// index, memory keeps a BM25 inverted index in process, filled at startup. The memory index
+	// only sees the writes served by its own instance, so it is meant for a single instance,
+	// e.g. development, and mysql for any deployment with replicas.
+	Index string
+	// SuggestionRefreshInterval is how often every instance rebuilds its suggestions, so the
+	// writes served by the other instances reach them.
+	SuggestionRefreshInterval time.Duration
+}
+
+func LoadConfig() Config {
+	index := os.Getenv(searchIndex)
+	if index != IndexMemory {
+		index = IndexMySQL
+	}
+
+	refreshInterval, err := time.ParseDuration(os.Getenv(suggestionRefreshInterval))
+	if err != nil || refreshInterval <= 0 {
+		refreshInterval = 10 * time.Minute
+	}
+
+	return Config{
+		Index:                     index,
+		SuggestionRefreshInterval: refreshInterval,
+	}
+}
@@ -0,0 +1,141 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	highlightOpen  = "<em>"
+	highlightClose = "</em>"
+	// snippetLength bounds in bytes the text around the first match kept by Snippet.
+	snippetLength = 160
+)
+
+// Highlights returns, for each field with a matching word, the snippet of its text with the matches highlighted.
+func Highlights(fields map[string]string, terms []string) map[string]string {
+	highlighted := map[string]string{}
+
+	for field, text := range fields {
+		if snippet, ok := Snippet(text, terms); ok {
+			highlighted[field] = snippet
+		}
+	}
+
+	return highlighted
+}
+
+// Highlight wraps in <em> the words of text whose term is one of terms. The rest of the
+// text is HTML escaped, so the result can be rendered as is. It returns false when no word matches.
+func Highlight(text string, terms []string) (string, bool) {
+	return highlight(text, terms, 0, len(text))
+}
+
+// Snippet is Highlight on a window of about snippetLength bytes around the first match,
+// with an ellipsis where the text was cut.
+func Snippet(text string, terms []string) (string, bool) {
+	if len(text) <= snippetLength {
+		return Highlight(text, terms)
+	}
+
+	wanted := termSet(terms)
+	for _, token := range Tokenize(text) {
+		if !wanted[token.Term] {
+			continue
+		}
+
+		start := token.Start - snippetLength/4
+		if start < 0 {
+			start = 0
+		}
+
+		end := start + snippetLength
+		if end > len(text) {
+			end = len(text)
+		}
+
+		start, end = wordStart(text, start), wordEnd(text, end)
+
+		snippet, _ := highlight(text, terms, start, end)
+		if start > 0 {
+			snippet = "…" + snippet
+		}
+		if end < len(text) {
+			snippet += "…"
+		}
+
+		return snippet, true
+	}
+
+	return "", false
+}
+
+func highlight(text string, terms []string, start, end int) (string, bool) {
+	wanted := termSet(terms)
+
+	var builder strings.Builder
+	matched := false
+	last := start
+
+	for _, token := range Tokenize(text[start:end]) {
+		if !wanted[token.Term] {
+			continue
+		}
+
+		builder.WriteString(html.EscapeString(text[last : start+token.Start]))
+		builder.WriteString(highlightOpen)
+		builder.WriteString(html.EscapeString(text[start+token.Start : start+token.End]))
+		builder.WriteString(highlightClose)
+
+		last = start + token.End
+		matched = true
+	}
+
+	builder.WriteString(html.EscapeString(text[last:end]))
+
+	return builder.String(), matched
+}
+
+// wordStart moves a snippet start forward to the beginning of a word.
+func wordStart(text string, start int) int {
+	if start == 0 {
+		return 0
+	}
+
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+
+	if space := strings.IndexByte(text[start:], ' '); space >= 0 && space < snippetLength/4 {
+		return start + space + 1
+	}
+
+	return start
+}
+
+// wordEnd moves a snippet end back to the end of a word.
+func wordEnd(text string, end int) int {
+	if end == len(text) {
+		return end
+	}
+
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	if space := strings.LastIndexByte(text[:end], ' '); space >= 0 && end-space < snippetLength/4 {
+		return space
+	}
+
+	return end
+}
+
+func termSet(terms []string) map[string]bool {
+	set := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		set[term] = true
+	}
+
+	return set
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+// AuditHandler reads the audit log, whose entries carry the states of the items, the actors and
+// their client IPs, so its routes are only served to the callers with an admin key.
+type AuditHandler interface {
+	GetAuditEntries(res http.ResponseWriter, req *http.Request) error
+	VerifyAuditChain(res http.ResponseWriter, req *http.Request) error
+}
+
+type auditHandler struct {
+	auditService ports.AuditService
+}
+
+func NewAuditHandler(auditService ports.AuditService) (AuditHandler, error) {
+	if auditService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	return &auditHandler{
+		auditService: auditService,
+	}, nil
+}
+
+func (h *auditHandler) GetAuditEntries(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering AuditHandler. GetAuditEntries()")
+
+	filter, err := parseAuditFilter(req.URL.Query())
+	if err != nil {
+		logger.Error(h, nil, err, "error validating query params")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	entries, err := h.auditService.FindAuditEntries(ctx, filter)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting audit entries")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.AuditEntriesResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateAuditEntriesResponse(entries),
+	}, http.StatusOK)
+}
+
+func (h *auditHandler) VerifyAuditChain(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering AuditHandler. VerifyAuditChain()")
+
+	brokenAtID, err := h.auditService.VerifyAuditChain(ctx)
+	if err != nil {
+		logger.Error(h, nil, err, "error verifying audit chain")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	if brokenAtID != 0 {
+		logger.With(log.Uint("audit_entry_id", brokenAtID)).Warn(h, nil,
+			"audit chain is broken")
+	}
+
+	return web.EncodeJSON(res, dto.AuditVerificationResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data: dto.AuditVerificationPayload{
+			Valid:      brokenAtID == 0,
+			BrokenAtID: brokenAtID,
+		},
+	}, http.StatusOK)
+}
+
+func parseAuditFilter(query url.Values) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		EntityType: query.Get("entityType"),
+		Actor:      query.Get("actor"),
+		Limit:      defaultAuditLimit,
+	}
+
+	if entityID := query.Get("entityId"); entityID != "" {
+		id, err := uintParam(entityID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid entityId: %s", entityID)
+		}
+
+		filter.EntityID = id
+	}
+
+	if afterID := query.Get("afterId"); afterID != "" {
+		id, err := uintParam(afterID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid afterId: %s", afterID)
+		}
+
+		filter.AfterID = id
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > maxAuditLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxAuditLimit)
+		}
+
+		filter.Limit = value
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+			}
+
+			*target = parsed
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// BlobHandler serves the content of the blob store, which is where the URLs of the local
+// store point to.
+type BlobHandler interface {
+	GetBlob(res http.ResponseWriter, req *http.Request) error
+}
+
+type blobHandler struct {
+	blobStore ports.BlobStore
+}
+
+func NewBlobHandler(blobStore ports.BlobStore) (BlobHandler, error) {
+	if blobStore == nil {
+		return nil, fmt.Errorf("blob store cannot be nil")
+	}
+
+	return &blobHandler{
+		blobStore: blobStore,
+	}, nil
+}
+
+// GetBlob lets clients cache the blobs forever, since a key always addresses the same content.
+// Browsers must not sniff the content, so an uploaded file is never run as another type.
+func (h *blobHandler) GetBlob(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering BlobHandler. GetBlob()")
+
+	key := web.Params(req)["key"]
+	etag := fmt.Sprintf("%q", key)
+
+	if ifNoneMatch(req, etag) {
+		res.Header().Set("ETag", etag)
+		res.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	content, contentType, err := h.blobStore.Get(ctx, key)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting blob")
+		return problem.Write(ctx, res, req, err)
+	}
+	defer content.Close()
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	res.Header().Set("Content-Type", contentType)
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	res.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	res.Header().Set("ETag", etag)
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(res, content); err != nil {
+		logger.Error(h, nil, err, "error writing blob")
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+var (
+	requireIfMatch     = "REQUIRE_IF_MATCH"
+	bulkMaxItems       = "BULK_MAX_ITEMS"
+	importMaxBytes     = "IMPORT_MAX_BYTES"
+	searchPriceBuckets = "SEARCH_PRICE_BUCKETS"
+	photoMaxBytes      = "PHOTO_UPLOAD_MAX_BYTES"
+)
+
+const (
+	defaultBulkMaxItems   = 1000
+	defaultImportMaxBytes = 32 << 20
+	defaultPhotoMaxBytes  = 10 << 20
+)
+
+var defaultSearchPriceEdges = []int{1000, 5000, 20000, 100000} //nolint:gochecknoglobals
+
+type ItemHandlerConfig struct {
+	// RequireIfMatch makes update, patch and delete fail with 428 when If-Match is missing.
+	RequireIfMatch bool
+	// MaxBulkItems is the largest batch accepted by a bulk creation.
+	MaxBulkItems int
+}
+
+func LoadItemHandlerConfig() ItemHandlerConfig {
+	required, _ := strconv.ParseBool(os.Getenv(requireIfMatch))
+
+	maxItems, err := strconv.Atoi(os.Getenv(bulkMaxItems))
+	if err != nil || maxItems <= 0 {
+		maxItems = defaultBulkMaxItems
+	}
+
+	return ItemHandlerConfig{
+		RequireIfMatch: required,
+		MaxBulkItems:   maxItems,
+	}
+}
+
+type PhotoHandlerConfig struct {
+	// RequireIfMatch is the same setting as ItemHandlerConfig.RequireIfMatch, since every
+	// photo change is an update of the item.
+	RequireIfMatch bool
+	// MaxUploadBytes is the largest photo accepted by an upload.
+	MaxUploadBytes int64
+}
+
+func LoadPhotoHandlerConfig() PhotoHandlerConfig {
+	required, _ := strconv.ParseBool(os.Getenv(requireIfMatch))
+
+	maxBytes, err := strconv.ParseInt(os.Getenv(photoMaxBytes), 10, 64)
+	if err != nil || maxBytes <= 0 {
+		maxBytes = defaultPhotoMaxBytes
+	}
+
+	return PhotoHandlerConfig{
+		RequireIfMatch: required,
+		MaxUploadBytes: maxBytes,
+	}
+}
+
+type ImportHandlerConfig struct {
+	// MaxBytes is the largest import file accepted.
+	MaxBytes int64
+}
+
+func LoadImportHandlerConfig() ImportHandlerConfig {
+	maxBytes, err := strconv.ParseInt(os.Getenv(importMaxBytes), 10, 64)
+	if err != nil || maxBytes <= 0 {
+		maxBytes = defaultImportMaxBytes
+	}
+
+	return ImportHandlerConfig{
+		MaxBytes: maxBytes,
+	}
+}
+
+type SearchHandlerConfig struct {
+	// PriceRanges are the buckets of the price facet.
+	PriceRanges []domain.PriceRange
+}
+
+// LoadSearchHandlerConfig reads the price buckets as the comma separated prices where one
+// bucket ends and the next starts, e.g. "1000,5000" for *-1000, 1000-5000 and 5000-*.
+func LoadSearchHandlerConfig() SearchHandlerConfig {
+	edges := defaultSearchPriceEdges
+
+	if value := os.Getenv(searchPriceBuckets); value != "" {
+		parsed := make([]int, 0)
+		for _, field := range strings.Split(value, ",") {
+			edge, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || edge < 0 {
+				parsed = nil
+				break
+			}
+			parsed = append(parsed, edge)
+		}
+
+		if len(parsed) > 0 && sort.IntsAreSorted(parsed) {
+			edges = parsed
+		}
+	}
+
+	return SearchHandlerConfig{
+		PriceRanges: domain.PriceRanges(edges),
+	}
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+type AuditEntryResponse struct {
+	ID         uint            `json:"id"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"requestId"`
+	ClientIP   string          `json:"clientIp"`
+	Operation  string          `json:"operation"`
+	EntityType string          `json:"entityType"`
+	EntityID   uint            `json:"entityId"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	PrevHash   string          `json:"prevHash"`
+	Hash       string          `json:"hash"`
+}
+
+type AuditEntriesResponse struct {
+	Status  int                  `json:"status"`
+	Message string               `json:"message"`
+	Data    []AuditEntryResponse `json:"data"`
+}
+
+type AuditVerificationResponse struct {
+	Status  int                      `json:"status"`
+	Message string                   `json:"message"`
+	Data    AuditVerificationPayload `json:"data"`
+}
+
+type AuditVerificationPayload struct {
+	Valid      bool `json:"valid"`
+	BrokenAtID uint `json:"brokenAtId,omitempty"`
+}
+
+func CreateAuditEntriesResponse(entries []domain.AuditEntry) []AuditEntryResponse {
+	response := make([]AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, AuditEntryResponse{
+			ID:         entry.ID,
+			Actor:      entry.Actor,
+			RequestID:  entry.RequestID,
+			ClientIP:   entry.ClientIP,
+			Operation:  entry.Operation,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Before:     entry.Before,
+			After:      entry.After,
+			CreatedAt:  entry.CreatedAt,
+			PrevHash:   entry.PrevHash,
+			Hash:       entry.Hash,
+		})
+	}
+
+	return response
+}
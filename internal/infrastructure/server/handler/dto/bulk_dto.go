@@ -0,0 +1,49 @@
+package dto
+
+import "github.com/osalomon89/test-crud-api/internal/core/domain"
+
+// BulkItemsBody is the body of a bulk creation. The items are validated one by one, so a
+// partial batch reports every failing item instead of rejecting the whole request.
+type BulkItemsBody struct {
+	Mode  string     `json:"mode" binding:"omitempty,oneof=atomic partial"`
+	Items []ItemBody `json:"items" binding:"required,min=1"`
+}
+
+type BulkItemResultResponse struct {
+	Index  int           `json:"index"`
+	Status string        `json:"status"`
+	Item   *ItemResponse `json:"item,omitempty"`
+	Error  *Problem      `json:"error,omitempty"`
+}
+
+type BulkResultResponse struct {
+	Mode    string                   `json:"mode"`
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"`
+	Results []BulkItemResultResponse `json:"results"`
+}
+
+type BulkResponse struct {
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    *BulkResultResponse `json:"data"`
+}
+
+// CreateBulkResultResponse counts the created and failed items of the results.
+func CreateBulkResultResponse(mode string, results []BulkItemResultResponse) *BulkResultResponse {
+	response := &BulkResultResponse{
+		Mode:    mode,
+		Results: results,
+	}
+
+	for _, result := range results {
+		switch result.Status {
+		case domain.BulkStatusCreated:
+			response.Created++
+		case domain.BulkStatusFailed:
+			response.Failed++
+		}
+	}
+
+	return response
+}
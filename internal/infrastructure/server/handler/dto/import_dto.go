@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+type ImportResultResponse struct {
+	ID            uint       `json:"id"`
+	Format        string     `json:"format"`
+	DryRun        bool       `json:"dryRun"`
+	Status        string     `json:"status"`
+	Progress      int        `json:"progress"`
+	TotalRows     int        `json:"totalRows"`
+	ProcessedRows int        `json:"processedRows"`
+	CreatedRows   int        `json:"createdRows"`
+	UpdatedRows   int        `json:"updatedRows"`
+	FailedRows    int        `json:"failedRows"`
+	JobID         uint       `json:"jobId,omitempty"`
+	JobURL        string     `json:"jobUrl,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	ErrorsURL     string     `json:"errorsUrl,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+}
+
+type ImportResponse struct {
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    *ImportResultResponse `json:"data"`
+}
+
+// CreateImportResponse reports the progress as the percentage of processed rows, and links
+// the error report once the import has failing rows.
+func CreateImportResponse(itemImport *domain.Import) *ImportResultResponse {
+	response := &ImportResultResponse{
+		ID:            itemImport.ID,
+		Format:        itemImport.Format,
+		DryRun:        itemImport.DryRun,
+		Status:        itemImport.Status,
+		Progress:      100,
+		TotalRows:     itemImport.TotalRows,
+		ProcessedRows: itemImport.ProcessedRows,
+		CreatedRows:   itemImport.CreatedRows,
+		UpdatedRows:   itemImport.UpdatedRows,
+		FailedRows:    itemImport.FailedRows,
+		JobID:         itemImport.JobID,
+		Error:         itemImport.Error,
+		CreatedAt:     itemImport.CreatedAt,
+		UpdatedAt:     itemImport.UpdatedAt,
+		CompletedAt:   itemImport.CompletedAt,
+	}
+
+	if itemImport.TotalRows > 0 {
+		response.Progress = itemImport.ProcessedRows * 100 / itemImport.TotalRows
+	}
+
+	if itemImport.JobID != 0 {
+		response.JobURL = fmt.Sprintf("/v1/jobs/%d", itemImport.JobID)
+	}
+
+	if itemImport.FailedRows > 0 {
+		response.ErrorsURL = fmt.Sprintf("/v1/imports/%d/errors", itemImport.ID)
+	}
+
+	return response
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+type JobResultResponse struct {
+	ID              uint       `json:"id"`
+	Type            string     `json:"type"`
+	Status          string     `json:"status"`
+	Progress        int        `json:"progress"`
+	ProgressMessage string     `json:"progressMessage,omitempty"`
+	Attempts        int        `json:"attempts"`
+	MaxAttempts     int        `json:"maxAttempts"`
+	Error           string     `json:"error,omitempty"`
+	CancelRequested bool       `json:"cancelRequested"`
+	RunAt           time.Time  `json:"runAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+}
+
+type JobResponse struct {
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+	Data    *JobResultResponse `json:"data"`
+}
+
+// CreateJobResponse leaves out the payload, which belongs to the handler of the job.
+func CreateJobResponse(job *domain.Job) *JobResultResponse {
+	return &JobResultResponse{
+		ID:              job.ID,
+		Type:            job.Type,
+		Status:          job.Status,
+		Progress:        job.Progress,
+		ProgressMessage: job.ProgressMessage,
+		Attempts:        job.Attempts,
+		MaxAttempts:     job.MaxAttempts,
+		Error:           job.Error,
+		CancelRequested: job.CancelRequested,
+		RunAt:           job.RunAt,
+		CreatedAt:       job.CreatedAt,
+		UpdatedAt:       job.UpdatedAt,
+		StartedAt:       job.StartedAt,
+		CompletedAt:     job.CompletedAt,
+	}
+}
@@ -0,0 +1,15 @@
+package dto
+
+type LogLevelBody struct {
+	Level string `json:"level"`
+}
+
+type LogLevelPayload struct {
+	Level string `json:"level"`
+}
+
+type LogLevelResponse struct {
+	Status  int              `json:"status"`
+	Message string           `json:"message"`
+	Data    *LogLevelPayload `json:"data"`
+}
@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+type PhotoCheckResponse struct {
+	Status          string    `json:"status"`
+	StatusCode      int       `json:"statusCode,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	CheckedAt       time.Time `json:"checkedAt"`
+	Failures        int       `json:"failures"`
+	DeactivatesItem bool      `json:"deactivatesItem"`
+}
+
+type CheckedPhotoResponse struct {
+	ID        uint                `json:"id"`
+	ItemID    uint                `json:"itemId"`
+	Path      string              `json:"path"`
+	IsPrimary bool                `json:"isPrimary"`
+	Check     *PhotoCheckResponse `json:"check"`
+}
+
+// PhotoCheckReportPayload counts every photo given by URL, while Photos is the page selected
+// by the query params.
+type PhotoCheckReportPayload struct {
+	Total            int                    `json:"total"`
+	Unchecked        int                    `json:"unchecked"`
+	OK               int                    `json:"ok"`
+	Broken           int                    `json:"broken"`
+	Unreachable      int                    `json:"unreachable"`
+	DeactivatedItems int                    `json:"deactivatedItems"`
+	Photos           []CheckedPhotoResponse `json:"photos"`
+}
+
+type PhotoCheckReportResponse struct {
+	Status  int                     `json:"status"`
+	Message string                  `json:"message"`
+	Data    PhotoCheckReportPayload `json:"data"`
+}
+
+func CreatePhotoCheckReportResponse(report *domain.PhotoCheckReport) PhotoCheckReportPayload {
+	photos := make([]CheckedPhotoResponse, 0, len(report.Photos))
+	for _, photo := range report.Photos {
+		photos = append(photos, CheckedPhotoResponse{
+			ID:        photo.ID,
+			ItemID:    photo.ItemID,
+			Path:      photo.Path,
+			IsPrimary: photo.IsPrimary,
+			Check:     createPhotoCheckResponse(photo.Check),
+		})
+	}
+
+	return PhotoCheckReportPayload{
+		Total:            report.Total,
+		Unchecked:        report.Unchecked,
+		OK:               report.OK,
+		Broken:           report.Broken,
+		Unreachable:      report.Unreachable,
+		DeactivatedItems: report.DeactivatedItems,
+		Photos:           photos,
+	}
+}
+
+func createPhotoCheckResponse(check *domain.PhotoCheck) *PhotoCheckResponse {
+	if check == nil {
+		return nil
+	}
+
+	return &PhotoCheckResponse{
+		Status:          check.Status,
+		StatusCode:      check.StatusCode,
+		Error:           check.Error,
+		CheckedAt:       check.CheckedAt,
+		Failures:        check.Failures,
+		DeactivatesItem: check.DeactivatesItem,
+	}
+}
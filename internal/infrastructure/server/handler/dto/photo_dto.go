@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+// PhotoBody adds a photo at position, or at the end when position is missing.
+type PhotoBody struct {
+	Path      string `json:"path" binding:"required,max=2048,photourl,photohost"`
+	Position  *int   `json:"position" binding:"omitempty,gte=0"`
+	IsPrimary bool   `json:"isPrimary"`
+}
+
+func (photoBody PhotoBody) ToPhotoDomain() domain.Photo {
+	return domain.Photo{
+		Path:      photoBody.Path,
+		IsPrimary: photoBody.IsPrimary,
+	}
+}
+
+// PhotoOrderBody lists every photo ID of the item in the new order, and optionally the new primary photo.
+type PhotoOrderBody struct {
+	PhotoIDs  []uint `json:"photoIds" binding:"required,min=1,max=20,dive,gt=0"`
+	PrimaryID uint   `json:"primaryId"`
+}
+
+// PhotoResponse.Variants are the URLs of the resized copies of an uploaded photo by name, e.g.
+// its thumbnail, which appear once they are generated, as does Hash, its perceptual hash in
+// hexadecimal. Check is the last check of the URL of a photo given by URL, null until it is checked.
+type PhotoResponse struct {
+	ID        uint                `json:"id"`
+	Path      string              `json:"path"`
+	Position  int                 `json:"position"`
+	IsPrimary bool                `json:"isPrimary"`
+	Variants  map[string]string   `json:"variants"`
+	Hash      string              `json:"hash,omitempty"`
+	Check     *PhotoCheckResponse `json:"check"`
+	CreatedAt time.Time           `json:"createdAt"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+type PhotosResponse struct {
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    []PhotoResponse `json:"data"`
+}
+
+func CreatePhotosResponse(photos []domain.Photo) []PhotoResponse {
+	response := make([]PhotoResponse, 0, len(photos))
+	for _, photo := range photos {
+		variants := photo.Variants
+		if variants == nil {
+			variants = map[string]string{}
+		}
+
+		var hash string
+		if photo.Hash != nil {
+			hash = fmt.Sprintf("%016x", *photo.Hash)
+		}
+
+		response = append(response, PhotoResponse{
+			ID:        photo.ID,
+			Path:      photo.Path,
+			Position:  photo.Position,
+			IsPrimary: photo.IsPrimary,
+			Variants:  variants,
+			Hash:      hash,
+			Check:     createPhotoCheckResponse(photo.Check),
+			CreatedAt: photo.CreatedAt,
+			UpdatedAt: photo.UpdatedAt,
+		})
+	}
+
+	return response
+}
+
+type PhotoMatchResponse struct {
+	PhotoID        uint `json:"photoId"`
+	MatchedPhotoID uint `json:"matchedPhotoId"`
+	Distance       int  `json:"distance"`
+}
+
+// DuplicateItemResponse.Matches pair the photos of the duplicate, PhotoID, with the photos of
+// the item they are similar to, MatchedPhotoID.
+type DuplicateItemResponse struct {
+	ItemID   uint                 `json:"itemId"`
+	Distance int                  `json:"distance"`
+	Matches  []PhotoMatchResponse `json:"matches"`
+}
+
+type DuplicatesResponse struct {
+	Status  int                     `json:"status"`
+	Message string                  `json:"message"`
+	Data    []DuplicateItemResponse `json:"data"`
+}
+
+func CreateDuplicatesResponse(duplicates []domain.DuplicateItem) []DuplicateItemResponse {
+	response := make([]DuplicateItemResponse, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		matches := make([]PhotoMatchResponse, 0, len(duplicate.Matches))
+		for _, match := range duplicate.Matches {
+			matches = append(matches, PhotoMatchResponse{
+				PhotoID:        match.PhotoID,
+				MatchedPhotoID: match.MatchedPhotoID,
+				Distance:       match.Distance,
+			})
+		}
+
+		response = append(response, DuplicateItemResponse{
+			ItemID:   duplicate.ItemID,
+			Distance: duplicate.Distance,
+			Matches:  matches,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,14 @@
+package dto
+
+// Problem is an RFC 7807 problem details body, extended with the catalogue code,
+// the request ID and, for request validation failures, every failing field.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
@@ -2,16 +2,18 @@ package dto
 
 import "github.com/osalomon89/test-crud-api/internal/core/domain"
 
+// ItemBody limits follow the items and photos columns: code is a varchar(191), and the
+// longtext columns are capped to sizes the API is willing to store.
 type ItemBody struct {
-	Code        string   `json:"code" binding:"required"`
-	Title       string   `json:"title" binding:"required"`
-	Description string   `json:"description" binding:"required"`
-	Price       int      `json:"price" binding:"required"`
-	Stock       int      `json:"stock" binding:"required"`
-	ItemType    string   `json:"itemType" binding:"required"`
+	Code        string   `json:"code" binding:"required,max=191"`
+	Title       string   `json:"title" binding:"required,max=255"`
+	Description string   `json:"description" binding:"required,max=65535"`
+	Price       int      `json:"price" binding:"required,gt=0"`
+	Stock       int      `json:"stock" binding:"gte=0"`
+	ItemType    string   `json:"itemType" binding:"required,itemtype"`
 	Leader      bool     `json:"leader"`
-	LeaderLevel string   `json:"leaderLevel"`
-	Photos      []string `json:"photos" binding:"required"`
+	LeaderLevel string   `json:"leaderLevel" binding:"omitempty,leaderlevel"`
+	Photos      []string `json:"photos" binding:"required,min=1,max=20,dive,required,max=2048,photourl,photohost"`
 }
 
 func (itemBody ItemBody) ToItemDomain() domain.Item {
@@ -33,3 +35,40 @@ func (itemBody ItemBody) ToItemDomain() domain.Item {
 		Photos:      photos,
 	}
 }
+
+// ItemPatchBody is the body of a partial update, absent fields are left unchanged.
+type ItemPatchBody struct {
+	Code        *string   `json:"code" binding:"omitempty,min=1,max=191"`
+	Title       *string   `json:"title" binding:"omitempty,min=1,max=255"`
+	Description *string   `json:"description" binding:"omitempty,min=1,max=65535"`
+	Price       *int      `json:"price" binding:"omitempty,gt=0"`
+	Stock       *int      `json:"stock" binding:"omitempty,gte=0"`
+	ItemType    *string   `json:"itemType" binding:"omitempty,itemtype"`
+	Leader      *bool     `json:"leader"`
+	LeaderLevel *string   `json:"leaderLevel" binding:"omitempty,leaderlevel"`
+	Photos      *[]string `json:"photos" binding:"omitempty,min=1,max=20,dive,required,max=2048,photourl,photohost"`
+}
+
+func (patchBody ItemPatchBody) ToItemPatch() domain.ItemPatch {
+	patch := domain.ItemPatch{
+		Code:        patchBody.Code,
+		Title:       patchBody.Title,
+		Description: patchBody.Description,
+		Price:       patchBody.Price,
+		Stock:       patchBody.Stock,
+		ItemType:    patchBody.ItemType,
+		Leader:      patchBody.Leader,
+		LeaderLevel: patchBody.LeaderLevel,
+	}
+
+	if patchBody.Photos != nil {
+		photos := make([]domain.Photo, 0, len(*patchBody.Photos))
+		for _, path := range *patchBody.Photos {
+			photos = append(photos, domain.Photo{Path: path})
+		}
+
+		patch.Photos = &photos
+	}
+
+	return patch
+}
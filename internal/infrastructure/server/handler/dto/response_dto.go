@@ -13,27 +13,23 @@ type Response struct {
 }
 
 type ItemResponse struct {
-	ID          uint     `json:"id"`
-	Code        string   `json:"code"`
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Price       int      `json:"price"`
-	Stock       int      `json:"stock"`
-	ItemType    string   `json:"itemType"`
-	Leader      bool     `json:"leader"`
-	LeaderLevel string   `json:"leaderLevel"`
-	Status      string   `json:"status"`
-	Photos      []string `json:"photos"`
+	ID          uint            `json:"id"`
+	Code        string          `json:"code"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Price       int             `json:"price"`
+	Stock       int             `json:"stock"`
+	ItemType    string          `json:"itemType"`
+	Leader      bool            `json:"leader"`
+	LeaderLevel string          `json:"leaderLevel"`
+	Status      string          `json:"status"`
+	Version     uint            `json:"version"`
+	Photos      []PhotoResponse `json:"photos"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
 func CreateItemResponse(item *domain.Item) *ItemResponse {
-	var photos []string
-	for _, photo := range item.Photos {
-		photos = append(photos, photo.Path)
-	}
-
 	return &ItemResponse{
 		ID:          item.ID,
 		Code:        item.Code,
@@ -45,12 +41,73 @@ func CreateItemResponse(item *domain.Item) *ItemResponse {
 		Leader:      item.Leader,
 		LeaderLevel: item.LeaderLevel,
 		Status:      item.Status,
-		Photos:      photos,
+		Version:     item.Version,
+		Photos:      CreatePhotosResponse(item.Photos),
 		CreatedAt:   item.CreatedAt,
 		UpdatedAt:   item.UpdatedAt,
 	}
 }
 
+type RevisionResponse struct {
+	ItemID    uint          `json:"itemId"`
+	Version   uint          `json:"version"`
+	Operation string        `json:"operation"`
+	Item      *ItemResponse `json:"item"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+type RevisionsResponse struct {
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+	Data    []RevisionResponse `json:"data"`
+}
+
+func CreateRevisionsResponse(revisions []domain.ItemRevision) []RevisionResponse {
+	response := make([]RevisionResponse, 0, len(revisions))
+	for i := range revisions {
+		response = append(response, RevisionResponse{
+			ItemID:    revisions[i].ItemID,
+			Version:   revisions[i].Version,
+			Operation: revisions[i].Operation,
+			Item:      CreateItemResponse(&revisions[i].Item),
+			CreatedAt: revisions[i].CreatedAt,
+		})
+	}
+
+	return response
+}
+
+type FieldChangeResponse struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type DiffResponse struct {
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    []FieldChangeResponse `json:"data"`
+}
+
+func CreateDiffResponse(changes []domain.FieldChange) []FieldChangeResponse {
+	response := make([]FieldChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		response = append(response, FieldChangeResponse{
+			Field: change.Field,
+			From:  change.From,
+			To:    change.To,
+		})
+	}
+
+	return response
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
 type ErrorResponse struct {
 	Message string `json:"message"`
 }
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+type SearchHitResponse struct {
+	Item       *ItemResponse     `json:"item"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type FacetCountResponse struct {
+	Value    string `json:"value"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+type SearchResultResponse struct {
+	Query   string                          `json:"query"`
+	Total   int                             `json:"total"`
+	Offset  int                             `json:"offset"`
+	Limit   int                             `json:"limit"`
+	Results []SearchHitResponse             `json:"results"`
+	Facets  map[string][]FacetCountResponse `json:"facets"`
+}
+
+type SearchResponse struct {
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    *SearchResultResponse `json:"data"`
+}
+
+func CreateSearchResultResponse(query domain.SearchQuery, result *domain.SearchResult) *SearchResultResponse {
+	response := &SearchResultResponse{
+		Query:   query.Text,
+		Total:   result.Total,
+		Offset:  query.Offset,
+		Limit:   query.Limit,
+		Results: make([]SearchHitResponse, 0, len(result.Hits)),
+		Facets:  make(map[string][]FacetCountResponse, len(result.Facets)),
+	}
+
+	for facet, counts := range result.Facets {
+		response.Facets[facet] = make([]FacetCountResponse, 0, len(counts))
+		for _, count := range counts {
+			response.Facets[facet] = append(response.Facets[facet], FacetCountResponse{
+				Value:    count.Value,
+				Count:    count.Count,
+				Selected: count.Selected,
+			})
+		}
+	}
+
+	for _, hit := range result.Hits {
+		response.Results = append(response.Results, SearchHitResponse{
+			Item:       CreateItemResponse(hit.Item),
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+type SuggestionResponse struct {
+	Text   string  `json:"text"`
+	Kind   string  `json:"kind"`
+	ItemID uint    `json:"itemId"`
+	Score  float64 `json:"score"`
+	Fuzzy  bool    `json:"fuzzy"`
+}
+
+type SuggestResponse struct {
+	Status  int                  `json:"status"`
+	Message string               `json:"message"`
+	Data    []SuggestionResponse `json:"data"`
+}
+
+func CreateSuggestionsResponse(suggestions []domain.Suggestion) []SuggestionResponse {
+	response := make([]SuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		response = append(response, SuggestionResponse{
+			Text:   suggestion.Text,
+			Kind:   suggestion.Kind,
+			ItemID: suggestion.ItemID,
+			Score:  suggestion.Score,
+			Fuzzy:  suggestion.Fuzzy,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+var (
+	errPreconditionRequired = domain.Error{
+		Code:    domain.ErrCodePreconditionRequired,
+		Message: "The If-Match header is required",
+	}
+	errPreconditionFailed = domain.Error{
+		Code:    domain.ErrCodePreconditionFailed,
+		Message: "The If-Match header does not match a strong item ETag",
+	}
+)
+
+func formatETag(version uint) string {
+	return fmt.Sprintf("%q", strconv.FormatUint(uint64(version), 10))
+}
+
+// ifMatchVersion returns the item version expected by the If-Match header, or 0 when
+// any version is accepted, either because of "*" or because the header is optional and missing.
+func ifMatchVersion(req *http.Request, required bool) (uint, error) {
+	ifMatch := strings.TrimSpace(req.Header.Get("If-Match"))
+	if ifMatch == "" {
+		if required {
+			return 0, errPreconditionRequired
+		}
+
+		return 0, nil
+	}
+
+	if ifMatch == "*" {
+		return 0, nil
+	}
+
+	// If-Match uses the strong comparison, so weak ETags never match.
+	if !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) || len(ifMatch) < 2 {
+		return 0, errPreconditionFailed
+	}
+
+	version, err := strconv.ParseUint(ifMatch[1:len(ifMatch)-1], 10, 64)
+	if err != nil || version == 0 {
+		return 0, errPreconditionFailed
+	}
+
+	return uint(version), nil
+}
+
+// ifNoneMatch reports whether the If-None-Match header matches the ETag, using the weak comparison.
+func ifNoneMatch(req *http.Request, etag string) bool {
+	ifNoneMatch := req.Header.Get("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/importer"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// importContentTypes are the media types that select the import format when the format query param is absent.
+var importContentTypes = map[string]string{
+	"text/csv":                domain.ImportFormatCSV,
+	"application/x-ndjson":    domain.ImportFormatJSONL,
+	"application/jsonl":       domain.ImportFormatJSONL,
+	"application/x-jsonlines": domain.ImportFormatJSONL,
+}
+
+type ImportHandler interface {
+	CreateImport(res http.ResponseWriter, req *http.Request) error
+	GetImport(res http.ResponseWriter, req *http.Request) error
+	GetImportErrors(res http.ResponseWriter, req *http.Request) error
+}
+
+type importHandler struct {
+	importService ports.ImportService
+	config        ImportHandlerConfig
+	validator     *validation.Validator
+}
+
+func NewImportHandler(importService ports.ImportService, config ImportHandlerConfig) (ImportHandler, error) {
+	if importService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	validator, err := validation.New(validation.LoadConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &importHandler{
+		importService: importService,
+		config:        config,
+		validator:     validator,
+	}, nil
+}
+
+// CreateImport parses the whole file before answering, so a file that can not be read is
+// rejected right away, and processes the rows as a job.
+func (h *importHandler) CreateImport(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ImportHandler. CreateImport()")
+
+	format, err := importFormat(req)
+	if err != nil {
+		logger.Error(h, nil, err, "error validating import format")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	var dryRun bool
+	if value := req.URL.Query().Get("dryRun"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			logger.Error(h, nil, err, "error validating dryRun query param")
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+				"The dryRun query param must be true or false")
+		}
+	}
+
+	body := http.MaxBytesReader(res, req.Body, h.config.MaxBytes)
+	rows, err := importer.ReadRows(format, body, h.validator, requestLanguage(req))
+	if err != nil {
+		logger.Error(h, nil, err, "error reading import file")
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+				fmt.Sprintf("The import file must be at most %d bytes", h.config.MaxBytes))
+		}
+
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	itemImport, err := h.importService.StartImport(ctx, domain.Import{Format: format, DryRun: dryRun}, rows)
+	if err != nil {
+		logger.Error(h, nil, err, "error starting import")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("/v1/imports/%d", itemImport.ID))
+
+	return web.EncodeJSON(res, dto.ImportResponse{
+		Status:  http.StatusAccepted,
+		Message: "Success",
+		Data:    dto.CreateImportResponse(itemImport),
+	}, http.StatusAccepted)
+}
+
+func (h *importHandler) GetImport(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ImportHandler. GetImport()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid import ID")
+	}
+
+	itemImport, err := h.importService.GetImport(ctx, id)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting import")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.ImportResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateImportResponse(itemImport),
+	}, http.StatusOK)
+}
+
+// GetImportErrors downloads the error report of an import as CSV, in the language of the request.
+func (h *importHandler) GetImportErrors(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ImportHandler. GetImportErrors()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid import ID")
+	}
+
+	importErrors, err := h.importService.GetImportErrors(ctx, id)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting import errors")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	res.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-errors.csv"`, id))
+	res.WriteHeader(http.StatusOK)
+
+	return importer.WriteErrorReport(res, importErrors, requestLanguage(req))
+}
+
+// importFormat reads the format query param, falling back to the Content-Type of the body.
+func importFormat(req *http.Request) (string, error) {
+	if format := req.URL.Query().Get("format"); format != "" {
+		if format != domain.ImportFormatCSV && format != domain.ImportFormatJSONL {
+			return "", fmt.Errorf("the format query param must be %s or %s",
+				domain.ImportFormatCSV, domain.ImportFormatJSONL)
+		}
+
+		return format, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err == nil {
+		if format, ok := importContentTypes[mediaType]; ok {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("the import format must be given by the format query param or a text/csv " +
+		"or application/x-ndjson Content-Type")
+}
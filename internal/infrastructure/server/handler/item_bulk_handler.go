@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// CreateItems answers 201 when every item was created and 207 when a partial batch has failures.
+// A rejected atomic batch is a problem listing the failing items.
+func (h *itemHandler) CreateItems(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. CreateItems()")
+
+	var bulkBody dto.BulkItemsBody
+	if err := json.NewDecoder(req.Body).Decode(&bulkBody); err != nil {
+		logger.Error(h, nil, err, "error validating request body")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	language := requestLanguage(req)
+	if fieldErrors := h.validator.Struct(bulkBody, language); len(fieldErrors) > 0 {
+		return problem.WriteValidation(ctx, res, req, fieldErrors)
+	}
+
+	if len(bulkBody.Items) > h.config.MaxBulkItems {
+		return problem.Write(ctx, res, req, domain.Error{
+			Code:    domain.ErrCodeBulkTooLarge,
+			Message: fmt.Sprintf("A bulk creation accepts at most %d items", h.config.MaxBulkItems),
+			Args:    []interface{}{h.config.MaxBulkItems},
+		})
+	}
+
+	mode := bulkBody.Mode
+	if mode == "" {
+		mode = domain.BulkModeAtomic
+	}
+
+	results := make([]dto.BulkItemResultResponse, len(bulkBody.Items))
+	items := make([]domain.Item, 0, len(bulkBody.Items))
+	indexes := make([]int, 0, len(bulkBody.Items))
+	var invalidFields []dto.FieldError
+
+	for i, itemBody := range bulkBody.Items {
+		results[i] = dto.BulkItemResultResponse{Index: i, Status: domain.BulkStatusSkipped}
+
+		fieldErrors := h.validator.Struct(itemBody, language)
+		if len(fieldErrors) == 0 {
+			items = append(items, itemBody.ToItemDomain())
+			indexes = append(indexes, i)
+			continue
+		}
+
+		itemProblem := problem.Resolve(req, domain.Error{Code: domain.ErrCodeRequestValidationFailed})
+		itemProblem.Errors = fieldErrors
+		results[i].Status = domain.BulkStatusFailed
+		results[i].Error = &itemProblem
+
+		for _, fieldError := range fieldErrors {
+			fieldError.Field = fmt.Sprintf("items[%d].%s", i, fieldError.Field)
+			invalidFields = append(invalidFields, fieldError)
+		}
+	}
+
+	if mode == domain.BulkModeAtomic && len(invalidFields) > 0 {
+		return problem.WriteValidation(ctx, res, req, invalidFields)
+	}
+
+	itemResults, err := h.itemService.CreateItems(ctx, items, mode)
+	if err != nil && itemResults == nil {
+		logger.Error(h, nil, err, "error creating items")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	for _, itemResult := range itemResults {
+		result := &results[indexes[itemResult.Index]]
+		result.Status = itemResult.Status
+
+		if itemResult.Item != nil {
+			result.Item = dto.CreateItemResponse(itemResult.Item)
+		}
+
+		if itemResult.Err != nil {
+			itemProblem := problem.Resolve(req, itemResult.Err)
+			result.Error = &itemProblem
+		}
+	}
+
+	if err != nil {
+		logger.Error(h, nil, err, "error creating items")
+		return problem.WriteWithErrors(ctx, res, req, err, bulkFieldErrors(results))
+	}
+
+	status := http.StatusCreated
+	response := dto.CreateBulkResultResponse(mode, results)
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	return web.EncodeJSON(res, dto.BulkResponse{
+		Status:  status,
+		Message: "Success",
+		Data:    response,
+	}, status)
+}
+
+// bulkFieldErrors lists the failing items of a rejected batch, one entry for each item.
+func bulkFieldErrors(results []dto.BulkItemResultResponse) []dto.FieldError {
+	var fieldErrors []dto.FieldError
+	for _, result := range results {
+		if result.Error != nil {
+			fieldErrors = append(fieldErrors, dto.FieldError{
+				Field:   fmt.Sprintf("items[%d]", result.Index),
+				Rule:    result.Error.Code,
+				Message: result.Error.Detail,
+			})
+		}
+	}
+
+	return fieldErrors
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/exporter"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+var exportContentTypes = map[string]string{
+	domain.ExportFormatCSV:   "text/csv; charset=utf-8",
+	domain.ExportFormatJSONL: "application/x-ndjson",
+}
+
+// ExportItems streams the items matching the filter as they are read, compressed with gzip
+// when the client accepts it. Once the first bytes are sent a failure can only be logged,
+// and the client sees a truncated body.
+func (h *itemHandler) ExportItems(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. ExportItems()")
+
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = domain.ExportFormatCSV
+	}
+
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+			fmt.Sprintf("The format query param must be %s or %s", domain.ExportFormatCSV, domain.ExportFormatJSONL))
+	}
+
+	filter, err := parseItemFilter(req.URL.Query())
+	if err != nil {
+		logger.Error(h, nil, err, "error validating query params")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	res.Header().Set("Content-Type", contentType)
+	res.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="items.%s"`, format))
+	res.Header().Add("Vary", "Accept-Encoding")
+
+	var body io.Writer = res
+	if acceptsGzip(req) {
+		res.Header().Set("Content-Encoding", "gzip")
+
+		gzipWriter := gzip.NewWriter(res)
+		defer gzipWriter.Close()
+
+		body = gzipWriter
+	}
+
+	writer, err := exporter.NewItemWriter(format, body)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	err = h.itemService.ExportItems(ctx, filter, writer.Write)
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if err != nil {
+		logger.Error(h, nil, err, "error exporting items")
+	}
+
+	return nil
+}
+
+// parseItemFilter reads the item filters of the query params.
+func parseItemFilter(query url.Values) (domain.ItemFilter, error) {
+	filter := domain.ItemFilter{
+		ItemType: query.Get("itemType"),
+		Status:   query.Get("status"),
+	}
+
+	if filter.ItemType != "" && filter.ItemType != domain.ItemTypeOwn && filter.ItemType != domain.ItemTypeSeller {
+		return filter, fmt.Errorf("itemType must be one of %s, %s", domain.ItemTypeOwn, domain.ItemTypeSeller)
+	}
+
+	if filter.Status != "" && filter.Status != domain.StatusActive && filter.Status != domain.StatusInactive {
+		return filter, fmt.Errorf("status must be one of %s, %s", domain.StatusActive, domain.StatusInactive)
+	}
+
+	if value := query.Get("leader"); value != "" {
+		leader, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("leader must be true or false")
+		}
+
+		filter.Leader = &leader
+	}
+
+	filter.LeaderLevel = query.Get("leaderLevel")
+	if filter.LeaderLevel != "" && !isLeaderLevel(filter.LeaderLevel) {
+		return filter, fmt.Errorf("leaderLevel must be one of %s, %s, %s", domain.LeaderLevelBasic,
+			domain.LeaderLevelGold, domain.LeaderLevelPlatinum)
+	}
+
+	if err := parsePriceFilter(query, &filter); err != nil {
+		return filter, err
+	}
+
+	for name, target := range map[string]*time.Time{"updatedFrom": &filter.UpdatedFrom, "updatedTo": &filter.UpdatedTo} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+			}
+
+			*target = parsed
+		}
+	}
+
+	return filter, nil
+}
+
+// parsePriceFilter reads either the price param, holding the key of a bucket of the price
+// facet such as "1000-5000" or "*-1000", or the priceMin and priceMax params.
+func parsePriceFilter(query url.Values, filter *domain.ItemFilter) error {
+	if value := query.Get("price"); value != "" {
+		bounds := strings.Split(value, "-")
+		if len(bounds) != 2 {
+			return fmt.Errorf("price must be a range such as 1000-5000, *-1000 or 5000-*")
+		}
+
+		for i, target := range []**int{&filter.PriceMin, &filter.PriceMax} {
+			if bounds[i] == "*" {
+				continue
+			}
+
+			bound, err := strconv.Atoi(bounds[i])
+			if err != nil || bound < 0 {
+				return fmt.Errorf("price must be a range such as 1000-5000, *-1000 or 5000-*")
+			}
+
+			*target = &bound
+		}
+
+		return nil
+	}
+
+	for name, target := range map[string]**int{"priceMin": &filter.PriceMin, "priceMax": &filter.PriceMax} {
+		if value := query.Get(name); value != "" {
+			bound, err := strconv.Atoi(value)
+			if err != nil || bound < 0 {
+				return fmt.Errorf("%s must be a non-negative integer", name)
+			}
+
+			*target = &bound
+		}
+	}
+
+	return nil
+}
+
+func isLeaderLevel(level string) bool {
+	return level == domain.LeaderLevelBasic || level == domain.LeaderLevelGold || level == domain.LeaderLevelPlatinum
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of the request allows gzip.
+func acceptsGzip(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		fields := strings.Split(strings.TrimSpace(encoding), ";")
+		if !strings.EqualFold(strings.TrimSpace(fields[0]), "gzip") {
+			continue
+		}
+
+		for _, param := range fields[1:] {
+			if q := strings.TrimSpace(param); q == "q=0" || q == "q=0.0" || q == "q=0.00" || q == "q=0.000" {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
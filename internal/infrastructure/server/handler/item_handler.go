@@ -2,34 +2,54 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/mercadolibre/fury_go-core/pkg/web"
 	"github.com/osalomon89/test-crud-api/internal/core/domain"
 	"github.com/osalomon89/test-crud-api/internal/core/ports"
 	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/i18n"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
 	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
 )
 
 type ItemHandler interface {
 	CreateItem(res http.ResponseWriter, req *http.Request) error
+	CreateItems(res http.ResponseWriter, req *http.Request) error
+	ExportItems(res http.ResponseWriter, req *http.Request) error
 	GetItemByID(res http.ResponseWriter, req *http.Request) error
+	UpdateItem(res http.ResponseWriter, req *http.Request) error
+	PatchItem(res http.ResponseWriter, req *http.Request) error
+	DeleteItem(res http.ResponseWriter, req *http.Request) error
+	GetItemRevisions(res http.ResponseWriter, req *http.Request) error
+	DiffItemRevisions(res http.ResponseWriter, req *http.Request) error
+	RevertItem(res http.ResponseWriter, req *http.Request) error
 }
 
 type itemHandler struct {
 	itemService ports.ItemService
+	config      ItemHandlerConfig
+	validator   *validation.Validator
 }
 
-func NewItemHandler(itemService ports.ItemService) (ItemHandler, error) {
+func NewItemHandler(itemService ports.ItemService, config ItemHandlerConfig) (ItemHandler, error) {
 	if itemService == nil {
 		return nil, fmt.Errorf("service cannot be nil")
 	}
 
+	validator, err := validation.New(validation.LoadConfig())
+	if err != nil {
+		return nil, err
+	}
+
 	return &itemHandler{
 		itemService: itemService,
+		config:      config,
+		validator:   validator,
 	}, nil
 }
 
@@ -41,40 +61,21 @@ func (h *itemHandler) CreateItem(res http.ResponseWriter, req *http.Request) err
 	var itemBody dto.ItemBody
 	if err := json.NewDecoder(req.Body).Decode(&itemBody); err != nil {
 		logger.Error(h, nil, err, "error validating request body")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
 
-		message := dto.Response{
-			Status:  http.StatusBadRequest,
-			Message: err.Error(),
-			Data:    nil,
-		}
-
-		return web.EncodeJSON(res, message, http.StatusBadRequest)
+	if fieldErrors := h.validator.Struct(itemBody, requestLanguage(req)); len(fieldErrors) > 0 {
+		return problem.WriteValidation(ctx, res, req, fieldErrors)
 	}
 
 	item, err := h.itemService.CreateItem(ctx, itemBody.ToItemDomain())
 	if err != nil {
 		logger.Error(h, nil, err, "error creating item")
-		var errorMsg string
-		var httpStatus int
-
-		itemError := new(domain.ItemError)
-		if ok := errors.As(err, itemError); ok {
-			httpStatus = http.StatusBadRequest
-			errorMsg = itemError.Error()
-		} else {
-			httpStatus = http.StatusInternalServerError
-			errorMsg = err.Error()
-		}
-
-		message := dto.Response{
-			Status:  httpStatus,
-			Message: errorMsg,
-			Data:    nil,
-		}
-
-		return web.EncodeJSON(res, message, httpStatus)
+		return problem.Write(ctx, res, req, err)
 	}
 
+	res.Header().Set("ETag", formatETag(item.Version))
+
 	return web.EncodeJSON(res, dto.Response{
 		Status:  http.StatusCreated,
 		Message: "Success",
@@ -90,34 +91,34 @@ func (h *itemHandler) GetItemByID(res http.ResponseWriter, req *http.Request) er
 	id, err := strconv.ParseUint(web.Params(req)["id"], 10, 32)
 	if err != nil || id <= 0 {
 		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
 
-		return web.EncodeJSON(res, dto.Response{
-			Status:  http.StatusBadRequest,
-			Message: err.Error(),
-			Data:    nil,
-		}, http.StatusBadRequest)
+	var item *domain.Item
+	if asOf := req.URL.Query().Get("asOf"); asOf != "" {
+		at, parseErr := time.Parse(time.RFC3339, asOf)
+		if parseErr != nil {
+			logger.Error(h, nil, parseErr, "error validating asOf query param")
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+				"The asOf query param must be an RFC3339 timestamp")
+		}
+
+		item, err = h.itemService.GetItemAsOf(ctx, uint(id), at)
+	} else {
+		item, err = h.itemService.GetItemByID(ctx, uint(id))
 	}
 
-	item, err := h.itemService.GetItemByID(ctx, uint(id))
 	if err != nil {
 		logger.Error(h, nil, err, "error getting item by ID")
-		var errorMsg string
-		var httpStatus int
-
-		itemError := new(domain.ResourceNotFoundError)
-		if ok := errors.As(err, itemError); ok {
-			httpStatus = http.StatusNotFound
-			errorMsg = itemError.Error()
-		} else {
-			httpStatus = http.StatusInternalServerError
-			errorMsg = err.Error()
-		}
+		return problem.Write(ctx, res, req, err)
+	}
+
+	etag := formatETag(item.Version)
+	res.Header().Set("ETag", etag)
 
-		return web.EncodeJSON(res, dto.Response{
-			Status:  httpStatus,
-			Message: errorMsg,
-			Data:    nil,
-		}, httpStatus)
+	if ifNoneMatch(req, etag) {
+		res.WriteHeader(http.StatusNotModified)
+		return nil
 	}
 
 	return web.EncodeJSON(res, dto.Response{
@@ -126,3 +127,120 @@ func (h *itemHandler) GetItemByID(res http.ResponseWriter, req *http.Request) er
 		Data:    dto.CreateItemResponse(item),
 	}, http.StatusOK)
 }
+
+func (h *itemHandler) UpdateItem(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. UpdateItem()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	var itemBody dto.ItemBody
+	if err := json.NewDecoder(req.Body).Decode(&itemBody); err != nil {
+		logger.Error(h, nil, err, "error validating request body")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	if fieldErrors := h.validator.Struct(itemBody, requestLanguage(req)); len(fieldErrors) > 0 {
+		return problem.WriteValidation(ctx, res, req, fieldErrors)
+	}
+
+	itemModel := itemBody.ToItemDomain()
+	itemModel.ID = id
+	itemModel.Version = version
+
+	item, err := h.itemService.UpdateItem(ctx, itemModel)
+	if err != nil {
+		logger.Error(h, nil, err, "error updating item")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	res.Header().Set("ETag", formatETag(item.Version))
+
+	return web.EncodeJSON(res, dto.Response{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateItemResponse(item),
+	}, http.StatusOK)
+}
+
+func (h *itemHandler) PatchItem(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. PatchItem()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	var patchBody dto.ItemPatchBody
+	if err := json.NewDecoder(req.Body).Decode(&patchBody); err != nil {
+		logger.Error(h, nil, err, "error validating request body")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	if fieldErrors := h.validator.Struct(patchBody, requestLanguage(req)); len(fieldErrors) > 0 {
+		return problem.WriteValidation(ctx, res, req, fieldErrors)
+	}
+
+	item, err := h.itemService.PatchItem(ctx, id, version, patchBody.ToItemPatch())
+	if err != nil {
+		logger.Error(h, nil, err, "error patching item")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	res.Header().Set("ETag", formatETag(item.Version))
+
+	return web.EncodeJSON(res, dto.Response{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateItemResponse(item),
+	}, http.StatusOK)
+}
+
+func (h *itemHandler) DeleteItem(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. DeleteItem()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	if err := h.itemService.DeleteItem(ctx, id, version); err != nil {
+		logger.Error(h, nil, err, "error deleting item")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// requestLanguage returns the language negotiated with the Accept-Language header of the request.
+func requestLanguage(req *http.Request) string {
+	return i18n.Negotiate(req.Header.Get("Accept-Language"))
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+func (h *itemHandler) GetItemRevisions(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. GetItemRevisions()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	revisions, err := h.itemService.GetItemRevisions(ctx, id)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting item revisions")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.RevisionsResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateRevisionsResponse(revisions),
+	}, http.StatusOK)
+}
+
+func (h *itemHandler) DiffItemRevisions(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. DiffItemRevisions()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	from, fromErr := uintParam(req.URL.Query().Get("from"))
+	to, toErr := uintParam(req.URL.Query().Get("to"))
+	if fromErr != nil || toErr != nil {
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+			"The from and to query params must be revision versions")
+	}
+
+	changes, err := h.itemService.DiffItemRevisions(ctx, id, from, to)
+	if err != nil {
+		logger.Error(h, nil, err, "error comparing item revisions")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.DiffResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateDiffResponse(changes),
+	}, http.StatusOK)
+}
+
+func (h *itemHandler) RevertItem(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering ItemHandler. RevertItem()")
+
+	id, idErr := uintParam(web.Params(req)["id"])
+	revision, revisionErr := uintParam(web.Params(req)["version"])
+	if idErr != nil || revisionErr != nil {
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+			"Invalid item ID or revision version")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	item, err := h.itemService.RevertItem(ctx, id, revision, version)
+	if err != nil {
+		logger.Error(h, nil, err, "error reverting item")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	res.Header().Set("ETag", formatETag(item.Version))
+
+	return web.EncodeJSON(res, dto.Response{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateItemResponse(item),
+	}, http.StatusOK)
+}
+
+func uintParam(value string) (uint, error) {
+	param, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	if param == 0 {
+		return 0, fmt.Errorf("param must be greater than zero")
+	}
+
+	return uint(param), nil
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type JobHandler interface {
+	GetJob(res http.ResponseWriter, req *http.Request) error
+	CancelJob(res http.ResponseWriter, req *http.Request) error
+}
+
+type jobHandler struct {
+	jobService ports.JobService
+}
+
+func NewJobHandler(jobService ports.JobService) (JobHandler, error) {
+	if jobService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	return &jobHandler{
+		jobService: jobService,
+	}, nil
+}
+
+func (h *jobHandler) GetJob(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering JobHandler. GetJob()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid job ID")
+	}
+
+	job, err := h.jobService.GetJob(ctx, id)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting job")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.JobResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateJobResponse(job),
+	}, http.StatusOK)
+}
+
+// CancelJob answers 202 while a running job has not yet stopped, and 200 once it is cancelled.
+func (h *jobHandler) CancelJob(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering JobHandler. CancelJob()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid job ID")
+	}
+
+	job, err := h.jobService.CancelJob(ctx, id)
+	if err != nil {
+		logger.Error(h, nil, err, "error cancelling job")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	status := http.StatusOK
+	if job.Status != domain.JobStatusCancelled {
+		status = http.StatusAccepted
+	}
+
+	return web.EncodeJSON(res, dto.JobResponse{
+		Status:  status,
+		Message: "Success",
+		Data:    dto.CreateJobResponse(job),
+	}, status)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+// LogHandler reads and changes the level of the logs while running, e.g. to debug an issue
+// without a restart. The level belongs to the process: behind a load balancer, a change only
+// applies to the instance that served it, and every instance is changed by its own address.
+type LogHandler interface {
+	GetLogLevel(res http.ResponseWriter, req *http.Request) error
+	SetLogLevel(res http.ResponseWriter, req *http.Request) error
+}
+
+type logHandler struct{}
+
+func NewLogHandler() LogHandler {
+	return &logHandler{}
+}
+
+func (h *logHandler) GetLogLevel(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering LogHandler. GetLogLevel()")
+
+	return writeLogLevel(res)
+}
+
+// SetLogLevel changes the level of every logger of this process only, not of the other
+// instances. The change is lost on restart, when the level is read again from LOG_LEVEL.
+func (h *logHandler) SetLogLevel(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering LogHandler. SetLogLevel()")
+
+	var levelBody dto.LogLevelBody
+	if err := json.NewDecoder(req.Body).Decode(&levelBody); err != nil {
+		logger.Error(h, nil, err, "error validating request body")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	level, err := log.ParseLevel(levelBody.Level)
+	if err != nil {
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	previous := log.GetLevel()
+	log.SetLevel(level)
+
+	// Logged at warn so the change is recorded whatever the new level is.
+	logger.With(log.String("previous_level", previous.String()), log.String("level", level.String())).
+		Warn(h, nil, "log level changed")
+
+	return writeLogLevel(res)
+}
+
+func writeLogLevel(res http.ResponseWriter) error {
+	return web.EncodeJSON(res, dto.LogLevelResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    &dto.LogLevelPayload{Level: log.GetLevel().String()},
+	}, http.StatusOK)
+}
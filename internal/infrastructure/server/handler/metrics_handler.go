@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/metrics"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+// MetricsHandler serves the metrics in the Prometheus text exposition format.
+type MetricsHandler interface {
+	GetMetrics(res http.ResponseWriter, req *http.Request) error
+}
+
+type metricsHandler struct {
+	registry *metrics.Registry
+}
+
+func NewMetricsHandler(registry *metrics.Registry) (MetricsHandler, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("registry cannot be nil")
+	}
+
+	return &metricsHandler{
+		registry: registry,
+	}, nil
+}
+
+// GetMetrics leaves out the metrics that could not be collected, e.g. the counts of the
+// database while it is down, so the others are still scraped.
+func (h *metricsHandler) GetMetrics(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering MetricsHandler. GetMetrics()")
+
+	var body bytes.Buffer
+	if err := h.registry.Write(ctx, &body); err != nil {
+		logger.Error(h, nil, err, "error collecting metrics")
+	}
+
+	res.Header().Set("Content-Type", metrics.ContentType)
+	res.WriteHeader(http.StatusOK)
+	_, err := res.Write(body.Bytes())
+
+	return err
+}
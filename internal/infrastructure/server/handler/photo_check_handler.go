@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	defaultPhotoCheckLimit = 100
+	maxPhotoCheckLimit     = 500
+)
+
+type PhotoCheckHandler interface {
+	GetReport(res http.ResponseWriter, req *http.Request) error
+	StartCheck(res http.ResponseWriter, req *http.Request) error
+}
+
+type photoCheckHandler struct {
+	photoCheckService ports.PhotoCheckService
+}
+
+func NewPhotoCheckHandler(photoCheckService ports.PhotoCheckService) (PhotoCheckHandler, error) {
+	if photoCheckService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	return &photoCheckHandler{
+		photoCheckService: photoCheckService,
+	}, nil
+}
+
+// GetReport answers the counts of the photos given by URL and a page of the broken ones, or of
+// the ones with the status query param, followed with afterId.
+func (h *photoCheckHandler) GetReport(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoCheckHandler. GetReport()")
+
+	filter, err := parsePhotoCheckFilter(req.URL.Query())
+	if err != nil {
+		logger.Error(h, nil, err, "error validating query params")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	report, err := h.photoCheckService.GetReport(ctx, filter)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting photo check report")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.PhotoCheckReportResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreatePhotoCheckReportResponse(report),
+	}, http.StatusOK)
+}
+
+// StartCheck answers 202 with the check job, to be followed at its Location.
+func (h *photoCheckHandler) StartCheck(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoCheckHandler. StartCheck()")
+
+	job, err := h.photoCheckService.StartCheck(ctx)
+	if err != nil {
+		logger.Error(h, nil, err, "error starting photo check")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	res.Header().Set("Location", fmt.Sprintf("/v1/jobs/%d", job.ID))
+
+	return web.EncodeJSON(res, dto.JobResponse{
+		Status:  http.StatusAccepted,
+		Message: "Success",
+		Data:    dto.CreateJobResponse(job),
+	}, http.StatusAccepted)
+}
+
+func parsePhotoCheckFilter(query url.Values) (domain.PhotoCheckFilter, error) {
+	filter := domain.PhotoCheckFilter{
+		Status: domain.PhotoCheckBroken,
+		Limit:  defaultPhotoCheckLimit,
+	}
+
+	if status := query.Get("status"); status != "" {
+		if status != domain.PhotoCheckBroken && status != domain.PhotoCheckOK &&
+			status != domain.PhotoCheckUnreachable && status != domain.PhotoCheckUnchecked {
+			return filter, fmt.Errorf("status must be one of %s, %s, %s, %s", domain.PhotoCheckBroken,
+				domain.PhotoCheckOK, domain.PhotoCheckUnreachable, domain.PhotoCheckUnchecked)
+		}
+
+		filter.Status = status
+	}
+
+	if afterID := query.Get("afterId"); afterID != "" {
+		id, err := uintParam(afterID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid afterId: %s", afterID)
+		}
+
+		filter.AfterID = id
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > maxPhotoCheckLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxPhotoCheckLimit)
+		}
+
+		filter.Limit = value
+	}
+
+	return filter, nil
+}
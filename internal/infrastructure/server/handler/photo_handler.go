@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/validation"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	defaultDuplicatesLimit = 20
+	maxDuplicatesLimit     = 100
+	// multipartOverhead bounds the bytes of an upload that are not the photo.
+	multipartOverhead = 64 << 10
+	// multipartMemory is the part of an upload kept in memory, the rest going to a temporary file.
+	multipartMemory = 1 << 20
+)
+
+// PhotoHandler serves the photos of an item. The changes are new versions of the item, so
+// they honour If-Match and answer with the photos and the new ETag of the item.
+type PhotoHandler interface {
+	GetPhotos(res http.ResponseWriter, req *http.Request) error
+	AddPhoto(res http.ResponseWriter, req *http.Request) error
+	UploadPhoto(res http.ResponseWriter, req *http.Request) error
+	DeletePhoto(res http.ResponseWriter, req *http.Request) error
+	ReorderPhotos(res http.ResponseWriter, req *http.Request) error
+	FindDuplicates(res http.ResponseWriter, req *http.Request) error
+}
+
+type photoHandler struct {
+	photoService ports.PhotoService
+	config       PhotoHandlerConfig
+	validator    *validation.Validator
+}
+
+func NewPhotoHandler(photoService ports.PhotoService, config PhotoHandlerConfig) (PhotoHandler, error) {
+	if photoService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	validator, err := validation.New(validation.LoadConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &photoHandler{
+		photoService: photoService,
+		config:       config,
+		validator:    validator,
+	}, nil
+}
+
+func (h *photoHandler) GetPhotos(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoHandler. GetPhotos()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	photos, err := h.photoService.GetPhotos(ctx, id)
+	if err != nil {
+		logger.Error(h, nil, err, "error getting item photos")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.PhotosResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreatePhotosResponse(photos),
+	}, http.StatusOK)
+}
+
+func (h *photoHandler) AddPhoto(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoHandler. AddPhoto()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	var photoBody dto.PhotoBody
+	if err := json.NewDecoder(req.Body).Decode(&photoBody); err != nil {
+		logger.Error(h, nil, err, "error validating request body")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	if fieldErrors := h.validator.Struct(photoBody, requestLanguage(req)); len(fieldErrors) > 0 {
+		return problem.WriteValidation(ctx, res, req, fieldErrors)
+	}
+
+	item, err := h.photoService.AddPhoto(ctx, id, version, photoBody.ToPhotoDomain(), photoBody.Position)
+	if err != nil {
+		logger.Error(h, nil, err, "error adding item photo")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return h.writePhotos(res, item, http.StatusCreated)
+}
+
+// UploadPhoto reads a multipart form with the photo in the file field, and the optional
+// position and isPrimary fields of AddPhoto.
+func (h *photoHandler) UploadPhoto(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoHandler. UploadPhoto()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	errTooLarge := domain.Error{
+		Code:    domain.ErrCodePhotoTooLarge,
+		Message: fmt.Sprintf("The photo must be at most %d bytes", h.config.MaxUploadBytes),
+		Args:    []interface{}{h.config.MaxUploadBytes},
+	}
+
+	// The form fields and the multipart boundaries come on top of the photo.
+	req.Body = http.MaxBytesReader(res, req.Body, h.config.MaxUploadBytes+multipartOverhead)
+	if err := req.ParseMultipartForm(multipartMemory); err != nil {
+		logger.Error(h, nil, err, "error reading multipart form")
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return problem.Write(ctx, res, req, errTooLarge)
+		}
+
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+	defer req.MultipartForm.RemoveAll() //nolint:errcheck
+
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		logger.Error(h, nil, err, "error reading photo file")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "The file field is required")
+	}
+	defer file.Close()
+
+	if header.Size > h.config.MaxUploadBytes {
+		return problem.Write(ctx, res, req, errTooLarge)
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		logger.Error(h, nil, err, "error reading photo file")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	var photo domain.Photo
+	if value := req.FormValue("isPrimary"); value != "" {
+		if photo.IsPrimary, err = strconv.ParseBool(value); err != nil {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "isPrimary must be true or false")
+		}
+	}
+
+	var position *int
+	if value := req.FormValue("position"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+				"position must be a non negative integer")
+		}
+
+		position = &parsed
+	}
+
+	item, err := h.photoService.UploadPhoto(ctx, id, version, content, photo, position)
+	if err != nil {
+		logger.Error(h, nil, err, "error uploading item photo")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return h.writePhotos(res, item, http.StatusCreated)
+}
+
+func (h *photoHandler) DeletePhoto(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoHandler. DeletePhoto()")
+
+	id, idErr := uintParam(web.Params(req)["id"])
+	photoID, photoErr := uintParam(web.Params(req)["photoId"])
+	if idErr != nil || photoErr != nil {
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item or photo ID")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	item, err := h.photoService.DeletePhoto(ctx, id, version, photoID)
+	if err != nil {
+		logger.Error(h, nil, err, "error deleting item photo")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return h.writePhotos(res, item, http.StatusOK)
+}
+
+func (h *photoHandler) ReorderPhotos(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoHandler. ReorderPhotos()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	version, err := ifMatchVersion(req, h.config.RequireIfMatch)
+	if err != nil {
+		return problem.Write(ctx, res, req, err)
+	}
+
+	var orderBody dto.PhotoOrderBody
+	if err := json.NewDecoder(req.Body).Decode(&orderBody); err != nil {
+		logger.Error(h, nil, err, "error validating request body")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	if fieldErrors := h.validator.Struct(orderBody, requestLanguage(req)); len(fieldErrors) > 0 {
+		return problem.WriteValidation(ctx, res, req, fieldErrors)
+	}
+
+	item, err := h.photoService.ReorderPhotos(ctx, id, version, orderBody.PhotoIDs, orderBody.PrimaryID)
+	if err != nil {
+		logger.Error(h, nil, err, "error reordering item photos")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return h.writePhotos(res, item, http.StatusOK)
+}
+
+// FindDuplicates reads the largest distance from the distance query param, and the number of
+// items from limit.
+func (h *photoHandler) FindDuplicates(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering PhotoHandler. FindDuplicates()")
+
+	id, err := uintParam(web.Params(req)["id"])
+	if err != nil {
+		logger.Error(h, nil, err, "error validating request param")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Invalid item ID")
+	}
+
+	distance := domain.DefaultPhotoHashDistance
+	if value := req.URL.Query().Get("distance"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > domain.MaxPhotoHashDistance {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+				fmt.Sprintf("distance must be between 0 and %d", domain.MaxPhotoHashDistance))
+		}
+
+		distance = parsed
+	}
+
+	limit := defaultDuplicatesLimit
+	if value := req.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxDuplicatesLimit {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+				fmt.Sprintf("limit must be between 1 and %d", maxDuplicatesLimit))
+		}
+
+		limit = parsed
+	}
+
+	duplicates, err := h.photoService.FindDuplicates(ctx, id, distance, limit)
+	if err != nil {
+		logger.Error(h, nil, err, "error finding duplicate items")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.DuplicatesResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateDuplicatesResponse(duplicates),
+	}, http.StatusOK)
+}
+
+func (h *photoHandler) writePhotos(res http.ResponseWriter, item *domain.Item, status int) error {
+	res.Header().Set("ETag", formatETag(item.Version))
+
+	return web.EncodeJSON(res, dto.PhotosResponse{
+		Status:  status,
+		Message: "Success",
+		Data:    dto.CreatePhotosResponse(item.Photos),
+	}, status)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchHandler interface {
+	SearchItems(res http.ResponseWriter, req *http.Request) error
+}
+
+type searchHandler struct {
+	searchService ports.SearchService
+	config        SearchHandlerConfig
+}
+
+func NewSearchHandler(searchService ports.SearchService, config SearchHandlerConfig) (SearchHandler, error) {
+	if searchService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	return &searchHandler{
+		searchService: searchService,
+		config:        config,
+	}, nil
+}
+
+// SearchItems also lists the items matching the filters when the q query param is absent.
+// Facets are counted with the same params as the filters, including price with the key of a bucket.
+func (h *searchHandler) SearchItems(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering SearchHandler. SearchItems()")
+
+	query, err := parseSearchQuery(req.URL.Query())
+	if err != nil {
+		logger.Error(h, nil, err, "error validating query params")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	query.PriceRanges = h.config.PriceRanges
+	query.Filter, err = parseItemFilter(req.URL.Query())
+	if err != nil {
+		logger.Error(h, nil, err, "error validating query params")
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+	}
+
+	result, err := h.searchService.SearchItems(ctx, query)
+	if err != nil {
+		logger.Error(h, nil, err, "error searching items")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.SearchResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateSearchResultResponse(query, result),
+	}, http.StatusOK)
+}
+
+func parseSearchQuery(values url.Values) (domain.SearchQuery, error) {
+	query := domain.SearchQuery{
+		Text:  strings.TrimSpace(values.Get("q")),
+		Limit: defaultSearchLimit,
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return query, fmt.Errorf("offset must be a non-negative integer")
+		}
+
+		query.Offset = value
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > maxSearchLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+		}
+
+		query.Limit = value
+	}
+
+	return query, nil
+}
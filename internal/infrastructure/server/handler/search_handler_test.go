@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    domain.SearchQuery
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  domain.SearchQuery{Limit: defaultSearchLimit},
+		},
+		{
+			name:  "text trimmed",
+			query: "q=%20l%C3%A1mpara%20roja%20&offset=20&limit=100",
+			want:  domain.SearchQuery{Text: "lámpara roja", Offset: 20, Limit: 100},
+		},
+		{
+			name:    "negative offset",
+			query:   "q=lampara&offset=-1",
+			wantErr: true,
+		},
+		{
+			name:    "offset not a number",
+			query:   "offset=first",
+			wantErr: true,
+		},
+		{
+			name:    "zero limit",
+			query:   "limit=0",
+			wantErr: true,
+		},
+		{
+			name:    "limit above the maximum",
+			query:   "limit=101",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := url.ParseQuery(test.query)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+
+			got, err := parseSearchQuery(values)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseSearchQuery() error = %v, want error %v", err, test.wantErr)
+			}
+
+			if !test.wantErr && (got.Text != test.want.Text || got.Offset != test.want.Offset ||
+				got.Limit != test.want.Limit) {
+				t.Errorf("parseSearchQuery() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseItemFilter(t *testing.T) {
+	leader := true
+	price1000, price5000 := 1000, 5000
+
+	tests := []struct {
+		name    string
+		query   string
+		want    domain.ItemFilter
+		wantErr bool
+	}{
+		{
+			name:  "no filter",
+			query: "q=lampara",
+			want:  domain.ItemFilter{},
+		},
+		{
+			name:  "facets selected together",
+			query: "status=ACTIVE&itemType=SELLER&leader=true&leaderLevel=GOLD&price=1000-5000",
+			want: domain.ItemFilter{Status: domain.StatusActive, ItemType: domain.ItemTypeSeller, Leader: &leader,
+				LeaderLevel: domain.LeaderLevelGold, PriceMin: &price1000, PriceMax: &price5000},
+		},
+		{
+			name:  "open lower price bucket",
+			query: "price=*-1000",
+			want:  domain.ItemFilter{PriceMax: &price1000},
+		},
+		{
+			name:  "open upper price bucket",
+			query: "price=5000-*",
+			want:  domain.ItemFilter{PriceMin: &price5000},
+		},
+		{
+			name:  "price bounds",
+			query: "priceMin=1000&priceMax=5000",
+			want:  domain.ItemFilter{PriceMin: &price1000, PriceMax: &price5000},
+		},
+		{
+			name:  "price bucket over the price bounds",
+			query: "price=*-1000&priceMin=5000",
+			want:  domain.ItemFilter{PriceMax: &price1000},
+		},
+		{
+			name:    "price not a range",
+			query:   "price=1000",
+			wantErr: true,
+		},
+		{
+			name:    "negative price bound",
+			query:   "priceMin=-1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown status",
+			query:   "status=DELETED",
+			wantErr: true,
+		},
+		{
+			name:    "leader not a boolean",
+			query:   "leader=yes",
+			wantErr: true,
+		},
+		{
+			name:    "unknown leader level",
+			query:   "leaderLevel=DIAMOND",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := url.ParseQuery(test.query)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+
+			got, err := parseItemFilter(values)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseItemFilter() error = %v, want error %v", err, test.wantErr)
+			}
+
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseItemFilter() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadSearchHandlerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		buckets string
+		want    []string
+	}{
+		{
+			name: "default buckets",
+			want: []string{"*-1000", "1000-5000", "5000-20000", "20000-100000", "100000-*"},
+		},
+		{
+			name:    "configured buckets",
+			buckets: "500, 2500",
+			want:    []string{"*-500", "500-2500", "2500-*"},
+		},
+		{
+			name:    "buckets out of order",
+			buckets: "2500,500",
+			want:    []string{"*-1000", "1000-5000", "5000-20000", "20000-100000", "100000-*"},
+		},
+		{
+			name:    "bucket not a number",
+			buckets: "500,cheap",
+			want:    []string{"*-1000", "1000-5000", "5000-20000", "20000-100000", "100000-*"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(searchPriceBuckets, test.buckets)
+
+			var got []string
+			for _, priceRange := range LoadSearchHandlerConfig().PriceRanges {
+				got = append(got, priceRange.Key())
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("LoadSearchHandlerConfig() price ranges = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
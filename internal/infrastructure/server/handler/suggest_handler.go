@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+)
+
+type SuggestHandler interface {
+	SuggestItems(res http.ResponseWriter, req *http.Request) error
+}
+
+type suggestHandler struct {
+	suggestService ports.SuggestService
+}
+
+func NewSuggestHandler(suggestService ports.SuggestService) (SuggestHandler, error) {
+	if suggestService == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	return &suggestHandler{
+		suggestService: suggestService,
+	}, nil
+}
+
+func (h *suggestHandler) SuggestItems(res http.ResponseWriter, req *http.Request) error {
+	ctx := marketcontext.New(req)
+	logger := marketcontext.Logger(ctx)
+	logger.Debug(h, nil, "Entering SuggestHandler. SuggestItems()")
+
+	prefix := strings.TrimSpace(req.URL.Query().Get("prefix"))
+	if prefix == "" {
+		return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "The prefix query param is required")
+	}
+
+	limit := defaultSuggestLimit
+	if value := req.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxSuggestLimit {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest,
+				fmt.Sprintf("limit must be between 1 and %d", maxSuggestLimit))
+		}
+
+		limit = parsed
+	}
+
+	suggestions, err := h.suggestService.SuggestItems(ctx, prefix, limit)
+	if err != nil {
+		logger.Error(h, nil, err, "error suggesting items")
+		return problem.Write(ctx, res, req, err)
+	}
+
+	return web.EncodeJSON(res, dto.SuggestResponse{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    dto.CreateSuggestionsResponse(suggestions),
+	}, http.StatusOK)
+}
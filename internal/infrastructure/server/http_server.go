@@ -1,8 +1,13 @@
 package server
 
 import (
+	"net/http"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
 	"github.com/mercadolibre/fury_go-platform/pkg/fury"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
 	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/middleware"
 )
 
 type HTTPServer interface {
@@ -10,26 +15,176 @@ type HTTPServer interface {
 	Run() error
 }
 
+type Handlers struct {
+	ItemHandler       handler.ItemHandler
+	PhotoHandler      handler.PhotoHandler
+	PhotoCheckHandler handler.PhotoCheckHandler
+	AuditHandler      handler.AuditHandler
+	ImportHandler     handler.ImportHandler
+	JobHandler        handler.JobHandler
+	SearchHandler     handler.SearchHandler
+	SuggestHandler    handler.SuggestHandler
+	BlobHandler       handler.BlobHandler
+	LogHandler        handler.LogHandler
+}
+
+type Middlewares struct {
+	RateLimitStore   ports.RateLimitStore
+	RateLimits       middleware.RateLimitConfig
+	IdempotencyStore ports.IdempotencyStore
+	Idempotency      middleware.IdempotencyConfig
+	RouteMetrics     *middleware.RouteMetrics
+	AccessLog        *middleware.AccessLog
+	Auth             middleware.AuthConfig
+}
+
 type httpServer struct {
-	ItemHandler handler.ItemHandler
+	Handlers    Handlers
+	Middlewares Middlewares
 	App         *fury.Application
 }
 
-func NewHTTPServer(app *fury.Application, handler handler.ItemHandler) HTTPServer {
+func NewHTTPServer(app *fury.Application, handlers Handlers, middlewares Middlewares) HTTPServer {
 	return &httpServer{
-		ItemHandler: handler,
+		Handlers:    handlers,
+		Middlewares: middlewares,
 		App:         app,
 	}
 }
 
 func (handler *httpServer) SetupRouter() {
-	api := handler.App.Router.Group("/v1/items")
+	mw := handler.Middlewares
+	itemsRead := middleware.RateLimit(mw.RateLimitStore, "items-read", mw.RateLimits.For("items-read"))
+	itemsWrite := middleware.RateLimit(mw.RateLimitStore, "items-write", mw.RateLimits.For("items-write"))
+	auditRead := middleware.RateLimit(mw.RateLimitStore, "audit", mw.RateLimits.For("audit"))
+	jobsRead := middleware.RateLimit(mw.RateLimitStore, "jobs-read", mw.RateLimits.For("jobs-read"))
+	jobsWrite := middleware.RateLimit(mw.RateLimitStore, "jobs-write", mw.RateLimits.For("jobs-write"))
+	adminRate := middleware.RateLimit(mw.RateLimitStore, "admin", mw.RateLimits.For("admin"))
+	idempotent := middleware.Idempotency(mw.IdempotencyStore, mw.Idempotency)
+
+	itemHandler := handler.Handlers.ItemHandler
+	photoHandler := handler.Handlers.PhotoHandler
+
+	api := handler.group("/v1/items")
+	{
+		api.Get("/export", itemsRead(itemHandler.ExportItems))
+		api.Get("/search", itemsRead(handler.Handlers.SearchHandler.SearchItems))
+		api.Get("/suggest", itemsRead(handler.Handlers.SuggestHandler.SuggestItems))
+		api.Get("/{id}", itemsRead(itemHandler.GetItemByID))
+		api.Post("/", itemsWrite(idempotent(itemHandler.CreateItem)))
+		api.Post("/bulk", itemsWrite(idempotent(itemHandler.CreateItems)))
+		api.Put("/{id}", itemsWrite(itemHandler.UpdateItem))
+		api.Patch("/{id}", itemsWrite(itemHandler.PatchItem))
+		api.Delete("/{id}", itemsWrite(itemHandler.DeleteItem))
+		api.Get("/{id}/revisions", itemsRead(itemHandler.GetItemRevisions))
+		api.Get("/{id}/revisions/diff", itemsRead(itemHandler.DiffItemRevisions))
+		api.Post("/{id}/revisions/{version}/revert", itemsWrite(itemHandler.RevertItem))
+		api.Get("/{id}/photos", itemsRead(photoHandler.GetPhotos))
+		api.Post("/{id}/photos", itemsWrite(photoHandler.AddPhoto))
+		api.Post("/{id}/photos/upload", itemsWrite(photoHandler.UploadPhoto))
+		api.Put("/{id}/photos/order", itemsWrite(photoHandler.ReorderPhotos))
+		api.Delete("/{id}/photos/{photoId}", itemsWrite(photoHandler.DeletePhoto))
+		api.Get("/{id}/duplicates", itemsRead(photoHandler.FindDuplicates))
+	}
+
+	blobs := handler.group("/v1/blobs")
+	{
+		blobs.Get("/{key}", itemsRead(handler.Handlers.BlobHandler.GetBlob))
+	}
+
+	// There is no photo check handler when the photo hosts are not configured.
+	if photoCheckHandler := handler.Handlers.PhotoCheckHandler; photoCheckHandler != nil {
+		photoChecks := handler.group("/v1/photos/checks")
+		{
+			photoChecks.Get("/", itemsRead(photoCheckHandler.GetReport))
+			photoChecks.Post("/", itemsWrite(photoCheckHandler.StartCheck))
+		}
+	}
+
+	auditHandler := handler.Handlers.AuditHandler
+
+	audit := handler.group("/v1/audit")
+	{
+		audit.Get("/", auditRead(middleware.RequireAdmin(auditHandler.GetAuditEntries)))
+		audit.Get("/verify", auditRead(middleware.RequireAdmin(auditHandler.VerifyAuditChain)))
+	}
+
+	importHandler := handler.Handlers.ImportHandler
+
+	imports := handler.group("/v1/imports")
+	{
+		imports.Post("/", itemsWrite(idempotent(importHandler.CreateImport)))
+		imports.Get("/{id}", itemsRead(importHandler.GetImport))
+		imports.Get("/{id}/errors", itemsRead(importHandler.GetImportErrors))
+	}
+
+	jobHandler := handler.Handlers.JobHandler
+
+	jobs := handler.group("/v1/jobs")
+	{
+		jobs.Get("/{id}", jobsRead(jobHandler.GetJob))
+		jobs.Post("/{id}/cancel", jobsWrite(jobHandler.CancelJob))
+	}
+
+	logHandler := handler.Handlers.LogHandler
+
+	admin := handler.group("/v1/admin")
 	{
-		api.Get("/{id}", handler.ItemHandler.GetItemByID)
-		api.Post("/", handler.ItemHandler.CreateItem)
+		admin.Get("/log-level", adminRate(middleware.RequireAdmin(logHandler.GetLogLevel)))
+		admin.Put("/log-level", adminRate(middleware.RequireAdmin(logHandler.SetLogLevel)))
 	}
 }
 
 func (handler *httpServer) Run() error {
 	return handler.App.Run()
 }
+
+// routeGroup registers the routes of a group traced, measured and logged by their pattern, with
+// their caller authenticated.
+type routeGroup struct {
+	group        *web.RouterGroup
+	prefix       string
+	metrics      *middleware.RouteMetrics
+	accessLog    *middleware.AccessLog
+	authenticate web.Middleware
+}
+
+func (handler *httpServer) group(prefix string) *routeGroup {
+	return &routeGroup{
+		group:        handler.App.Router.Group(prefix),
+		prefix:       prefix,
+		metrics:      handler.Middlewares.RouteMetrics,
+		accessLog:    handler.Middlewares.AccessLog,
+		authenticate: middleware.Authenticate(handler.Middlewares.Auth),
+	}
+}
+
+// handle wraps h with the metrics, the span and the access log of the route, then authenticates
+// the caller before the rate limits of h are applied. The access log goes inside the span, so the
+// request ID it gives is the trace ID when the request has none.
+func (g *routeGroup) handle(method, path string, h web.Handler) web.Handler {
+	route := g.prefix + path
+	h = g.authenticate(h)
+
+	return g.metrics.Route(method, route)(middleware.Trace(method, route)(g.accessLog.Route(method, route)(h)))
+}
+
+func (g *routeGroup) Get(path string, h web.Handler) {
+	g.group.Get(path, g.handle(http.MethodGet, path, h))
+}
+
+func (g *routeGroup) Post(path string, h web.Handler) {
+	g.group.Post(path, g.handle(http.MethodPost, path, h))
+}
+
+func (g *routeGroup) Put(path string, h web.Handler) {
+	g.group.Put(path, g.handle(http.MethodPut, path, h))
+}
+
+func (g *routeGroup) Patch(path string, h web.Handler) {
+	g.group.Patch(path, g.handle(http.MethodPatch, path, h))
+}
+
+func (g *routeGroup) Delete(path string, h web.Handler) {
+	g.group.Delete(path, g.handle(http.MethodDelete, path, h))
+}
@@ -0,0 +1,39 @@
+package i18n
+
+import "github.com/osalomon89/test-crud-api/internal/core/domain"
+
+var englishCatalogue = map[string]Message{
+	domain.ErrCodeItemValidationFailed:    {Title: "Item validation failed"},
+	domain.ErrCodeItemCodeDuplicated:      {Title: "Item code already exists", Detail: "The item code must be unique"},
+	domain.ErrCodeItemLeaderLevelInvalid:  {Title: "Invalid leader level", Detail: "Leader level is not valid: %s"},
+	domain.ErrCodeItemPhotosRequired:      {Title: "Photos required", Detail: "The item must have at least one photo"},
+	domain.ErrCodeItemPhotosLimit:         {Title: "Too many photos", Detail: "An item can have at most %d photos"},
+	domain.ErrCodeItemPhotoOrderInvalid:   {Title: "Invalid photo order", Detail: "The order must list every photo of the item exactly once"},
+	domain.ErrCodePhotoTypeUnsupported:    {Title: "Unsupported photo type", Detail: "The photo must be a JPEG, PNG, GIF or WebP image"},
+	domain.ErrCodePhotoTooLarge:           {Title: "Photo too large", Detail: "The photo must be at most %d bytes"},
+	domain.ErrCodePhotoTooManyPixels:      {Title: "Photo too large", Detail: "The photo has too many pixels to be processed"},
+	domain.ErrCodeRevisionNotRestorable:   {Title: "Revision not restorable", Detail: "A deletion revision can not be restored"},
+	domain.ErrCodeBulkRejected:            {Title: "Bulk creation rejected", Detail: "No item was created because some of them are not valid"},
+	domain.ErrCodeBulkTooLarge:            {Title: "Too many items", Detail: "A bulk creation accepts at most %d items"},
+	domain.ErrCodeJobFinished:             {Title: "Job already finished", Detail: "The job has already finished and can not be cancelled"},
+	domain.ErrCodeRequestValidationFailed: {Title: "Request validation failed", Detail: "The request body has invalid fields"},
+	domain.ErrCodeMalformedRequest:        {Title: "Malformed request"},
+	domain.ErrCodeResourceNotFound:        {Title: "Resource not found", Detail: "The requested resource does not exist"},
+	domain.ErrCodeVersionConflict:         {Title: "Version conflict", Detail: "The item has been modified by another request"},
+	domain.ErrCodePreconditionFailed:      {Title: "Precondition failed", Detail: "The If-Match header does not match a strong item ETag"},
+	domain.ErrCodePreconditionRequired:    {Title: "Precondition required", Detail: "The If-Match header is required"},
+	domain.ErrCodeIdempotencyKeyReused:    {Title: "Idempotency key reused", Detail: "The Idempotency-Key has already been used with a different request"},
+	domain.ErrCodeIdempotencyInProgress:   {Title: "Request in progress", Detail: "A request with the same Idempotency-Key is still being processed"},
+	domain.ErrCodeRateLimitExceeded:       {Title: "Rate limit exceeded", Detail: "Too many requests, retry after the time given by Retry-After"},
+	domain.ErrCodeUnauthorized:            {Title: "Unauthorized", Detail: "A valid X-Api-Key header is required"},
+	domain.ErrCodeForbidden:               {Title: "Forbidden", Detail: "The caller is not allowed to perform this operation"},
+	domain.ErrCodeInternal:                {Title: "Internal error", Detail: "An unexpected error occurred"},
+
+	"validation.itemtype":    {Detail: "{0} must be one of OWN, SELLER"},
+	"validation.leaderlevel": {Detail: "{0} must be one of BASIC, GOLD, PLATINUM"},
+	"validation.photourl":    {Detail: "{0} must be an absolute URL with one of the schemes {1}"},
+	"validation.photohost":   {Detail: "{0} must point at one of the hosts {1}"},
+
+	"import.integer": {Detail: "%s must be an integer"},
+	"import.boolean": {Detail: "%s must be true or false"},
+}
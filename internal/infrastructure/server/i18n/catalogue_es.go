@@ -0,0 +1,39 @@
+package i18n
+
+import "github.com/osalomon89/test-crud-api/internal/core/domain"
+
+var spanishCatalogue = map[string]Message{
+	domain.ErrCodeItemValidationFailed:    {Title: "Error de validación del ítem"},
+	domain.ErrCodeItemCodeDuplicated:      {Title: "El código del ítem ya existe", Detail: "El código del ítem debe ser único"},
+	domain.ErrCodeItemLeaderLevelInvalid:  {Title: "Nivel de líder inválido", Detail: "El nivel de líder no es válido: %s"},
+	domain.ErrCodeItemPhotosRequired:      {Title: "Fotos requeridas", Detail: "El ítem debe tener al menos una foto"},
+	domain.ErrCodeItemPhotosLimit:         {Title: "Demasiadas fotos", Detail: "Un ítem puede tener como máximo %d fotos"},
+	domain.ErrCodeItemPhotoOrderInvalid:   {Title: "Orden de fotos inválido", Detail: "El orden debe incluir cada foto del ítem exactamente una vez"},
+	domain.ErrCodePhotoTypeUnsupported:    {Title: "Tipo de foto no soportado", Detail: "La foto debe ser una imagen JPEG, PNG, GIF o WebP"},
+	domain.ErrCodePhotoTooLarge:           {Title: "Foto demasiado grande", Detail: "La foto debe tener como máximo %d bytes"},
+	domain.ErrCodePhotoTooManyPixels:      {Title: "Foto demasiado grande", Detail: "La foto tiene demasiados píxeles para ser procesada"},
+	domain.ErrCodeRevisionNotRestorable:   {Title: "Revisión no restaurable", Detail: "Una revisión de borrado no puede restaurarse"},
+	domain.ErrCodeBulkRejected:            {Title: "Creación masiva rechazada", Detail: "No se creó ningún ítem porque algunos no son válidos"},
+	domain.ErrCodeBulkTooLarge:            {Title: "Demasiados ítems", Detail: "Una creación masiva acepta como máximo %d ítems"},
+	domain.ErrCodeJobFinished:             {Title: "Trabajo ya finalizado", Detail: "El trabajo ya finalizó y no puede cancelarse"},
+	domain.ErrCodeRequestValidationFailed: {Title: "Error de validación de la solicitud", Detail: "El cuerpo de la solicitud tiene campos inválidos"},
+	domain.ErrCodeMalformedRequest:        {Title: "Solicitud mal formada"},
+	domain.ErrCodeResourceNotFound:        {Title: "Recurso no encontrado", Detail: "El recurso solicitado no existe"},
+	domain.ErrCodeVersionConflict:         {Title: "Conflicto de versión", Detail: "El ítem fue modificado por otra solicitud"},
+	domain.ErrCodePreconditionFailed:      {Title: "Precondición fallida", Detail: "El encabezado If-Match no coincide con un ETag fuerte del ítem"},
+	domain.ErrCodePreconditionRequired:    {Title: "Precondición requerida", Detail: "El encabezado If-Match es obligatorio"},
+	domain.ErrCodeIdempotencyKeyReused:    {Title: "Clave de idempotencia reutilizada", Detail: "La Idempotency-Key ya fue usada con una solicitud diferente"},
+	domain.ErrCodeIdempotencyInProgress:   {Title: "Solicitud en curso", Detail: "Una solicitud con la misma Idempotency-Key todavía se está procesando"},
+	domain.ErrCodeRateLimitExceeded:       {Title: "Límite de solicitudes excedido", Detail: "Demasiadas solicitudes, reintente luego del tiempo indicado en Retry-After"},
+	domain.ErrCodeUnauthorized:            {Title: "No autorizado", Detail: "Se requiere un encabezado X-Api-Key válido"},
+	domain.ErrCodeForbidden:               {Title: "Prohibido", Detail: "El llamador no tiene permitido realizar esta operación"},
+	domain.ErrCodeInternal:                {Title: "Error interno", Detail: "Ocurrió un error inesperado"},
+
+	"validation.itemtype":    {Detail: "{0} debe ser uno de OWN, SELLER"},
+	"validation.leaderlevel": {Detail: "{0} debe ser uno de BASIC, GOLD, PLATINUM"},
+	"validation.photourl":    {Detail: "{0} debe ser una URL absoluta con uno de los esquemas {1}"},
+	"validation.photohost":   {Detail: "{0} debe apuntar a uno de los hosts {1}"},
+
+	"import.integer": {Detail: "%s debe ser un número entero"},
+	"import.boolean": {Detail: "%s debe ser true o false"},
+}
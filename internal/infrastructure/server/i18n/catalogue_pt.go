@@ -0,0 +1,39 @@
+package i18n
+
+import "github.com/osalomon89/test-crud-api/internal/core/domain"
+
+var portugueseCatalogue = map[string]Message{
+	domain.ErrCodeItemValidationFailed:    {Title: "Erro de validação do item"},
+	domain.ErrCodeItemCodeDuplicated:      {Title: "O código do item já existe", Detail: "O código do item deve ser único"},
+	domain.ErrCodeItemLeaderLevelInvalid:  {Title: "Nível de líder inválido", Detail: "O nível de líder não é válido: %s"},
+	domain.ErrCodeItemPhotosRequired:      {Title: "Fotos obrigatórias", Detail: "O item deve ter pelo menos uma foto"},
+	domain.ErrCodeItemPhotosLimit:         {Title: "Fotos demais", Detail: "Um item pode ter no máximo %d fotos"},
+	domain.ErrCodeItemPhotoOrderInvalid:   {Title: "Ordem de fotos inválida", Detail: "A ordem deve incluir cada foto do item exatamente uma vez"},
+	domain.ErrCodePhotoTypeUnsupported:    {Title: "Tipo de foto não suportado", Detail: "A foto deve ser uma imagem JPEG, PNG, GIF ou WebP"},
+	domain.ErrCodePhotoTooLarge:           {Title: "Foto grande demais", Detail: "A foto deve ter no máximo %d bytes"},
+	domain.ErrCodePhotoTooManyPixels:      {Title: "Foto grande demais", Detail: "A foto tem pixels demais para ser processada"},
+	domain.ErrCodeRevisionNotRestorable:   {Title: "Revisão não restaurável", Detail: "Uma revisão de exclusão não pode ser restaurada"},
+	domain.ErrCodeBulkRejected:            {Title: "Criação em massa rejeitada", Detail: "Nenhum item foi criado porque alguns não são válidos"},
+	domain.ErrCodeBulkTooLarge:            {Title: "Itens demais", Detail: "Uma criação em massa aceita no máximo %d itens"},
+	domain.ErrCodeJobFinished:             {Title: "Tarefa já finalizada", Detail: "A tarefa já foi finalizada e não pode ser cancelada"},
+	domain.ErrCodeRequestValidationFailed: {Title: "Erro de validação da requisição", Detail: "O corpo da requisição tem campos inválidos"},
+	domain.ErrCodeMalformedRequest:        {Title: "Requisição malformada"},
+	domain.ErrCodeResourceNotFound:        {Title: "Recurso não encontrado", Detail: "O recurso solicitado não existe"},
+	domain.ErrCodeVersionConflict:         {Title: "Conflito de versão", Detail: "O item foi modificado por outra requisição"},
+	domain.ErrCodePreconditionFailed:      {Title: "Pré-condição falhou", Detail: "O cabeçalho If-Match não corresponde a um ETag forte do item"},
+	domain.ErrCodePreconditionRequired:    {Title: "Pré-condição obrigatória", Detail: "O cabeçalho If-Match é obrigatório"},
+	domain.ErrCodeIdempotencyKeyReused:    {Title: "Chave de idempotência reutilizada", Detail: "A Idempotency-Key já foi usada com uma requisição diferente"},
+	domain.ErrCodeIdempotencyInProgress:   {Title: "Requisição em andamento", Detail: "Uma requisição com a mesma Idempotency-Key ainda está sendo processada"},
+	domain.ErrCodeRateLimitExceeded:       {Title: "Limite de requisições excedido", Detail: "Requisições demais, tente novamente após o tempo indicado em Retry-After"},
+	domain.ErrCodeUnauthorized:            {Title: "Não autorizado", Detail: "É necessário um cabeçalho X-Api-Key válido"},
+	domain.ErrCodeForbidden:               {Title: "Proibido", Detail: "O chamador não tem permissão para realizar esta operação"},
+	domain.ErrCodeInternal:                {Title: "Erro interno", Detail: "Ocorreu um erro inesperado"},
+
+	"validation.itemtype":    {Detail: "{0} deve ser um de OWN, SELLER"},
+	"validation.leaderlevel": {Detail: "{0} deve ser um de BASIC, GOLD, PLATINUM"},
+	"validation.photourl":    {Detail: "{0} deve ser uma URL absoluta com um dos esquemas {1}"},
+	"validation.photohost":   {Detail: "{0} deve apontar para um dos hosts {1}"},
+
+	"import.integer": {Detail: "%s deve ser um número inteiro"},
+	"import.boolean": {Detail: "%s deve ser true ou false"},
+}
@@ -0,0 +1,127 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+const (
+	English    = "en"
+	Spanish    = "es"
+	Portuguese = "pt"
+
+	DefaultLanguage = English
+)
+
+// Message is the localized text of a catalogue entry. Detail is a fmt template receiving
+// the arguments of the error, and is empty when the original error message is kept.
+type Message struct {
+	Title  string
+	Detail string
+}
+
+var catalogues = map[string]map[string]Message{
+	English:    englishCatalogue,
+	Spanish:    spanishCatalogue,
+	Portuguese: portugueseCatalogue,
+}
+
+// Languages returns the supported languages, the default one first.
+func Languages() []string {
+	return []string{English, Spanish, Portuguese}
+}
+
+// Negotiate returns the supported language preferred by an Accept-Language header,
+// matching on the primary subtag, e.g. pt-BR selects pt.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		language string
+		quality  float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		language := strings.SplitN(tag, "-", 2)[0]
+		if _, ok := catalogues[language]; (ok || language == "*") && quality > 0 {
+			candidates = append(candidates, candidate{language: language, quality: quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	if len(candidates) == 0 || candidates[0].language == "*" {
+		return DefaultLanguage
+	}
+
+	return candidates[0].language
+}
+
+// Title returns the localized title of an error code, falling back to English and then to the domain catalogue.
+func Title(language, code string) string {
+	if message, ok := lookup(language, code); ok && message.Title != "" {
+		return message.Title
+	}
+
+	return domain.LookupError(code).Title
+}
+
+// Detail returns the detail of an error code in the given language, the template of the code
+// formatted with args, falling back to English. It returns false when the code has no template.
+func Detail(language, code string, args ...interface{}) (string, bool) {
+	message, ok := catalogues[language][code]
+	if !ok || message.Detail == "" {
+		message = catalogues[DefaultLanguage][code]
+	}
+
+	if message.Detail == "" {
+		return "", false
+	}
+
+	return format(message.Detail, args), true
+}
+
+func format(template string, args []interface{}) string {
+	if len(args) == 0 {
+		return template
+	}
+
+	return fmt.Sprintf(template, args...)
+}
+
+// Text returns a localized text that is not an error code, e.g. a validation rule.
+func Text(language, key string) string {
+	message, _ := lookup(language, key)
+	return message.Detail
+}
+
+func lookup(language, key string) (Message, bool) {
+	if message, ok := catalogues[language][key]; ok {
+		return message, true
+	}
+
+	message, ok := catalogues[DefaultLanguage][key]
+
+	return message, ok
+}
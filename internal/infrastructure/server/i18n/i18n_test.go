@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+func TestDetail(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		code     string
+		args     []interface{}
+		want     string
+		wantOK   bool
+	}{
+		{
+			name:     "template formatted with the arguments",
+			language: Spanish,
+			code:     domain.ErrCodeItemPhotosLimit,
+			args:     []interface{}{20},
+			want:     "Un ítem puede tener como máximo 20 fotos",
+			wantOK:   true,
+		},
+		{
+			name:     "template without arguments",
+			language: Portuguese,
+			code:     domain.ErrCodeItemPhotosRequired,
+			want:     "O item deve ter pelo menos uma foto",
+			wantOK:   true,
+		},
+		{
+			name:     "default language",
+			language: English,
+			code:     domain.ErrCodeItemLeaderLevelInvalid,
+			args:     []interface{}{"DIAMOND"},
+			want:     "Leader level is not valid: DIAMOND",
+			wantOK:   true,
+		},
+		{
+			name:     "unsupported language",
+			language: "fr",
+			code:     domain.ErrCodeItemPhotosRequired,
+			want:     "The item must have at least one photo",
+			wantOK:   true,
+		},
+		{
+			name:     "code without a template",
+			language: Spanish,
+			code:     domain.ErrCodeItemValidationFailed,
+			wantOK:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := Detail(test.language, test.code, test.args...)
+			if got != test.want || ok != test.wantOK {
+				t.Errorf("Detail() = %q, %v, want %q, %v", got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler"
+)
+
+// metricsReadTimeout bounds the requests of the metrics server, which only serves scrapes.
+const metricsReadTimeout = 10 * time.Second
+
+// NewMetricsServer serves the metrics on their own address, apart from the routes of the API,
+// so they are not exposed to the clients of the API.
+func NewMetricsServer(addr string, metricsHandler handler.MetricsHandler) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(res http.ResponseWriter, req *http.Request) {
+		// The error is a failed write of the response, after the scraper went away.
+		_ = metricsHandler.GetMetrics(res, req)
+	})
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: metricsReadTimeout,
+		ReadTimeout:       metricsReadTimeout,
+	}
+}
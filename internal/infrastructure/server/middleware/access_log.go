@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"math/rand"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+// requestIDPattern keeps the request IDs sent by the clients short and printable, so they can
+// be logged and echoed as they are.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// AccessLog logs a line per request, and gives every request an ID that is sent back in the
+// X-Request-Id header.
+type AccessLog struct {
+	sampleRate    float64
+	sampledRoutes map[string]bool
+	sample        func() float64
+}
+
+func NewAccessLog(config AccessLogConfig) *AccessLog {
+	sampledRoutes := make(map[string]bool, len(config.SampledRoutes))
+	for _, route := range config.SampledRoutes {
+		sampledRoutes[route] = true
+	}
+
+	return &AccessLog{
+		sampleRate:    config.SampleRate,
+		sampledRoutes: sampledRoutes,
+		sample:        rand.Float64,
+	}
+}
+
+// Route returns a middleware that logs the requests of a route, by the pattern it was registered
+// with. A request without a valid X-Request-Id gets the ID of its trace, or a new one, which is
+// set on the request so every logger of the request has it, see marketcontext.New.
+func (a *AccessLog) Route(method, route string) web.Middleware {
+	sampled := a.sampledRoutes[method+" "+route]
+
+	return func(next web.Handler) web.Handler {
+		return func(res http.ResponseWriter, req *http.Request) error {
+			start := time.Now()
+
+			if !requestIDPattern.MatchString(req.Header.Get(marketcontext.RequestIDKey)) {
+				req.Header.Del(marketcontext.RequestIDKey)
+			}
+
+			logger := marketcontext.Logger(marketcontext.New(req))
+			requestID := logger.GetRequestID()
+
+			req.Header.Set(marketcontext.RequestIDKey, requestID)
+			res.Header().Set(marketcontext.RequestIDKey, requestID)
+
+			recorder := &statusRecorder{ResponseWriter: res}
+			err := next(recorder, req)
+			status := recorder.Status(err)
+
+			if status < http.StatusBadRequest && sampled && a.sample() >= a.sampleRate {
+				return err
+			}
+
+			logger = logger.With(
+				log.String("method", method),
+				log.String("route", route),
+				log.Int("status", status),
+				log.Int64("bytes", recorder.bytes),
+				log.Duration("duration", time.Since(start)),
+				log.String("client_ip", marketcontext.ClientIP(req)),
+			)
+
+			if status >= http.StatusInternalServerError {
+				logger.Error(a, nil, err, "request served")
+			} else {
+				logger.Info(a, nil, "request served")
+			}
+
+			return err
+		}
+	}
+}
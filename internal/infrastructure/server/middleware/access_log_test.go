@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"github.com/osalomon89/test-crud-api/pkg/log"
+)
+
+type accessLogBackend struct {
+	entries []log.Entry
+}
+
+func (backend *accessLogBackend) Write(entry log.Entry) {
+	if entry.Message == "request served" {
+		backend.entries = append(backend.entries, entry)
+	}
+}
+
+func captureAccessLog(t *testing.T) *accessLogBackend {
+	t.Helper()
+
+	backend := &accessLogBackend{}
+	log.SetBackend(backend)
+	t.Cleanup(func() { log.SetBackend(log.ToolkitBackend()) })
+
+	return backend
+}
+
+func statusHandler(status int) web.Handler {
+	return func(res http.ResponseWriter, req *http.Request) error {
+		res.WriteHeader(status)
+
+		return nil
+	}
+}
+
+func serveAccessLog(t *testing.T, h web.Handler, requestID string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items/1", nil)
+	if requestID != "" {
+		req.Header.Set(marketcontext.RequestIDKey, requestID)
+	}
+
+	res := httptest.NewRecorder()
+	if err := h(res, req); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	return res
+}
+
+func TestAccessLogEchoesAValidRequestID(t *testing.T) {
+	captureAccessLog(t)
+
+	var seen string
+	h := NewAccessLog(AccessLogConfig{SampleRate: 1}).Route(http.MethodGet, "/v1/items/{id}")(
+		func(res http.ResponseWriter, req *http.Request) error {
+			seen = req.Header.Get(marketcontext.RequestIDKey)
+			return statusHandler(http.StatusOK)(res, req)
+		})
+
+	res := serveAccessLog(t, h, "client-id.42:a_b")
+
+	if got := res.Header().Get(marketcontext.RequestIDKey); got != "client-id.42:a_b" || seen != got {
+		t.Errorf("request ID = %q, seen by the handler as %q, want %q", got, seen, "client-id.42:a_b")
+	}
+}
+
+func TestAccessLogReplacesAnInvalidRequestID(t *testing.T) {
+	captureAccessLog(t)
+
+	h := NewAccessLog(AccessLogConfig{SampleRate: 1}).Route(http.MethodGet, "/v1/items/{id}")(
+		statusHandler(http.StatusOK))
+
+	for _, requestID := range []string{"", "id with spaces", "id\r\nX-Injected: 1", strings.Repeat("a", 129)} {
+		got := serveAccessLog(t, h, requestID).Header().Get(marketcontext.RequestIDKey)
+		if got == requestID || !requestIDPattern.MatchString(got) {
+			t.Errorf("request ID %q answered with %q, want a new valid one", requestID, got)
+		}
+	}
+}
+
+func TestAccessLogSamplesOnlyTheSuccessfulRequestsOfSampledRoutes(t *testing.T) {
+	backend := captureAccessLog(t)
+
+	accessLog := NewAccessLog(AccessLogConfig{SampleRate: 0.1, SampledRoutes: []string{"GET /v1/items/{id}"}})
+	accessLog.sample = func() float64 { return 0.5 }
+
+	sampled := accessLog.Route(http.MethodGet, "/v1/items/{id}")
+	other := accessLog.Route(http.MethodGet, "/v1/jobs/{id}")
+
+	tests := []struct {
+		name   string
+		h      web.Handler
+		logged bool
+		level  log.Level
+	}{
+		{name: "sampled out", h: sampled(statusHandler(http.StatusOK))},
+		{name: "client error", h: sampled(statusHandler(http.StatusNotFound)), logged: true, level: log.LevelInfo},
+		{name: "server error", h: sampled(statusHandler(http.StatusInternalServerError)), logged: true,
+			level: log.LevelError},
+		{name: "route not sampled", h: other(statusHandler(http.StatusOK)), logged: true, level: log.LevelInfo},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend.entries = nil
+			serveAccessLog(t, test.h, "")
+
+			if logged := len(backend.entries) == 1; logged != test.logged {
+				t.Fatalf("logged = %v, want %v", logged, test.logged)
+			}
+
+			if test.logged && backend.entries[0].Level != test.level {
+				t.Errorf("level = %s, want %s", backend.entries[0].Level, test.level)
+			}
+		})
+	}
+
+	accessLog.sample = func() float64 { return 0.05 }
+	backend.entries = nil
+	serveAccessLog(t, sampled(statusHandler(http.StatusOK)), "")
+
+	if len(backend.entries) != 1 {
+		t.Error("a request within the sample rate was not logged")
+	}
+}
+
+func TestLoadAccessLogConfigSamplesOneRequestInTen(t *testing.T) {
+	t.Setenv(accessLogSampleRate, "")
+
+	if got := LoadAccessLogConfig().SampleRate; got != 0.1 {
+		t.Errorf("SampleRate = %v, want 0.1", got)
+	}
+
+	t.Setenv(accessLogSampleRate, "1")
+
+	if got := LoadAccessLogConfig().SampleRate; got != 1 {
+		t.Errorf("SampleRate = %v, want 1", got)
+	}
+}
+
+func TestAccessLogIgnoresTheForwardedForOfUntrustedProxies(t *testing.T) {
+	backend := captureAccessLog(t)
+
+	h := NewAccessLog(AccessLogConfig{SampleRate: 1}).Route(http.MethodGet, "/v1/items/{id}")(
+		statusHandler(http.StatusOK))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items/1", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	if err := h(httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+
+	if len(backend.entries) != 1 {
+		t.Fatalf("got %d lines, want 1", len(backend.entries))
+	}
+
+	var clientIP interface{}
+	for _, field := range backend.entries[0].Fields {
+		if field.Key == "client_ip" {
+			clientIP = field.Value
+		}
+	}
+
+	if clientIP != "203.0.113.7" {
+		t.Errorf("client_ip = %v, want 203.0.113.7", clientIP)
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const APIKeyHeader = "X-Api-Key"
+
+// Authenticate returns a middleware that identifies the caller by the API key of the request.
+// A request without a key goes on as anonymous, and one with an unknown key is rejected.
+func Authenticate(config AuthConfig) web.Middleware {
+	return func(next web.Handler) web.Handler {
+		return func(res http.ResponseWriter, req *http.Request) error {
+			key := req.Header.Get(APIKeyHeader)
+			if key == "" {
+				return next(res, req)
+			}
+
+			caller, ok := config.APIKeys[hashAPIKey(key)]
+			if !ok {
+				return problem.WriteCode(marketcontext.New(req), res, req, domain.ErrCodeUnauthorized,
+					"The API key is not valid")
+			}
+
+			caller.IP = marketcontext.ClientIP(req)
+
+			return next(res, req.WithContext(marketcontext.WithCaller(req.Context(), caller)))
+		}
+	}
+}
+
+// RequireAdmin is a middleware that only lets through the callers authenticated with an admin
+// key, see LoadAuthConfig. It goes after Authenticate.
+func RequireAdmin(next web.Handler) web.Handler {
+	return func(res http.ResponseWriter, req *http.Request) error {
+		ctx := marketcontext.New(req)
+		caller := marketcontext.GetCaller(ctx)
+
+		if caller.ID == "" {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeUnauthorized, "")
+		}
+
+		if !caller.Admin {
+			marketcontext.Logger(ctx).Warn(nil, map[string]string{"caller": caller.ID},
+				"admin endpoint refused to a caller that is not an admin")
+
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeForbidden, "")
+		}
+
+		return next(res, req)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	auth := AuthConfig{APIKeys: map[string]marketcontext.Caller{
+		hashAPIKey("key-1"): {ID: "caller-1"},
+		hashAPIKey("key-2"): {ID: "ops", Admin: true},
+	}}
+	handler := Authenticate(auth)(RequireAdmin(okHandler))
+
+	tests := []struct {
+		name string
+		key  string
+		want int
+	}{
+		{name: "anonymous", want: http.StatusUnauthorized},
+		{name: "unknown key", key: "unknown", want: http.StatusUnauthorized},
+		{name: "caller", key: "key-1", want: http.StatusForbidden},
+		{name: "admin", key: "key-2", want: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/v1/admin/log-level", nil)
+			if test.key != "" {
+				req.Header.Set(APIKeyHeader, test.key)
+			}
+
+			res := httptest.NewRecorder()
+			if err := handler(res, req); err != nil {
+				t.Fatalf("handler error = %v", err)
+			}
+
+			if res.Code != test.want {
+				t.Errorf("got status %d, want %d", res.Code, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+var (
+	rateLimitRate          = "RATE_LIMIT_RATE"
+	rateLimitBurst         = "RATE_LIMIT_BURST"
+	idempotencyTTL         = "IDEMPOTENCY_TTL"
+	idempotencyWait        = "IDEMPOTENCY_WAIT"
+	idempotencyLease       = "IDEMPOTENCY_LEASE"
+	idempotencyPurge       = "IDEMPOTENCY_PURGE_INTERVAL"
+	accessLogSampleRate    = "ACCESS_LOG_SAMPLE_RATE"
+	accessLogSampledRoutes = "ACCESS_LOG_SAMPLED_ROUTES"
+	apiKeys                = "API_KEYS"
+	trustedProxies         = "TRUSTED_PROXIES"
+)
+
+// writeRateLimit is the limit of the route groups that write when none is set for them.
+var writeRateLimit = domain.RateLimit{Rate: 5, Burst: 10}
+
+// rateLimitGroups are the route groups with a rate limit, by whether they write.
+var rateLimitGroups = map[string]bool{
+	"items-read":  false,
+	"items-write": true,
+	"audit":       false,
+	"jobs-read":   false,
+	"jobs-write":  true,
+	"admin":       true,
+}
+
+// defaultAccessLogSampleRate logs one in ten of the successful requests of the sampled routes.
+const defaultAccessLogSampleRate = 0.1
+
+// defaultSampledRoutes are the routes read the most, by method and pattern.
+var defaultSampledRoutes = []string{
+	"GET /v1/items/{id}",
+	"GET /v1/items/search",
+	"GET /v1/items/suggest",
+	"GET /v1/blobs/{key}",
+}
+
+type RateLimitConfig struct {
+	Default domain.RateLimit
+	// Groups are the limits by route group.
+	Groups map[string]domain.RateLimit
+}
+
+// For returns the limit of a route group, the default one for a group without its own.
+func (config RateLimitConfig) For(group string) domain.RateLimit {
+	if limit, ok := config.Groups[group]; ok {
+		return limit
+	}
+
+	return config.Default
+}
+
+// LoadRateLimitConfig reads the rate limits from the environment. Rates are tokens per second.
+// The limit of a group is read from RATE_LIMIT_<GROUP>_RATE and RATE_LIMIT_<GROUP>_BURST, e.g.
+// RATE_LIMIT_ITEMS_WRITE_RATE, and defaults to RATE_LIMIT_RATE and RATE_LIMIT_BURST, or to a
+// lower limit for the groups that write.
+func LoadRateLimitConfig() RateLimitConfig {
+	config := RateLimitConfig{
+		Default: domain.RateLimit{
+			Rate:  getEnvFloat(rateLimitRate, 50),
+			Burst: getEnvInt(rateLimitBurst, 100),
+		},
+		Groups: map[string]domain.RateLimit{},
+	}
+
+	for group, writes := range rateLimitGroups {
+		limit := config.Default
+		if writes {
+			limit = writeRateLimit
+		}
+
+		prefix := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(group, "-", "_"))
+		config.Groups[group] = domain.RateLimit{
+			Rate:  getEnvFloat(prefix+"_RATE", limit.Rate),
+			Burst: getEnvInt(prefix+"_BURST", limit.Burst),
+		}
+	}
+
+	return config
+}
+
+type IdempotencyConfig struct {
+	TTL  time.Duration
+	Wait time.Duration
+	// Lease is how long a key stays reserved by a request that stopped renewing it, e.g. because
+	// the instance serving it crashed, before another request can take it over.
+	Lease time.Duration
+	// PurgeInterval is how often the expired responses are deleted.
+	PurgeInterval time.Duration
+}
+
+// LoadIdempotencyConfig reads from the environment how long responses are kept for replay,
+// how long a retry waits for a concurrent request with the same key, the lease of the
+// requests in progress and how often the expired responses are purged.
+func LoadIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:           getEnvDuration(idempotencyTTL, 24*time.Hour),
+		Wait:          getEnvDuration(idempotencyWait, 10*time.Second),
+		Lease:         getEnvDuration(idempotencyLease, 30*time.Second),
+		PurgeInterval: getEnvDuration(idempotencyPurge, time.Hour),
+	}
+}
+
+type AccessLogConfig struct {
+	// SampleRate is the share, from 0 to 1, of the successful requests of the sampled routes
+	// that are logged. The failed requests are always logged.
+	SampleRate float64
+	// SampledRoutes are the routes sampled, as the method and the pattern, e.g.
+	// GET /v1/items/{id}.
+	SampledRoutes []string
+}
+
+// LoadAccessLogConfig reads from the environment which routes are sampled, comma separated, and
+// how many of their successful requests are logged, one in ten unless a rate is set.
+func LoadAccessLogConfig() AccessLogConfig {
+	config := AccessLogConfig{
+		SampleRate:    defaultAccessLogSampleRate,
+		SampledRoutes: defaultSampledRoutes,
+	}
+
+	if rate, err := strconv.ParseFloat(os.Getenv(accessLogSampleRate), 64); err == nil && rate >= 0 && rate <= 1 {
+		config.SampleRate = rate
+	}
+
+	if routes := os.Getenv(accessLogSampledRoutes); routes != "" {
+		config.SampledRoutes = nil
+
+		for _, route := range strings.Split(routes, ",") {
+			if route = strings.TrimSpace(route); route != "" {
+				config.SampledRoutes = append(config.SampledRoutes, route)
+			}
+		}
+	}
+
+	return config
+}
+
+type AuthConfig struct {
+	// APIKeys are the callers by the SHA-256 of their key, so the keys are not kept in memory.
+	APIKeys map[string]marketcontext.Caller
+}
+
+// LoadAuthConfig reads the API keys from the environment, comma separated, as caller:key, or
+// caller:key:admin for the callers allowed to use the admin endpoints. Malformed entries are
+// left out.
+func LoadAuthConfig() AuthConfig {
+	config := AuthConfig{APIKeys: map[string]marketcontext.Caller{}}
+
+	for _, entry := range strings.Split(os.Getenv(apiKeys), ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			continue
+		}
+
+		if len(parts) == 3 && parts[2] != "admin" {
+			continue
+		}
+
+		config.APIKeys[hashAPIKey(parts[1])] = marketcontext.Caller{ID: parts[0], Admin: len(parts) == 3}
+	}
+
+	return config
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadTrustedProxies reads from the environment the IPs and CIDRs, comma separated, of the
+// proxies in front of the service, see marketcontext.SetTrustedProxies.
+func LoadTrustedProxies() []string {
+	var proxies []string
+
+	for _, proxy := range strings.Split(os.Getenv(trustedProxies), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyPollInterval   = 100 * time.Millisecond
+	idempotencyInProgressWait = 1
+)
+
+type idempotency struct {
+	store  ports.IdempotencyStore
+	config IdempotencyConfig
+}
+
+// Idempotency returns a middleware that stores the response of every request sent with an
+// Idempotency-Key header and replays it when the request is retried with the same key.
+// A retry arriving while the original request is still running waits for its outcome.
+// The keys are scoped to the authenticated caller, or to the client IP of the anonymous
+// ones, so a caller can not replay the responses of another one.
+func Idempotency(store ports.IdempotencyStore, config IdempotencyConfig) web.Middleware {
+	i := &idempotency{
+		store:  store,
+		config: config,
+	}
+
+	return i.handle
+}
+
+func (i *idempotency) handle(next web.Handler) web.Handler {
+	return func(res http.ResponseWriter, req *http.Request) error {
+		key := req.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return next(res, req)
+		}
+
+		ctx := marketcontext.New(req)
+		logger := marketcontext.Logger(ctx)
+
+		if len(key) > maxIdempotencyKeyLength {
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, "Idempotency-Key is too long")
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			logger.Error(i, nil, err, "error reading request body")
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeMalformedRequest, err.Error())
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := clientKey(ctx) + ":" + key
+		fingerprint := requestFingerprint(req, body)
+		deadline := time.Now().Add(i.config.Wait)
+
+		var owner string
+
+		for {
+			var record *domain.IdempotencyRecord
+
+			owner, record, err = i.store.Reserve(ctx, storeKey, fingerprint, i.config.TTL, i.config.Lease)
+			if err != nil {
+				logger.Error(i, nil, err, "error reserving idempotency key")
+				return problem.Write(ctx, res, req, err)
+			}
+
+			if record == nil {
+				break
+			}
+
+			if record.Fingerprint != fingerprint {
+				return problem.WriteCode(ctx, res, req, domain.ErrCodeIdempotencyKeyReused, "")
+			}
+
+			if record.Completed {
+				logger.Info(i, nil, "replaying response for idempotency key")
+				return replay(res, record)
+			}
+
+			if time.Now().After(deadline) {
+				res.Header().Set("Retry-After", strconv.Itoa(idempotencyInProgressWait))
+				return problem.WriteCode(ctx, res, req, domain.ErrCodeIdempotencyInProgress, "")
+			}
+
+			select {
+			case <-req.Context().Done():
+				return req.Context().Err()
+			case <-time.After(idempotencyPollInterval):
+			}
+		}
+
+		stopRenewing := i.renew(ctx, storeKey, owner)
+		recorder := newResponseRecorder(res)
+		err = next(recorder, req)
+		stopRenewing()
+
+		if err != nil || recorder.Status() >= http.StatusInternalServerError {
+			if releaseErr := i.store.Release(ctx, storeKey, owner); releaseErr != nil {
+				logger.Error(i, nil, releaseErr, "error releasing idempotency key")
+			}
+
+			return err
+		}
+
+		err = i.store.Complete(ctx, domain.IdempotencyRecord{
+			Key:         storeKey,
+			Owner:       owner,
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.Status(),
+			Header:      recorder.Header().Clone(),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			logger.Error(i, nil, err, "error storing response for idempotency key")
+		}
+
+		return nil
+	}
+}
+
+// renew extends the lease of the key every third of the lease while the request runs, until the
+// returned function is called.
+func (i *idempotency) renew(ctx context.Context, key, owner string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(i.config.Lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := i.store.Renew(ctx, key, owner, i.config.Lease); err != nil {
+					marketcontext.Logger(ctx).Error(i, nil, err, "error renewing idempotency key")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay writes a stored response. Headers already set by outer middlewares,
+// such as the rate limit ones, are kept over the stored values.
+func replay(res http.ResponseWriter, record *domain.IdempotencyRecord) error {
+	for name, values := range record.Header {
+		if _, ok := res.Header()[name]; !ok {
+			res.Header()[name] = values
+		}
+	}
+
+	res.Header().Set(IdempotentReplayedHeader, "true")
+	res.WriteHeader(record.StatusCode)
+	_, err := res.Write(record.Body)
+
+	return err
+}
@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/memory"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type idempotencyResponse struct {
+	status   int
+	body     string
+	replayed bool
+}
+
+func newIdempotentHandler(t *testing.T) (func(body string, headers map[string]string) idempotencyResponse, *int) {
+	calls := 0
+	created := func(res http.ResponseWriter, req *http.Request) error {
+		calls++
+
+		body, _ := io.ReadAll(req.Body)
+		res.WriteHeader(http.StatusCreated)
+		_, err := res.Write([]byte("created " + string(body)))
+
+		return err
+	}
+
+	auth := AuthConfig{APIKeys: map[string]marketcontext.Caller{
+		hashAPIKey("key-1"): {ID: "caller-1"},
+		hashAPIKey("key-2"): {ID: "caller-2"},
+	}}
+	config := IdempotencyConfig{TTL: time.Hour, Wait: 10 * time.Millisecond, Lease: time.Minute}
+	handler := Authenticate(auth)(Idempotency(memory.NewIdempotencyStore(), config)(created))
+
+	return func(body string, headers map[string]string) idempotencyResponse {
+		req := httptest.NewRequest(http.MethodPost, "/v1/items", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "key")
+
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		res := httptest.NewRecorder()
+		if err := handler(res, req); err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+
+		return idempotencyResponse{
+			status:   res.Code,
+			body:     res.Body.String(),
+			replayed: res.Header().Get(IdempotentReplayedHeader) == "true",
+		}
+	}, &calls
+}
+
+func TestIdempotencyReplaysTheStoredResponse(t *testing.T) {
+	serve, calls := newIdempotentHandler(t)
+
+	first := serve(`{"code":"A"}`, nil)
+	retry := serve(`{"code":"A"}`, nil)
+
+	if *calls != 1 {
+		t.Fatalf("handler called %d times, want 1", *calls)
+	}
+
+	if first.replayed || !retry.replayed {
+		t.Errorf("replayed = %v then %v, want false then true", first.replayed, retry.replayed)
+	}
+
+	if retry.status != http.StatusCreated || retry.body != first.body {
+		t.Errorf("replay = %d %q, want %d %q", retry.status, retry.body, http.StatusCreated, first.body)
+	}
+}
+
+func TestIdempotencyRejectsAKeyReusedWithAnotherRequest(t *testing.T) {
+	serve, calls := newIdempotentHandler(t)
+
+	serve(`{"code":"A"}`, nil)
+
+	if got := serve(`{"code":"B"}`, nil); got.status != http.StatusConflict {
+		t.Errorf("got status %d, want %d", got.status, http.StatusConflict)
+	}
+
+	if *calls != 1 {
+		t.Errorf("handler called %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencyScopesKeysToTheCaller(t *testing.T) {
+	serve, calls := newIdempotentHandler(t)
+
+	serve(`{"code":"A"}`, map[string]string{APIKeyHeader: "key-1"})
+	other := serve(`{"code":"A"}`, map[string]string{APIKeyHeader: "key-2"})
+	anonymous := serve(`{"code":"A"}`, nil)
+
+	if other.replayed || anonymous.replayed || *calls != 3 {
+		t.Errorf("the response of a caller was replayed to another one, calls = %d", *calls)
+	}
+}
+
+func TestIdempotencyServesConcurrentRequestsWithTheSameKeyOnce(t *testing.T) {
+	var calls int32
+
+	entered := make(chan struct{})
+	proceed := make(chan struct{})
+	created := func(res http.ResponseWriter, req *http.Request) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(entered)
+		}
+
+		<-proceed
+		res.WriteHeader(http.StatusCreated)
+		_, err := res.Write([]byte("created"))
+
+		return err
+	}
+
+	config := IdempotencyConfig{TTL: time.Hour, Wait: 5 * time.Second, Lease: time.Minute}
+	handler := Idempotency(memory.NewIdempotencyStore(), config)(created)
+
+	const requests = 5
+	responses := make([]*httptest.ResponseRecorder, requests)
+
+	var wg sync.WaitGroup
+
+	for n := range responses {
+		wg.Add(1)
+
+		go func(n int) {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/items", strings.NewReader(`{"code":"A"}`))
+			req.Header.Set(IdempotencyKeyHeader, "key")
+			responses[n] = httptest.NewRecorder()
+
+			if err := handler(responses[n], req); err != nil {
+				t.Errorf("handler error = %v", err)
+			}
+		}(n)
+	}
+
+	<-entered
+	// The other requests are waiting for the first one by now, or reserve the key after it.
+	time.Sleep(50 * time.Millisecond)
+	close(proceed)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+
+	replayed := 0
+
+	for _, res := range responses {
+		if res.Code != http.StatusCreated || res.Body.String() != "created" {
+			t.Errorf("response = %d %q, want %d %q", res.Code, res.Body.String(), http.StatusCreated, "created")
+		}
+
+		if res.Header().Get(IdempotentReplayedHeader) == "true" {
+			replayed++
+		}
+	}
+
+	if replayed != requests-1 {
+		t.Errorf("%d responses replayed, want %d", replayed, requests-1)
+	}
+}
+
+func TestIdempotencyStoreTakesOverAnExpiredLease(t *testing.T) {
+	store := memory.NewIdempotencyStore()
+	ctx := context.Background()
+
+	owner, record, err := store.Reserve(ctx, "key", "a", time.Hour, time.Minute)
+	if owner == "" || record != nil || err != nil {
+		t.Fatalf("Reserve() = %q, %v, %v, want the key reserved", owner, record, err)
+	}
+
+	if _, record, _ := store.Reserve(ctx, "key", "b", time.Hour, time.Minute); record == nil {
+		t.Fatal("Reserve() took over a key whose lease has not expired")
+	}
+
+	if err := store.Renew(ctx, "key", owner, time.Millisecond); err != nil {
+		t.Fatalf("Renew() error = %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	taken, record, err := store.Reserve(ctx, "key", "b", time.Hour, time.Minute)
+	if taken == "" || taken == owner || record != nil || err != nil {
+		t.Fatalf("Reserve() = %q, %v, %v, want the expired lease taken over", taken, record, err)
+	}
+}
+
+func TestIdempotencyStoreKeepsTheKeyOfTheRequestThatTookItOver(t *testing.T) {
+	store := memory.NewIdempotencyStore()
+	ctx := context.Background()
+
+	late, _, _ := store.Reserve(ctx, "key", "a", time.Hour, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	owner, _, _ := store.Reserve(ctx, "key", "a", time.Hour, time.Minute)
+
+	if err := store.Renew(ctx, "key", late, time.Minute); err == nil {
+		t.Error("Renew() by the request whose lease expired succeeded")
+	}
+
+	completed := domain.IdempotencyRecord{Key: "key", Owner: owner, StatusCode: http.StatusCreated}
+	if err := store.Complete(ctx, completed); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if err := store.Release(ctx, "key", late); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	if err := store.Complete(ctx, domain.IdempotencyRecord{Key: "key", Owner: late, StatusCode: 500}); err == nil {
+		t.Error("Complete() by the request whose lease expired succeeded")
+	}
+
+	// Completed records are not released by their own owner either.
+	if err := store.Release(ctx, "key", owner); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	_, record, err := store.Reserve(ctx, "key", "a", time.Hour, time.Minute)
+	if err != nil || record == nil || record.StatusCode != http.StatusCreated {
+		t.Fatalf("Reserve() = %+v, %v, want the response of the request that took the key over", record, err)
+	}
+}
+
+func TestIdempotencyStorePurgesTheExpiredRecords(t *testing.T) {
+	store := memory.NewIdempotencyStore()
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c"} {
+		store.Reserve(ctx, key, key, time.Millisecond, time.Minute)
+	}
+
+	store.Reserve(ctx, "live", "live", time.Hour, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+
+	if purged, err := store.PurgeExpired(ctx, 2); purged != 2 || err != nil {
+		t.Fatalf("PurgeExpired(2) = %d, %v, want 2", purged, err)
+	}
+
+	if purged, err := store.PurgeExpired(ctx, 2); purged != 1 || err != nil {
+		t.Fatalf("PurgeExpired(2) = %d, %v, want the last expired record", purged, err)
+	}
+
+	if _, record, _ := store.Reserve(ctx, "live", "live", time.Hour, time.Minute); record == nil {
+		t.Error("PurgeExpired() deleted a record that has not expired")
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/metrics"
+)
+
+// RouteMetrics measures the rate, the errors and the duration of the requests by route.
+type RouteMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+	inFlight *metrics.Gauge
+}
+
+func NewRouteMetrics(registry *metrics.Registry) *RouteMetrics {
+	return &RouteMetrics{
+		requests: registry.NewCounter("http_requests_total", "Requests served, by route and status.",
+			"method", "route", "status"),
+		duration: registry.NewHistogram("http_request_duration_seconds", "Duration of the requests, by route.",
+			metrics.HTTPBuckets, "method", "route"),
+		inFlight: registry.NewGauge("http_requests_in_flight", "Requests being served."),
+	}
+}
+
+// Route returns a middleware that measures the requests of a route. The route is the pattern
+// it was registered with, e.g. /v1/items/{id}, so the series do not grow with the IDs.
+func (m *RouteMetrics) Route(method, route string) web.Middleware {
+	return func(next web.Handler) web.Handler {
+		return func(res http.ResponseWriter, req *http.Request) error {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: res}
+
+			m.inFlight.Add(1)
+			defer m.inFlight.Add(-1)
+
+			err := next(recorder, req)
+			status := recorder.Status(err)
+
+			m.requests.Inc(method, route, strconv.Itoa(status))
+			m.duration.Observe(time.Since(start).Seconds(), method, route)
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/problem"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+type rateLimiter struct {
+	store ports.RateLimitStore
+	group string
+	limit domain.RateLimit
+}
+
+// RateLimit returns a middleware that applies a token bucket per client to every
+// route it wraps. Routes sharing a group share the same bucket for a given client.
+func RateLimit(store ports.RateLimitStore, group string, limit domain.RateLimit) web.Middleware {
+	limiter := &rateLimiter{
+		store: store,
+		group: group,
+		limit: limit,
+	}
+
+	return limiter.handle
+}
+
+func (l *rateLimiter) handle(next web.Handler) web.Handler {
+	return func(res http.ResponseWriter, req *http.Request) error {
+		ctx := marketcontext.New(req)
+		logger := marketcontext.Logger(ctx)
+
+		result, err := l.store.Take(ctx, l.group+":"+clientKey(ctx), l.limit)
+		if err != nil {
+			logger.Error(l, nil, err, "error taking rate limit token, letting the request through")
+			return next(res, req)
+		}
+
+		res.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		res.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		res.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			logger.Warn(l, map[string]string{"group": l.group}, "rate limit exceeded")
+			res.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+
+			return problem.WriteCode(ctx, res, req, domain.ErrCodeRateLimitExceeded, "")
+		}
+
+		return next(res, req)
+	}
+}
+
+// clientKey identifies the caller authenticated by Authenticate, falling back to the client IP,
+// so a client can not get a new bucket by changing its headers.
+func clientKey(ctx context.Context) string {
+	caller := marketcontext.GetCaller(ctx)
+	if caller.ID != "" {
+		return "caller:" + caller.ID
+	}
+
+	return "ip:" + caller.IP
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
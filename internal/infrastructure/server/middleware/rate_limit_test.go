@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/repositories/memory"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+func okHandler(res http.ResponseWriter, req *http.Request) error {
+	res.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func newRateLimitedHandler(t *testing.T) func(headers map[string]string) int {
+	auth := AuthConfig{APIKeys: map[string]marketcontext.Caller{hashAPIKey("key-1"): {ID: "caller-1"}}}
+	limited := Authenticate(auth)(RateLimit(memory.NewRateLimitStore(), "test",
+		domain.RateLimit{Rate: 0.001, Burst: 2})(okHandler))
+
+	return func(headers map[string]string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/items/1", nil)
+		req.RemoteAddr = "203.0.113.7:4321"
+
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		res := httptest.NewRecorder()
+		if err := limited(res, req); err != nil {
+			t.Fatalf("handler error = %v", err)
+		}
+
+		return res.Code
+	}
+}
+
+func TestRateLimitExhaustsTheBucketOfAClient(t *testing.T) {
+	serve := newRateLimitedHandler(t)
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if got := serve(nil); got != want {
+			t.Fatalf("request %d: got status %d, want %d", i+1, got, want)
+		}
+	}
+}
+
+func TestRateLimitIgnoresSpoofableHeaders(t *testing.T) {
+	serve := newRateLimitedHandler(t)
+	serve(nil)
+	serve(nil)
+
+	spoofed := []map[string]string{
+		{"X-Forwarded-For": "198.51.100.1"},
+		{"X-Forwarded-For": "198.51.100.2, 10.0.0.1"},
+		{"X-Caller-Id": "someone-else"},
+	}
+
+	for _, headers := range spoofed {
+		if got := serve(headers); got != http.StatusTooManyRequests {
+			t.Errorf("with %v: got status %d, want %d", headers, got, http.StatusTooManyRequests)
+		}
+	}
+}
+
+func TestRateLimitKeepsABucketPerAuthenticatedCaller(t *testing.T) {
+	serve := newRateLimitedHandler(t)
+	serve(nil)
+	serve(nil)
+
+	withKey := map[string]string{APIKeyHeader: "key-1"}
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if got := serve(withKey); got != want {
+			t.Fatalf("request %d: got status %d, want %d", i+1, got, want)
+		}
+	}
+
+	if got := serve(map[string]string{APIKeyHeader: "unknown"}); got != http.StatusUnauthorized {
+		t.Errorf("with an unknown key: got status %d, want %d", got, http.StatusUnauthorized)
+	}
+}
+
+func TestLoadRateLimitConfig(t *testing.T) {
+	t.Setenv("RATE_LIMIT_RATE", "20")
+	t.Setenv("RATE_LIMIT_BURST", "40")
+	t.Setenv("RATE_LIMIT_ADMIN_RATE", "1")
+	t.Setenv("RATE_LIMIT_ADMIN_BURST", "2")
+
+	config := LoadRateLimitConfig()
+
+	tests := []struct {
+		group string
+		want  domain.RateLimit
+	}{
+		{group: "admin", want: domain.RateLimit{Rate: 1, Burst: 2}},
+		{group: "jobs-write", want: writeRateLimit},
+		{group: "audit", want: domain.RateLimit{Rate: 20, Burst: 40}},
+		{group: "unknown", want: domain.RateLimit{Rate: 20, Burst: 40}},
+	}
+
+	for _, tt := range tests {
+		if got := config.For(tt.group); got != tt.want {
+			t.Errorf("For(%q) = %+v, want %+v", tt.group, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseRecorder writes through to the wrapped ResponseWriter while keeping
+// a copy of the status code and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder(res http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: res}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.status
+}
+
+// statusRecorder writes through to the wrapped ResponseWriter keeping the status code and the
+// size of the body only, unlike responseRecorder, so streamed responses are not buffered.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+
+	return n, err
+}
+
+// Status returns the status code written. An error returned without writing a response is
+// answered by the app as a failure.
+func (r *statusRecorder) Status(err error) int {
+	switch {
+	case r.status != 0:
+		return r.status
+	case err != nil:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusOK
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/osalomon89/test-crud-api/internal/infrastructure/server"
+
+// Trace returns a middleware that starts the server span of a route, continuing the trace of the
+// traceparent header of the request when there is one. The span carries the request ID, which
+// is the trace ID when the request has no X-Request-Id, see marketcontext.New, read once the
+// request has been served so it is the one given by the AccessLog.
+func Trace(method, route string) web.Middleware {
+	return func(next web.Handler) web.Handler {
+		return func(res http.ResponseWriter, req *http.Request) error {
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := otel.Tracer(tracerName).Start(ctx, method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.method", method),
+					attribute.String("http.route", route),
+					attribute.String("http.target", req.URL.RequestURI()),
+					attribute.String("http.client_ip", marketcontext.ClientIP(req)),
+				))
+			defer span.End()
+
+			req = req.WithContext(ctx)
+
+			recorder := &statusRecorder{ResponseWriter: res}
+			err := next(recorder, req)
+			status := recorder.Status(err)
+
+			span.SetAttributes(
+				attribute.String("request.id", marketcontext.Logger(marketcontext.New(req)).GetRequestID()),
+				attribute.Int("http.status_code", status),
+			)
+
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/i18n"
+	marketcontext "github.com/osalomon89/test-crud-api/pkg/context"
+)
+
+const (
+	ContentType = "application/problem+json"
+	typePrefix  = "urn:test-crud-api:problem:"
+
+	internalErrorDetail = "An unexpected error occurred"
+)
+
+// New builds the problem of a catalogue code.
+func New(code, detail string) dto.Problem {
+	definition := domain.LookupError(code)
+
+	return dto.Problem{
+		Type:   typePrefix + strings.ToLower(strings.ReplaceAll(definition.Code, "_", "-")),
+		Title:  definition.Title,
+		Status: definition.Status,
+		Detail: detail,
+		Code:   definition.Code,
+	}
+}
+
+// FromError maps an error to its problem. The detail of an item error is its message, without
+// the prefix of its Error. Errors outside the catalogue become an internal error whose detail
+// does not leak the underlying message.
+func FromError(err error) dto.Problem {
+	var itemErr domain.ItemError
+	if errors.As(err, &itemErr) {
+		return New(itemErr.ErrorCode(), itemErr.Message)
+	}
+
+	var coded domain.CodedError
+	if errors.As(err, &coded) {
+		return New(coded.ErrorCode(), coded.Error())
+	}
+
+	return New(domain.ErrCodeInternal, internalErrorDetail)
+}
+
+// Resolve returns the problem of err in the language negotiated with the request, for the
+// responses that embed problems instead of being one.
+func Resolve(req *http.Request, err error) dto.Problem {
+	args, catalogue := catalogueDetail(err)
+
+	return localize(FromError(err), i18n.Negotiate(req.Header.Get("Accept-Language")), catalogue, args)
+}
+
+// Write writes the problem of err as the response.
+func Write(ctx context.Context, res http.ResponseWriter, req *http.Request, err error) error {
+	args, catalogue := catalogueDetail(err)
+
+	return encode(ctx, res, req, FromError(err), catalogue, args)
+}
+
+// WriteWithErrors writes the problem of err listing the errors it is made of.
+func WriteWithErrors(ctx context.Context, res http.ResponseWriter, req *http.Request, err error,
+	fieldErrors []dto.FieldError) error {
+	errProblem := FromError(err)
+	errProblem.Errors = fieldErrors
+	args, catalogue := catalogueDetail(err)
+
+	return encode(ctx, res, req, errProblem, catalogue, args)
+}
+
+// WriteCode writes the problem of a catalogue code as the response. An empty detail is the
+// detail of the code in the catalogue.
+func WriteCode(ctx context.Context, res http.ResponseWriter, req *http.Request, code, detail string) error {
+	return Encode(ctx, res, req, New(code, detail))
+}
+
+// WriteValidation writes a request validation problem listing every failing field.
+func WriteValidation(ctx context.Context, res http.ResponseWriter, req *http.Request,
+	fieldErrors []dto.FieldError) error {
+	validationProblem := New(domain.ErrCodeRequestValidationFailed, "")
+	validationProblem.Errors = fieldErrors
+
+	return Encode(ctx, res, req, validationProblem)
+}
+
+// Encode writes a problem in the language negotiated with the Accept-Language header of the
+// request. The code of the problem is never translated, and its detail only when it is empty.
+func Encode(ctx context.Context, res http.ResponseWriter, req *http.Request, problem dto.Problem) error {
+	return encode(ctx, res, req, problem, problem.Detail == "", nil)
+}
+
+func encode(ctx context.Context, res http.ResponseWriter, req *http.Request, problem dto.Problem,
+	catalogue bool, args []interface{}) error {
+	language := i18n.Negotiate(req.Header.Get("Accept-Language"))
+
+	problem = localize(problem, language, catalogue, args)
+	problem.Instance = req.URL.Path
+	problem.RequestID = marketcontext.Logger(ctx).GetRequestID()
+
+	res.Header().Set("Content-Type", ContentType)
+	res.Header().Set("Content-Language", language)
+	res.WriteHeader(problem.Status)
+
+	return json.NewEncoder(res).Encode(problem)
+}
+
+// localize translates the title of a problem and, when catalogue is set, its detail from the
+// template of its code formatted with args. The detail of a code without a template is kept.
+func localize(problem dto.Problem, language string, catalogue bool, args []interface{}) dto.Problem {
+	problem.Title = i18n.Title(language, problem.Code)
+
+	if !catalogue {
+		return problem
+	}
+
+	if detail, ok := i18n.Detail(language, problem.Code, args...); ok {
+		problem.Detail = detail
+	}
+
+	return problem
+}
+
+// catalogueDetail reports whether the detail of the problem of err is the one of its code, with
+// the arguments of the template: it is for the catalogue errors with arguments, whose message
+// says no more than their code and arguments, and for the errors outside the catalogue, which
+// become an internal error. A specific detail, e.g. the resource not found, is kept as it is.
+func catalogueDetail(err error) ([]interface{}, bool) {
+	var withArgs interface{ ErrorArgs() []interface{} }
+	if errors.As(err, &withArgs) {
+		return withArgs.ErrorArgs(), true
+	}
+
+	var coded domain.CodedError
+
+	return nil, !errors.As(err, &coded)
+}
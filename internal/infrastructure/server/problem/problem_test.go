@@ -0,0 +1,114 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		wantStatus int
+		wantDetail string
+	}{
+		{
+			name: "item error",
+			err: domain.ItemError{
+				Code:    domain.ErrCodeItemPhotosRequired,
+				Message: "The item must have at least one photo",
+			},
+			wantCode:   domain.ErrCodeItemPhotosRequired,
+			wantStatus: http.StatusBadRequest,
+			wantDetail: "The item must have at least one photo",
+		},
+		{
+			name:       "wrapped item error without a code",
+			err:        fmt.Errorf("error in repository: %w", domain.ItemError{Message: "Code is required"}),
+			wantCode:   domain.ErrCodeItemValidationFailed,
+			wantStatus: http.StatusBadRequest,
+			wantDetail: "Code is required",
+		},
+		{
+			name:       "not found",
+			err:        fmt.Errorf("error in repository: %w", domain.ResourceNotFoundError{Message: "Item not found"}),
+			wantCode:   domain.ErrCodeResourceNotFound,
+			wantStatus: http.StatusNotFound,
+			wantDetail: "Item not found",
+		},
+		{
+			name:       "error outside the catalogue",
+			err:        errors.New("dial tcp 127.0.0.1:3306: connection refused"),
+			wantCode:   domain.ErrCodeInternal,
+			wantStatus: http.StatusInternalServerError,
+			wantDetail: internalErrorDetail,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FromError(test.err)
+
+			if got.Code != test.wantCode || got.Status != test.wantStatus || got.Detail != test.wantDetail {
+				t.Errorf("FromError() = %s %d %q, want %s %d %q", got.Code, got.Status, got.Detail,
+					test.wantCode, test.wantStatus, test.wantDetail)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantTitle  string
+		wantDetail string
+	}{
+		{
+			name: "catalogue error translated by its code and arguments",
+			err: domain.Error{
+				Code:    domain.ErrCodeBulkTooLarge,
+				Message: "A bulk creation accepts at most 1000 items",
+				Args:    []interface{}{1000},
+			},
+			wantTitle:  "Demasiados ítems",
+			wantDetail: "Una creación masiva acepta como máximo 1000 ítems",
+		},
+		{
+			name:       "item error of a code without a template",
+			err:        domain.ItemError{Message: "Code is required"},
+			wantTitle:  "Error de validación del ítem",
+			wantDetail: "Code is required",
+		},
+		{
+			name:       "specific detail",
+			err:        domain.ResourceNotFoundError{Message: "Item not found"},
+			wantTitle:  "Recurso no encontrado",
+			wantDetail: "Item not found",
+		},
+		{
+			name:       "error outside the catalogue",
+			err:        errors.New("dial tcp 127.0.0.1:3306: connection refused"),
+			wantTitle:  "Error interno",
+			wantDetail: "Ocurrió un error inesperado",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/items/1", nil)
+			req.Header.Set("Accept-Language", "es")
+
+			got := Resolve(req, test.err)
+			if got.Title != test.wantTitle || got.Detail != test.wantDetail {
+				t.Errorf("Resolve() = %q %q, want %q %q", got.Title, got.Detail, test.wantTitle, test.wantDetail)
+			}
+		})
+	}
+}
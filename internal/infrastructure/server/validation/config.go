@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"os"
+	"strings"
+)
+
+var (
+	photoURLSchemes = "PHOTO_URL_SCHEMES"
+	photoURLHosts   = "PHOTO_URL_HOSTS"
+)
+
+var defaultPhotoURLSchemes = []string{"http", "https"} //nolint:gochecknoglobals
+
+type Config struct {
+	// PhotoURLSchemes are the schemes a photo URL can have.
+	PhotoURLSchemes []string
+	// PhotoURLHosts are the hosts a photo URL can point at, any host when empty. An entry
+	// starting with "*." matches the subdomains of the rest, e.g. "*.example.com". The host of
+	// BLOB_BASE_URL must be listed for the items with uploaded photos to be updated with PUT.
+	// The photo check only requests these hosts, and does not start when there is none.
+	PhotoURLHosts []string
+}
+
+// LoadConfig reads the allowlists as comma separated values, e.g. "https" and
+// "images.example.com,*.cdn.example.com".
+func LoadConfig() Config {
+	schemes := splitList(os.Getenv(photoURLSchemes))
+	if len(schemes) == 0 {
+		schemes = defaultPhotoURLSchemes
+	}
+
+	return Config{
+		PhotoURLSchemes: schemes,
+		PhotoURLHosts:   splitList(os.Getenv(photoURLHosts)),
+	}
+}
+
+// splitList returns the lower-cased non-empty values of a comma separated list.
+func splitList(value string) []string {
+	values := make([]string, 0)
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			values = append(values, field)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,189 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/pt"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	estranslations "github.com/go-playground/validator/v10/translations/es"
+	pttranslations "github.com/go-playground/validator/v10/translations/pt"
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler/dto"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/i18n"
+)
+
+// bindingTag is the struct tag holding the validation rules of the request DTOs.
+const bindingTag = "binding"
+
+// Validator checks the binding rules of the request DTOs, with messages in every supported language.
+type Validator struct {
+	validate   *validator.Validate
+	translator *ut.UniversalTranslator
+}
+
+func New(config Config) (*Validator, error) {
+	validate := newValidate(config)
+
+	translator, err := newTranslator(validate, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{
+		validate:   validate,
+		translator: translator,
+	}, nil
+}
+
+// Struct checks the binding rules of a body and returns every failing field, with its
+// message in the given language.
+func (v *Validator) Struct(body interface{}, language string) []dto.FieldError {
+	trans, _ := v.translator.GetTranslator(language)
+
+	return validateBody(v.validate, trans, body)
+}
+
+func newValidate(config Config) *validator.Validate {
+	validate := validator.New()
+	validate.SetTagName(bindingTag)
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	_ = validate.RegisterValidation("itemtype", func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		return value == domain.ItemTypeOwn || value == domain.ItemTypeSeller
+	})
+
+	_ = validate.RegisterValidation("leaderlevel", func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		return value == domain.LeaderLevelBasic || value == domain.LeaderLevelGold ||
+			value == domain.LeaderLevelPlatinum
+	})
+
+	_ = validate.RegisterValidation("photourl", func(fl validator.FieldLevel) bool {
+		photoURL, err := url.ParseRequestURI(fl.Field().String())
+		if err != nil {
+			return false
+		}
+
+		return containsString(config.PhotoURLSchemes, strings.ToLower(photoURL.Scheme)) && photoURL.Host != ""
+	})
+
+	// photohost leaves the URLs it can not parse to photourl.
+	_ = validate.RegisterValidation("photohost", func(fl validator.FieldLevel) bool {
+		photoURL, err := url.ParseRequestURI(fl.Field().String())
+		if err != nil || len(config.PhotoURLHosts) == 0 {
+			return true
+		}
+
+		return domain.PhotoHostAllowed(config.PhotoURLHosts, strings.ToLower(photoURL.Hostname()))
+	})
+
+	return validate
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// customRules are the validations registered by newValidate, translated from the i18n catalogues.
+var customRules = []string{"itemtype", "leaderlevel", "photourl", "photohost"}
+
+// newTranslator registers the validation messages of every supported language. The messages
+// of the photo URL rules also list the allowed values.
+func newTranslator(validate *validator.Validate, config Config) (*ut.UniversalTranslator, error) {
+	allowed := map[string]string{
+		"photourl":  strings.Join(config.PhotoURLSchemes, ", "),
+		"photohost": strings.Join(config.PhotoURLHosts, ", "),
+	}
+
+	translate := func(trans ut.Translator, fieldError validator.FieldError) string {
+		message, err := trans.T(fieldError.Tag(), fieldError.Field(), allowed[fieldError.Tag()])
+		if err != nil {
+			return fieldError.Error()
+		}
+
+		return message
+	}
+
+	uni := ut.New(en.New(), en.New(), es.New(), pt.New())
+
+	registerDefaults := map[string]func(*validator.Validate, ut.Translator) error{
+		i18n.English:    entranslations.RegisterDefaultTranslations,
+		i18n.Spanish:    estranslations.RegisterDefaultTranslations,
+		i18n.Portuguese: pttranslations.RegisterDefaultTranslations,
+	}
+
+	for _, language := range i18n.Languages() {
+		trans, _ := uni.GetTranslator(language)
+		if err := registerDefaults[language](validate, trans); err != nil {
+			return nil, fmt.Errorf("error registering %s validation messages: %w", language, err)
+		}
+
+		for _, rule := range customRules {
+			text := i18n.Text(language, "validation."+rule)
+			err := validate.RegisterTranslation(rule, trans, func(trans ut.Translator) error {
+				return trans.Add(rule, text, true)
+			}, translate)
+			if err != nil {
+				return nil, fmt.Errorf("error registering %s validation messages: %w", language, err)
+			}
+		}
+	}
+
+	return uni, nil
+}
+
+func validateBody(validate *validator.Validate, trans ut.Translator, body interface{}) []dto.FieldError {
+	err := validate.Struct(body)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []dto.FieldError{{Field: "", Rule: "invalid", Message: err.Error()}}
+	}
+
+	fieldErrors := make([]dto.FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fieldErrors = append(fieldErrors, dto.FieldError{
+			Field:   fieldPath(fieldError),
+			Rule:    fieldError.Tag(),
+			Message: fieldError.Translate(trans),
+		})
+	}
+
+	return fieldErrors
+}
+
+// fieldPath returns the JSON path of the field without the name of the body struct, e.g. photos[0].
+func fieldPath(fieldError validator.FieldError) string {
+	namespace := fieldError.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return namespace
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"os"
+	"strconv"
+)
+
+var (
+	tracingExporter    = "TRACING_EXPORTER"
+	tracingFile        = "TRACING_FILE"
+	tracingSampleRatio = "TRACING_SAMPLE_RATIO"
+	tracingServiceName = "TRACING_SERVICE_NAME"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+
+	defaultFile        = "data/traces.json"
+	defaultServiceName = "test-crud-api"
+)
+
+type Config struct {
+	// Exporter is where the spans are sent: otlp sends them to the collector configured with
+	// the standard OTEL_EXPORTER_OTLP_* variables, stdout and file write them as JSON for local
+	// use, and none keeps only the propagation of the incoming trace context.
+	Exporter string
+	// File is where the file exporter appends the spans.
+	File string
+	// SampleRatio is the share of the traces started here that are recorded. The traces started
+	// by the caller follow the sampling decision of the caller.
+	SampleRatio float64
+	ServiceName string
+}
+
+func LoadConfig() Config {
+	config := Config{
+		Exporter:    os.Getenv(tracingExporter),
+		File:        os.Getenv(tracingFile),
+		SampleRatio: 1,
+		ServiceName: os.Getenv(tracingServiceName),
+	}
+
+	switch config.Exporter {
+	case ExporterOTLP, ExporterStdout, ExporterFile:
+	default:
+		config.Exporter = ExporterNone
+	}
+
+	if config.File == "" {
+		config.File = defaultFile
+	}
+
+	if ratio, err := strconv.ParseFloat(os.Getenv(tracingSampleRatio), 64); err == nil && ratio >= 0 && ratio <= 1 {
+		config.SampleRatio = ratio
+	}
+
+	if config.ServiceName == "" {
+		config.ServiceName = defaultServiceName
+	}
+
+	return config
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mercadolibre/fury_go-core/pkg/web"
+	"github.com/osalomon89/test-crud-api/internal/infrastructure/server/handler"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type itemHandler struct {
+	itemHandler handler.ItemHandler
+}
+
+// NewItemHandler records a span for every request served by an ItemHandler, a child of the
+// span of the route, with the ID of the item in the path when there is one.
+func NewItemHandler(next handler.ItemHandler) (handler.ItemHandler, error) {
+	if next == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+
+	return &itemHandler{itemHandler: next}, nil
+}
+
+// serve calls next with the request carrying the span, so the spans of the service and the
+// repository are its children.
+func (h *itemHandler) serve(method string, next web.Handler, res http.ResponseWriter, req *http.Request) error {
+	var attributes []attribute.KeyValue
+	if id, err := strconv.ParseUint(web.Params(req)["id"], 10, 64); err == nil {
+		attributes = append(attributes, ItemIDKey.Int64(int64(id)))
+	}
+
+	ctx, span := start(req.Context(), "ItemHandler."+method, trace.SpanKindInternal, attributes...)
+
+	err := next(res, req.WithContext(ctx))
+	end(span, err)
+
+	return err
+}
+
+func (h *itemHandler) CreateItem(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("CreateItem", h.itemHandler.CreateItem, res, req)
+}
+
+func (h *itemHandler) CreateItems(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("CreateItems", h.itemHandler.CreateItems, res, req)
+}
+
+func (h *itemHandler) ExportItems(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("ExportItems", h.itemHandler.ExportItems, res, req)
+}
+
+func (h *itemHandler) GetItemByID(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("GetItemByID", h.itemHandler.GetItemByID, res, req)
+}
+
+func (h *itemHandler) UpdateItem(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("UpdateItem", h.itemHandler.UpdateItem, res, req)
+}
+
+func (h *itemHandler) PatchItem(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("PatchItem", h.itemHandler.PatchItem, res, req)
+}
+
+func (h *itemHandler) DeleteItem(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("DeleteItem", h.itemHandler.DeleteItem, res, req)
+}
+
+func (h *itemHandler) GetItemRevisions(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("GetItemRevisions", h.itemHandler.GetItemRevisions, res, req)
+}
+
+func (h *itemHandler) DiffItemRevisions(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("DiffItemRevisions", h.itemHandler.DiffItemRevisions, res, req)
+}
+
+func (h *itemHandler) RevertItem(res http.ResponseWriter, req *http.Request) error {
+	return h.serve("RevertItem", h.itemHandler.RevertItem, res, req)
+}
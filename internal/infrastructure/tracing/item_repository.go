@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type itemRepository struct {
+	repository ports.ItemRepository
+	backend    string
+}
+
+// NewItemRepository records a span for every operation of an ItemRepository of the given
+// backend, e.g. mysql or kvs, which is the db.system of the spans.
+func NewItemRepository(repository ports.ItemRepository, backend string) (ports.ItemRepository, error) {
+	if repository == nil {
+		return nil, fmt.Errorf("repository cannot be nil")
+	}
+
+	return &itemRepository{
+		repository: repository,
+		backend:    backend,
+	}, nil
+}
+
+func (repo *itemRepository) start(ctx context.Context, operation string,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	attributes = append(attributes, attribute.String("db.system", repo.backend),
+		attribute.String("db.operation", operation))
+
+	return start(ctx, "ItemRepository."+operation, trace.SpanKindClient, attributes...)
+}
+
+func (repo *itemRepository) SaveItem(ctx context.Context, item *domain.Item) error {
+	ctx, span := repo.start(ctx, "SaveItem", itemAttributes(item)...)
+
+	err := repo.repository.SaveItem(ctx, item)
+	span.SetAttributes(itemAttributes(item)...)
+	end(span, err)
+
+	return err
+}
+
+func (repo *itemRepository) SaveItems(ctx context.Context, items []*domain.Item) error {
+	ctx, span := repo.start(ctx, "SaveItems", attribute.Int("items.count", len(items)))
+
+	err := repo.repository.SaveItems(ctx, items)
+	end(span, err)
+
+	return err
+}
+
+func (repo *itemRepository) GetItemByID(ctx context.Context, id uint) (*domain.Item, error) {
+	ctx, span := repo.start(ctx, "GetItemByID", idAttribute(id))
+
+	item, err := repo.repository.GetItemByID(ctx, id)
+	span.SetAttributes(itemAttributes(item)...)
+	end(span, err)
+
+	return item, err
+}
+
+func (repo *itemRepository) GetItemsByIDs(ctx context.Context, ids []uint) ([]*domain.Item, error) {
+	ctx, span := repo.start(ctx, "GetItemsByIDs", attribute.Int("items.count", len(ids)))
+
+	items, err := repo.repository.GetItemsByIDs(ctx, ids)
+	end(span, err)
+
+	return items, err
+}
+
+func (repo *itemRepository) GetItemByCode(ctx context.Context, code string) (*domain.Item, error) {
+	ctx, span := repo.start(ctx, "GetItemByCode", ItemCodeKey.String(code))
+
+	item, err := repo.repository.GetItemByCode(ctx, code)
+	span.SetAttributes(itemAttributes(item)...)
+	end(span, err)
+
+	return item, err
+}
+
+// StreamItems spans the whole stream, the time spent in fn included.
+func (repo *itemRepository) StreamItems(ctx context.Context, filter domain.ItemFilter,
+	fn func(*domain.Item) error) error {
+	ctx, span := repo.start(ctx, "StreamItems")
+
+	err := repo.repository.StreamItems(ctx, filter, fn)
+	end(span, err)
+
+	return err
+}
+
+func (repo *itemRepository) UpdateItem(ctx context.Context, item *domain.Item) error {
+	ctx, span := repo.start(ctx, "UpdateItem", itemAttributes(item)...)
+
+	err := repo.repository.UpdateItem(ctx, item)
+	end(span, err)
+
+	return err
+}
+
+func (repo *itemRepository) DeleteItem(ctx context.Context, id uint, version uint) error {
+	ctx, span := repo.start(ctx, "DeleteItem", idAttribute(id))
+
+	err := repo.repository.DeleteItem(ctx, id, version)
+	end(span, err)
+
+	return err
+}
+
+func (repo *itemRepository) CountItemsByStatus(ctx context.Context) (map[string]int, error) {
+	ctx, span := repo.start(ctx, "CountItemsByStatus")
+
+	counts, err := repo.repository.CountItemsByStatus(ctx)
+	end(span, err)
+
+	return counts, err
+}
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"github.com/osalomon89/test-crud-api/internal/core/ports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type itemService struct {
+	itemService ports.ItemService
+}
+
+// NewItemService records a span for every call to an ItemService, with the ID and the code of
+// the item when they are known.
+func NewItemService(svc ports.ItemService) (ports.ItemService, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+
+	return &itemService{itemService: svc}, nil
+}
+
+func startService(ctx context.Context, method string,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return start(ctx, "ItemService."+method, trace.SpanKindInternal, attributes...)
+}
+
+func (svc *itemService) CreateItem(ctx context.Context, item domain.Item) (*domain.Item, error) {
+	ctx, span := startService(ctx, "CreateItem", itemAttributes(&item)...)
+
+	created, err := svc.itemService.CreateItem(ctx, item)
+	span.SetAttributes(itemAttributes(created)...)
+	end(span, err)
+
+	return created, err
+}
+
+func (svc *itemService) CreateItems(ctx context.Context, items []domain.Item,
+	mode string) ([]domain.BulkItemResult, error) {
+	ctx, span := startService(ctx, "CreateItems", attribute.Int("items.count", len(items)),
+		attribute.String("items.mode", mode))
+
+	results, err := svc.itemService.CreateItems(ctx, items, mode)
+	end(span, err)
+
+	return results, err
+}
+
+func (svc *itemService) GetItemByID(ctx context.Context, itemID uint) (*domain.Item, error) {
+	ctx, span := startService(ctx, "GetItemByID", idAttribute(itemID))
+
+	item, err := svc.itemService.GetItemByID(ctx, itemID)
+	span.SetAttributes(itemAttributes(item)...)
+	end(span, err)
+
+	return item, err
+}
+
+func (svc *itemService) ExportItems(ctx context.Context, filter domain.ItemFilter,
+	fn func(*domain.Item) error) error {
+	ctx, span := startService(ctx, "ExportItems")
+
+	err := svc.itemService.ExportItems(ctx, filter, fn)
+	end(span, err)
+
+	return err
+}
+
+func (svc *itemService) UpdateItem(ctx context.Context, item domain.Item) (*domain.Item, error) {
+	ctx, span := startService(ctx, "UpdateItem", itemAttributes(&item)...)
+
+	updated, err := svc.itemService.UpdateItem(ctx, item)
+	end(span, err)
+
+	return updated, err
+}
+
+func (svc *itemService) PatchItem(ctx context.Context, itemID uint, version uint,
+	patch domain.ItemPatch) (*domain.Item, error) {
+	ctx, span := startService(ctx, "PatchItem", idAttribute(itemID))
+
+	patched, err := svc.itemService.PatchItem(ctx, itemID, version, patch)
+	span.SetAttributes(itemAttributes(patched)...)
+	end(span, err)
+
+	return patched, err
+}
+
+func (svc *itemService) DeleteItem(ctx context.Context, itemID uint, version uint) error {
+	ctx, span := startService(ctx, "DeleteItem", idAttribute(itemID))
+
+	err := svc.itemService.DeleteItem(ctx, itemID, version)
+	end(span, err)
+
+	return err
+}
+
+func (svc *itemService) GetItemAsOf(ctx context.Context, itemID uint, asOf time.Time) (*domain.Item, error) {
+	ctx, span := startService(ctx, "GetItemAsOf", idAttribute(itemID))
+
+	item, err := svc.itemService.GetItemAsOf(ctx, itemID, asOf)
+	span.SetAttributes(itemAttributes(item)...)
+	end(span, err)
+
+	return item, err
+}
+
+func (svc *itemService) GetItemRevisions(ctx context.Context, itemID uint) ([]domain.ItemRevision, error) {
+	ctx, span := startService(ctx, "GetItemRevisions", idAttribute(itemID))
+
+	revisions, err := svc.itemService.GetItemRevisions(ctx, itemID)
+	end(span, err)
+
+	return revisions, err
+}
+
+func (svc *itemService) DiffItemRevisions(ctx context.Context, itemID uint, fromVersion,
+	toVersion uint) ([]domain.FieldChange, error) {
+	ctx, span := startService(ctx, "DiffItemRevisions", idAttribute(itemID))
+
+	changes, err := svc.itemService.DiffItemRevisions(ctx, itemID, fromVersion, toVersion)
+	end(span, err)
+
+	return changes, err
+}
+
+func (svc *itemService) RevertItem(ctx context.Context, itemID uint, revisionVersion uint,
+	version uint) (*domain.Item, error) {
+	ctx, span := startService(ctx, "RevertItem", idAttribute(itemID))
+
+	reverted, err := svc.itemService.RevertItem(ctx, itemID, revisionVersion, version)
+	span.SetAttributes(itemAttributes(reverted)...)
+	end(span, err)
+
+	return reverted, err
+}
@@ -0,0 +1,93 @@
+// Package tracing records the spans of the requests with OpenTelemetry and sends them to the
+// configured exporter.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Setup makes the W3C trace context and baggage the propagation format of the process and,
+// unless the exporter is none, installs a tracer provider sending the spans to the exporter.
+// The returned func flushes the pending spans and stops the exporter.
+func Setup(ctx context.Context, config Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{},
+		propagation.Baggage{}))
+
+	if config.Exporter == ExporterNone {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	exporter, file, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file == nil {
+			return err
+		}
+
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+
+		return err
+	}, nil
+}
+
+// newExporter also returns the file of the file exporter, to be closed once it is shut down.
+func newExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, *os.File, error) {
+	switch config.Exporter {
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating otlp exporter: %w", err)
+		}
+
+		return exporter, nil, nil
+	case ExporterFile:
+		if err := os.MkdirAll(filepath.Dir(config.File), 0o755); err != nil {
+			return nil, nil, fmt.Errorf("error creating traces directory: %w", err)
+		}
+
+		file, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening traces file: %w", err)
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("error creating file exporter: %w", err)
+		}
+
+		return exporter, file, nil
+	default:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating stdout exporter: %w", err)
+		}
+
+		return exporter, nil, nil
+	}
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/osalomon89/test-crud-api/internal/core/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/osalomon89/test-crud-api"
+
+// Attribute keys of the spans of the items.
+const (
+	ItemIDKey   = attribute.Key("item.id")
+	ItemCodeKey = attribute.Key("item.code")
+)
+
+// start starts a span, a child of the span of ctx if any, with the tracer provider installed
+// by Setup, or the no-op one.
+func start(ctx context.Context, name string, kind trace.SpanKind,
+	attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(kind),
+		trace.WithAttributes(attributes...))
+}
+
+// end records err on the span and ends it. The not found, conflict and validation errors of
+// the domain are answers rather than failures, so they do not set the error status.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+
+		var (
+			notFound  domain.ResourceNotFoundError
+			conflict  domain.ConflictError
+			itemError domain.ItemError
+		)
+
+		if !errors.As(err, &notFound) && !errors.As(err, &conflict) && !errors.As(err, &itemError) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	span.End()
+}
+
+func idAttribute(id uint) attribute.KeyValue {
+	return ItemIDKey.Int64(int64(id))
+}
+
+// itemAttributes returns the ID and the code of the item, leaving out the ones not set yet.
+func itemAttributes(item *domain.Item) []attribute.KeyValue {
+	var attributes []attribute.KeyValue
+
+	if item == nil {
+		return attributes
+	}
+
+	if item.ID != 0 {
+		attributes = append(attributes, idAttribute(item.ID))
+	}
+
+	if item.Code != "" {
+		attributes = append(attributes, ItemCodeKey.String(item.Code))
+	}
+
+	return attributes
+}
@@ -2,22 +2,78 @@ package marketcontext
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"github.com/osalomon89/test-crud-api/pkg/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const RequestIDKey = "X-Request-Id"
 
 type loggerKey struct{}
 
+type callerKey struct{}
+
+// Caller identifies who sent the request being served. ID is empty for an anonymous request, and
+// is only set once the caller has been authenticated, see WithCaller.
+type Caller struct {
+	ID    string
+	IP    string
+	Admin bool
+}
+
+// New returns the context of a request, with the caller authenticated for it or an anonymous one
+// known by its IP.
 func New(request *http.Request) context.Context {
+	ctx := request.Context()
+	if _, ok := ctx.Value(callerKey{}).(Caller); !ok {
+		ctx = context.WithValue(ctx, callerKey{}, Caller{IP: ClientIP(request)})
+	}
+
 	requestID := request.Header.Get(RequestIDKey)
+
+	// A traced request without a request ID is identified by its trace, so every logger of the
+	// request gets the same ID.
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.HasTraceID() {
+		traceID := spanContext.TraceID().String()
+		if len(requestID) == 0 {
+			requestID = traceID
+		}
+
+		return context.WithValue(ctx, loggerKey{}, log.NewTracedLogger(requestID, traceID))
+	}
+
 	if len(requestID) == 0 {
-		return context.WithValue(request.Context(), loggerKey{}, log.DefaultLogger())
+		return context.WithValue(ctx, loggerKey{}, log.DefaultLogger())
 	}
 
-	return context.WithValue(request.Context(), loggerKey{}, log.NewLogger(requestID))
+	return context.WithValue(ctx, loggerKey{}, log.NewLogger(requestID))
+}
+
+// WithLogger returns a context whose logger is the given one, e.g. one made With the fields of
+// the work being done, so the lines logged down the call chain carry them.
+func WithLogger(ctx context.Context, logger log.ILogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// WithCaller returns a context with an authenticated caller, for a request once its credentials
+// have been checked or for work that is not started by a request, e.g. a command.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// Detach returns a context with the logger, the caller and the span of ctx that is not cancelled
+// with it, for the work that goes on after the response has been sent.
+func Detach(ctx context.Context) context.Context {
+	detached := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+	detached = context.WithValue(detached, callerKey{}, GetCaller(ctx))
+
+	return context.WithValue(detached, loggerKey{}, Logger(ctx))
 }
 
 func Logger(ctx context.Context) log.ILogger {
@@ -28,3 +84,100 @@ func Logger(ctx context.Context) log.ILogger {
 
 	return logger
 }
+
+func GetCaller(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+
+	return caller
+}
+
+type trustedProxiesHolder struct {
+	networks []*net.IPNet
+}
+
+var trustedProxies atomic.Value
+
+func init() {
+	trustedProxies.Store(trustedProxiesHolder{})
+}
+
+// SetTrustedProxies sets the addresses, as IPs or CIDRs, of the proxies whose X-Forwarded-For
+// entries are believed. Until it is set no proxy is trusted and X-Forwarded-For is ignored.
+func SetTrustedProxies(proxies []string) error {
+	networks := make([]*net.IPNet, 0, len(proxies))
+
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	trustedProxies.Store(trustedProxiesHolder{networks: networks})
+
+	return nil
+}
+
+func trustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies.Load().(trustedProxiesHolder).networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the remote address of the request, unless it is a trusted proxy. Then the
+// X-Forwarded-For entries are walked from the right, the ones appended by the proxies we run,
+// and the first one that is not a trusted proxy is the client. The entries on its left are
+// written by the client, so they are never believed.
+func ClientIP(request *http.Request) string {
+	address, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		address = request.RemoteAddr
+	}
+
+	if !trustedProxy(address) {
+		return address
+	}
+
+	forwarded := strings.Split(strings.Join(request.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+
+		if !trustedProxy(hop) {
+			return hop
+		}
+
+		address = hop
+	}
+
+	return address
+}
@@ -0,0 +1,73 @@
+package marketcontext
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{
+			name:       "no trusted proxies ignores X-Forwarded-For",
+			remoteAddr: "203.0.113.7:4321",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "untrusted remote address ignores X-Forwarded-For",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "203.0.113.7:4321",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "rightmost untrusted hop",
+			trusted:    []string{"10.0.0.0/8", "192.0.2.10"},
+			remoteAddr: "10.1.2.3:4321",
+			forwarded:  []string{"198.51.100.1, 203.0.113.9", "192.0.2.10"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "only trusted hops",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.1.2.3:4321",
+			forwarded:  []string{"10.0.0.2, 10.0.0.1"},
+			want:       "10.0.0.2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := SetTrustedProxies(test.trusted); err != nil {
+				t.Fatalf("SetTrustedProxies() error = %v", err)
+			}
+
+			t.Cleanup(func() {
+				_ = SetTrustedProxies(nil)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = test.remoteAddr
+
+			for _, forwarded := range test.forwarded {
+				req.Header.Add("X-Forwarded-For", forwarded)
+			}
+
+			if got := ClientIP(req); got != test.want {
+				t.Errorf("ClientIP() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxiesRejectsInvalidAddresses(t *testing.T) {
+	if err := SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Error("SetTrustedProxies() did not fail")
+	}
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"github.com/mercadolibre/go-meli-toolkit/goutils/logger"
+)
+
+// Entry is a log line as handed to a backend. Fields has the tags of the line, then the fields
+// of the logger, then Request_ID, Class, Sequence_ID and Trace_ID when the request is traced.
+type Entry struct {
+	Level   Level
+	Message string
+	Err     error
+	Fields  []Field
+}
+
+// Backend writes the log lines. The lines below the level of the loggers never reach it.
+type Backend interface {
+	Write(entry Entry)
+}
+
+type backendHolder struct {
+	backend Backend
+}
+
+var backend atomic.Value
+
+func init() {
+	backend.Store(backendHolder{backend: ToolkitBackend()})
+}
+
+// SetBackend makes every logger write to the given backend from now on.
+func SetBackend(newBackend Backend) {
+	backend.Store(backendHolder{backend: newBackend})
+}
+
+func currentBackend() Backend {
+	return backend.Load().(backendHolder).backend
+}
+
+type toolkitBackend struct{}
+
+// ToolkitBackend writes to the go-meli-toolkit logger, with the fields formatted as key:value
+// tags. It is the backend until another one is set.
+func ToolkitBackend() Backend {
+	return toolkitBackend{}
+}
+
+func (toolkitBackend) Write(entry Entry) {
+	tags := make([]string, len(entry.Fields))
+	for i, field := range entry.Fields {
+		tags[i] = field.String()
+	}
+
+	switch entry.Level {
+	case LevelDebug:
+		logger.Debug(entry.Message, tags...)
+	case LevelInfo:
+		logger.Info(entry.Message, tags...)
+	case LevelWarn:
+		logger.Warn(entry.Message, tags...)
+	default:
+		logger.Error(entry.Message, entry.Err, tags...)
+	}
+}
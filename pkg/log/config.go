@@ -0,0 +1,121 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	logBackend       = "LOG_BACKEND"
+	logLevel         = "LOG_LEVEL"
+	logRedactKeys    = "LOG_REDACT_KEYS"
+	logRedactPattern = "LOG_REDACT_PATTERN"
+)
+
+const (
+	BackendToolkit  = "toolkit"
+	BackendSlogJSON = "slog-json"
+	BackendSlogText = "slog-text"
+	BackendZap      = "zap"
+)
+
+type Config struct {
+	// Backend is where the lines are written: toolkit to the go-meli-toolkit logger, slog-json
+	// and slog-text to stdout through log/slog, and zap to stdout as JSON.
+	Backend string
+	// Level is the lowest level written, which can be changed while running with SetLevel.
+	Level Level
+	// RedactKeys are redacted on top of DefaultRedactKeys, read comma separated.
+	RedactKeys []string
+	// RedactPattern is redacted on top of DefaultRedactPatterns, e.g. the ids of the customers.
+	// Several patterns are joined with |.
+	RedactPattern string
+}
+
+// LoadConfig reads an unknown backend as toolkit, and an unknown level as debug.
+func LoadConfig() Config {
+	config := Config{
+		Backend:       os.Getenv(logBackend),
+		Level:         LevelDebug,
+		RedactPattern: os.Getenv(logRedactPattern),
+	}
+
+	for _, key := range strings.Split(os.Getenv(logRedactKeys), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			config.RedactKeys = append(config.RedactKeys, key)
+		}
+	}
+
+	switch config.Backend {
+	case BackendSlogJSON, BackendSlogText, BackendZap:
+	default:
+		config.Backend = BackendToolkit
+	}
+
+	if parsed, err := ParseLevel(os.Getenv(logLevel)); err == nil {
+		config.Level = parsed
+	}
+
+	return config
+}
+
+// Configure sets the backend, the level and the redactor of every logger.
+func Configure(config Config) error {
+	newBackend, err := NewBackend(config.Backend)
+	if err != nil {
+		return err
+	}
+
+	keys := append(append([]string(nil), DefaultRedactKeys...), config.RedactKeys...)
+
+	patterns := append([]string(nil), DefaultRedactPatterns...)
+	if config.RedactPattern != "" {
+		patterns = append(patterns, config.RedactPattern)
+	}
+
+	redactor, err := NewRedactor(keys, patterns)
+	if err != nil {
+		return err
+	}
+
+	SetBackend(newBackend)
+	SetLevel(config.Level)
+	SetRedactor(redactor)
+
+	return nil
+}
+
+// NewBackend creates a backend by name, writing to stdout.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case BackendToolkit:
+		return ToolkitBackend(), nil
+	case BackendSlogJSON:
+		return SlogBackend(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})), nil
+	case BackendSlogText:
+		return SlogBackend(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})), nil
+	case BackendZap:
+		zapConfig := zap.NewProductionConfig()
+		zapConfig.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+		zapConfig.Sampling = nil
+		// The caller and the stack would be the ones of this package, the Class field tells
+		// where the line comes from.
+		zapConfig.DisableCaller = true
+		zapConfig.DisableStacktrace = true
+		zapConfig.OutputPaths = []string{"stdout"}
+
+		zapLogger, err := zapConfig.Build()
+		if err != nil {
+			return nil, fmt.Errorf("error creating zap logger: %w", err)
+		}
+
+		return ZapBackend(zapLogger), nil
+	default:
+		return nil, fmt.Errorf("unknown log backend %q", name)
+	}
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Field is a typed attribute of a log line, kept as such by the backends that support it, e.g.
+// an int is written as a JSON number by the slog and zap backends.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Uint(key string, value uint) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Any keeps a value of any other type, written by the backends as they see fit.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// String formats the field the way the tags of the toolkit logger are written, key:value.
+func (field Field) String() string {
+	return fmt.Sprintf("%s:%v", field.Key, field.Value)
+}
+
+// tagFields turns the tags of a line into fields, ordered by key so lines read the same way.
+func tagFields(tags map[string]string) []Field {
+	fields := make([]Field, 0, len(tags))
+	for key, value := range tags {
+		fields = append(fields, String(key, value))
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+
+	return fields
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (level Level) String() string {
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("level(%d)", int32(level))
+}
+
+// ParseLevel reads a level by name, ignoring case.
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+
+	return LevelDebug, fmt.Errorf("unknown log level %q, must be one of debug, info, warn, error", name)
+}
+
+// level is the lowest level written by every logger. It starts at debug so the backend, e.g.
+// the toolkit logger, applies its own until it is set.
+var level int32 = int32(LevelDebug)
+
+// SetLevel changes the lowest level written by every logger, which takes effect at once.
+func SetLevel(newLevel Level) {
+	atomic.StoreInt32(&level, int32(newLevel))
+}
+
+func GetLevel() Level {
+	return Level(atomic.LoadInt32(&level))
+}
+
+// Enabled reports whether the lines of the given level are written.
+func Enabled(lineLevel Level) bool {
+	return lineLevel >= GetLevel()
+}
@@ -6,7 +6,6 @@ import (
 	"sync"
 
 	"github.com/gofrs/uuid"
-	"github.com/mercadolibre/go-meli-toolkit/goutils/logger"
 )
 
 const (
@@ -18,43 +17,96 @@ type ILogger interface {
 	Warn(source interface{}, tags map[string]string, message string, args ...interface{})
 	Error(source interface{}, tags map[string]string, err error, message string, args ...interface{})
 	Debug(source interface{}, tags map[string]string, message string, args ...interface{})
+	// With returns a logger of the same request that adds the fields to every line, after the
+	// tags of the line.
+	With(fields ...Field) ILogger
 	GetRequestID() string
 	GetMessage(message string, args ...interface{}) string
 	GetTags(source interface{}, tags map[string]string) []string
 }
 
+// sequence numbers the lines of a request, shared by the loggers returned by With.
+type sequence struct {
+	mutex sync.Mutex
+	id    int
+}
+
+func (seq *sequence) next() int {
+	seq.mutex.Lock()
+	defer seq.mutex.Unlock()
+
+	seq.id++
+
+	return seq.id
+}
+
 type log struct {
-	mutex      sync.Mutex
-	requestID  string
-	sequenceID int
+	requestID string
+	traceID   string
+	fields    []Field
+	sequence  *sequence
 }
 
 func DefaultLogger() ILogger {
-	iLogger := &log{requestID: newRequestID()}
+	iLogger := &log{requestID: newRequestID(), sequence: &sequence{}}
 	return iLogger
 }
 
 func NewLogger(requestID string) ILogger {
-	iLogger := &log{requestID: requestID}
+	iLogger := &log{requestID: requestID, sequence: &sequence{}}
+
+	return iLogger
+}
+
+// NewTracedLogger tags every line with the trace of the request as well, so the spans of the
+// request can be found from its logs.
+func NewTracedLogger(requestID, traceID string) ILogger {
+	iLogger := &log{requestID: requestID, traceID: traceID, sequence: &sequence{}}
 
 	return iLogger
 }
 
 func (theLogger *log) Info(source interface{}, tags map[string]string, message string, args ...interface{}) {
-	logger.Info(theLogger.GetMessage(message, args...), theLogger.GetTags(source, tags)...)
+	theLogger.write(LevelInfo, source, tags, nil, message, args...)
 }
 
 func (theLogger *log) Warn(source interface{}, tags map[string]string, message string, args ...interface{}) {
-	logger.Warn(theLogger.GetMessage(message, args...), theLogger.GetTags(source, tags)...)
+	theLogger.write(LevelWarn, source, tags, nil, message, args...)
 }
 
 func (theLogger *log) Error(source interface{}, tags map[string]string, err error,
 	message string, args ...interface{}) {
-	logger.Error(theLogger.GetMessage(message, args...), err, theLogger.GetTags(source, tags)...)
+	theLogger.write(LevelError, source, tags, err, message, args...)
 }
 
 func (theLogger *log) Debug(source interface{}, tags map[string]string, message string, args ...interface{}) {
-	logger.Debug(theLogger.GetMessage(message, args...), theLogger.GetTags(source, tags)...)
+	theLogger.write(LevelDebug, source, tags, nil, message, args...)
+}
+
+// write leaves out the lines below the level before formatting them, so the debug lines cost
+// little when they are not written. The message, the error and the fields are redacted before
+// they reach the backend.
+func (theLogger *log) write(level Level, source interface{}, tags map[string]string, err error,
+	message string, args ...interface{}) {
+	if !Enabled(level) {
+		return
+	}
+
+	currentBackend().Write(Entry{
+		Level:   level,
+		Message: Redact(theLogger.GetMessage(message, args...)),
+		Err:     redactError(err),
+		Fields:  theLogger.getFields(source, tags),
+	})
+}
+
+func (theLogger *log) With(fields ...Field) ILogger {
+	return &log{
+		requestID: theLogger.requestID,
+		traceID:   theLogger.traceID,
+		fields:    append(append([]Field(nil), theLogger.fields...), fields...),
+		sequence:  theLogger.sequence,
+	}
 }
 
 func (theLogger *log) GetRequestID() string {
@@ -90,28 +142,34 @@ func getClass(source interface{}) string {
 }
 
 func (theLogger *log) GetTags(source interface{}, tags map[string]string) []string {
-	var res []string
-
-	i := 0
-
-	if len(tags) == 0 {
-		res = make([]string, minTags)
-	} else {
-		res = make([]string, len(tags)+minTags)
-		for key, value := range tags {
-			res[i] = fmt.Sprintf("%s:%v", key, value)
-			i++
-		}
+	fields := theLogger.getFields(source, tags)
+
+	res := make([]string, len(fields))
+	for i, field := range fields {
+		res[i] = field.String()
 	}
 
-	theLogger.mutex.Lock()
-	theLogger.sequenceID++
+	return res
+}
 
-	res[i] = fmt.Sprintf("Request_ID:%v", theLogger.requestID)
-	res[i+1] = fmt.Sprintf("Class:%v", getClass(source))
-	res[i+2] = fmt.Sprintf("Sequence_ID:%v", theLogger.sequenceID)
+// getFields numbers a new line of the request and returns its fields, redacted.
+func (theLogger *log) getFields(source interface{}, tags map[string]string) []Field {
+	fields := make([]Field, 0, len(tags)+len(theLogger.fields)+minTags+1)
+	fields = append(fields, tagFields(tags)...)
+	fields = append(fields, theLogger.fields...)
+	fields = append(fields,
+		String("Request_ID", theLogger.requestID),
+		String("Class", getClass(source)),
+		Int("Sequence_ID", theLogger.sequence.next()),
+	)
+
+	if theLogger.traceID != "" {
+		fields = append(fields, String("Trace_ID", theLogger.traceID))
+	}
 
-	theLogger.mutex.Unlock()
+	for i, field := range fields {
+		fields[i] = redactField(field)
+	}
 
-	return res
+	return fields
 }
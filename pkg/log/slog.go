@@ -0,0 +1,36 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+var slogLevels = map[Level]slog.Level{
+	LevelDebug: slog.LevelDebug,
+	LevelInfo:  slog.LevelInfo,
+	LevelWarn:  slog.LevelWarn,
+	LevelError: slog.LevelError,
+}
+
+type slogBackend struct {
+	logger *slog.Logger
+}
+
+// SlogBackend writes to a log/slog handler, e.g. slog.NewJSONHandler. The handler should let
+// every level through, since the lines are filtered by the level of the loggers.
+func SlogBackend(handler slog.Handler) Backend {
+	return slogBackend{logger: slog.New(handler)}
+}
+
+func (b slogBackend) Write(entry Entry) {
+	attrs := make([]slog.Attr, 0, len(entry.Fields)+1)
+	for _, field := range entry.Fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+
+	if entry.Err != nil {
+		attrs = append(attrs, slog.String("error", entry.Err.Error()))
+	}
+
+	b.logger.LogAttrs(context.Background(), slogLevels[entry.Level], entry.Message, attrs...)
+}
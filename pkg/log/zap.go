@@ -0,0 +1,41 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var zapLevels = map[Level]zapcore.Level{
+	LevelDebug: zapcore.DebugLevel,
+	LevelInfo:  zapcore.InfoLevel,
+	LevelWarn:  zapcore.WarnLevel,
+	LevelError: zapcore.ErrorLevel,
+}
+
+type zapBackend struct {
+	logger *zap.Logger
+}
+
+// ZapBackend writes to a zap logger. The logger should let every level through, since the
+// lines are filtered by the level of the loggers.
+func ZapBackend(logger *zap.Logger) Backend {
+	return zapBackend{logger: logger}
+}
+
+func (b zapBackend) Write(entry Entry) {
+	checked := b.logger.Check(zapLevels[entry.Level], entry.Message)
+	if checked == nil {
+		return
+	}
+
+	fields := make([]zap.Field, 0, len(entry.Fields)+1)
+	for _, field := range entry.Fields {
+		fields = append(fields, zap.Any(field.Key, field.Value))
+	}
+
+	if entry.Err != nil {
+		fields = append(fields, zap.Error(entry.Err))
+	}
+
+	checked.Write(fields...)
+}